@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/carboniferio/carbonifer/internal/data"
+	"github.com/carboniferio/carbonifer/internal/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// dataCmd represents the data command
+var dataCmd = &cobra.Command{
+	Use:   "data",
+	Short: "Manage the data files used for estimations",
+}
+
+// dataValidateCmd represents the data validate command
+var dataValidateCmd = &cobra.Command{
+	Use: "validate [data path]",
+	Long: `Validate the data files of a custom data path (default: data.path of config).
+
+Each file overriding an embedded data file is checked against its expected columns and types.
+Regions and instance types of the embedded files missing in the overriding ones are listed.
+Exits with code 1 if a file is invalid.
+Example usages:
+	carbonifer data validate
+	carbonifer data validate /path/to/data`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'data validate'")
+
+		dataPath := viper.GetString("data.path")
+		if len(args) != 0 {
+			dataPath = args[0]
+		}
+		if dataPath == "" {
+			log.Fatal(errors.New("No data path to validate, set data.path in config or give it as argument"))
+		}
+
+		report, err := data.ValidateDataPath(dataPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		reportText := ""
+		if viper.Get("out.format") == "json" {
+			reportText = output.GenerateDataValidationJSON(*report)
+		} else {
+			reportText = output.GenerateDataValidationText(*report)
+		}
+
+		writeReport(cmd, reportText)
+
+		if !report.IsValid() {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(dataCmd)
+	dataCmd.AddCommand(dataValidateCmd)
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/output"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use: "diff <base> <head>",
+	Long: `Compare CO2 emissions of two versions of your infrastructure.
+
+The 'diff' command takes two arguments, base and head, each one can be:
+		- a terraform project directory
+		- a terraform plan file (raw or json)
+		- a JSON report generated by 'carbonifer plan --format json'
+Example usages:
+	carbonifer diff /path/to/main/project /path/to/branch/project
+	carbonifer diff main-report.json branch-plan.json
+	carbonifer diff --format markdown main.tfplan branch.tfplan`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'diff'")
+
+		base, err := loadEstimationReport(resolveInput(args[0:1]))
+		if err != nil {
+			log.Fatal(errors.Wrapf(err, "Failed to estimate base '%v'", args[0]))
+		}
+		head, err := loadEstimationReport(resolveInput(args[1:2]))
+		if err != nil {
+			log.Fatal(errors.Wrapf(err, "Failed to estimate head '%v'", args[1]))
+		}
+		if base.Info.UnitCarbonEmissionsTime != head.Info.UnitCarbonEmissionsTime {
+			log.Fatalf("Cannot compare reports with different units: '%v' and '%v'", base.Info.UnitCarbonEmissionsTime, head.Info.UnitCarbonEmissionsTime)
+		}
+
+		delta := estimate.DiffReports(base, head)
+		if viper.GetString("diff.sort") != "address" {
+			estimate.SortDeltas(&delta.Resources)
+		}
+		diff := estimation.EstimationDiffReport{
+			Info:  head.Info,
+			Delta: *delta,
+		}
+		if percentChange, ok := delta.PercentChange(); ok {
+			diff.PercentChange = &percentChange
+		}
+
+		// Generate report
+		reportText := ""
+		switch viper.GetString("out.format") {
+		case "json":
+			reportText = output.GenerateDiffJSON(diff)
+		case "markdown":
+			reportText = output.GenerateDiffMarkdown(diff)
+		default:
+			reportText = output.GenerateDiffText(diff)
+		}
+
+		writeReport(cmd, reportText)
+	},
+}
+
+// loadEstimationReport reads an estimation report from a JSON report, or estimates it from terraform plan or project
+func loadEstimationReport(input string) (estimation.EstimationReport, error) {
+	if strings.HasSuffix(input, ".json") {
+		report, isReport, err := readEstimationReportJSON(input)
+		if err != nil {
+			return estimation.EstimationReport{}, err
+		}
+		if isReport {
+			return *report, nil
+		}
+	}
+
+	// Each input has its own working directory
+	terraform.ResetTerraformExec()
+	tfPlan, err := terraform.CarboniferPlan(input)
+	if err != nil {
+		return estimation.EstimationReport{}, err
+	}
+	if tfPlan == nil {
+		return estimation.EstimationReport{}, errors.Errorf("No terraform plan generated for %v", input)
+	}
+	forecastCarbonIntensity, forecastRegion := readForecastCarbonIntensity()
+	return estimate.EstimatePlan(viper.GetViper(), nil, tfPlan, forecastCarbonIntensity, forecastRegion)
+}
+
+// readEstimationReportJSON reads a JSON file and returns the report only if it is a carbonifer report (not a plan)
+func readEstimationReportJSON(input string) (*estimation.EstimationReport, bool, error) {
+	content, err := os.ReadFile(filepath.Clean(input))
+	if err != nil {
+		return nil, false, err
+	}
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return nil, false, err
+	}
+	_, hasInfo := keys["Info"]
+	_, hasResources := keys["Resources"]
+	if !hasInfo || !hasResources {
+		return nil, false, nil
+	}
+
+	var report estimation.EstimationReport
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, true, errors.Wrapf(err, "Cannot read estimation report %v", input)
+	}
+	return &report, true, nil
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().String("sort", "delta", "Sort resources by 'delta' (absolute emissions delta, biggest first) or 'address'")
+	viper.BindPFlag("diff.sort", diffCmd.Flags().Lookup("sort"))
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/output"
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// explainCmd represents the explain command
+var explainCmd = &cobra.Command{
+	Use: "explain <address> [directory]",
+	Long: `Explain how CO2 emissions of a resource are estimated.
+
+The 'explain' command takes the address of a resource and optionally the same input as 'plan':
+		- default: current directory
+		- directory: a terraform project directory
+		- file: a terraform plan file (raw or json)
+It prints each term of the estimation (CPU, memory, storage, GPU, PUE, replication, grid carbon intensity)
+with the coefficients used and where each spec of the resource was read from.
+Example usages:
+	carbonifer explain google_compute_instance.first
+	carbonifer explain 'module.app.google_compute_instance.web[0]' /path/to/terraform/plan.json
+	carbonifer explain --format json google_compute_instance.first /path/to/terraform/project`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'explain'")
+
+		address := args[0]
+		input := resolveInput(args[1:])
+
+		// Generate or Read Terraform plan
+		tfPlan, err := terraform.CarboniferPlan(input)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Read the resource from terraform plan
+		resource, specsSources, err := plan.ExplainResource(viper.GetViper(), nil, tfPlan, address)
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "Failed to get resource from terraform plan"))
+		}
+
+		forecastCarbonIntensity, forecastRegion := readForecastCarbonIntensity()
+
+		explanation, err := estimate.ExplainResource(viper.GetViper(), resource, forecastCarbonIntensity, forecastRegion)
+		if err != nil {
+			log.Fatal(errors.Wrapf(err, "Failed to estimate resource %v", address))
+		}
+		explanation.SpecsSources = specsSources
+
+		// Generate report
+		reportText := ""
+		if viper.Get("out.format") == "json" {
+			reportText = output.GenerateExplanationJSON(*explanation)
+		} else {
+			reportText = output.GenerateExplanationText(*explanation)
+		}
+
+		writeReport(cmd, reportText)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(explainCmd)
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	internalResources "github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/pkg/providers"
+	"github.com/carboniferio/carbonifer/pkg/resources"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// instanceCmd represents the instance command
+var instanceCmd = &cobra.Command{
+	Use: "instance <instance type>",
+	Long: `Estimate CO2 from a single instance type, without any Terraform.
+
+The report is the same as the one of 'plan'.
+GPUs of GCP machine types (ex: a2-highgpu-1g) are counted, --gpu adds GPUs to them.
+GPUs of AWS instance types and local disks are not counted unless set with --gpu, --disk-ssd-gb or --disk-hdd-gb.
+Example usages:
+	carbonifer instance n2-standard-2 --region europe-west9
+	carbonifer instance m5.xlarge --provider aws --region eu-west-3 --count 3
+	carbonifer instance n1-standard-4 --region us-east1 --gpu nvidia-tesla-t4 --disk-ssd-gb 100`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'instance'")
+
+		resource, err := getInstanceResource(cmd, args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		forecastCarbonIntensity, forecastRegion := readForecastCarbonIntensity()
+
+		// Estimate CO2 emissions like plan does
+		resourceList := map[string]internalResources.Resource{
+			resource.GetAddress(): resource.ToComputeResource(),
+		}
+		estimations := estimate.EstimateResources(viper.GetViper(), resourceList, forecastCarbonIntensity, forecastRegion)
+
+		// Generate report
+		reportText := generateReport(estimations, forecastCarbonIntensity != nil, nil)
+
+		writeReport(cmd, reportText)
+	},
+}
+
+// getInstanceResource builds the resource described by the instance command flags
+func getInstanceResource(cmd *cobra.Command, instanceType string) (*resources.GenericResource, error) {
+	providerName, _ := cmd.Flags().GetString("provider")
+	provider, err := providers.ParseProvider(providerName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Unknown provider %v", providerName)
+	}
+	region, _ := cmd.Flags().GetString("region")
+	if region == "" {
+		return nil, errors.New("Region is required (--region)")
+	}
+	count, _ := cmd.Flags().GetInt64("count")
+	if count <= 0 {
+		return nil, errors.Errorf("Count must be positive: %v", count)
+	}
+	gpus, _ := cmd.Flags().GetStringSlice("gpu")
+	ssdGb, _ := cmd.Flags().GetFloat64("disk-ssd-gb")
+	hddGb, _ := cmd.Flags().GetFloat64("disk-hdd-gb")
+	if ssdGb < 0 || hddGb < 0 {
+		return nil, errors.New("Disk sizes cannot be negative")
+	}
+
+	resource, err := resources.GetResource(instanceType, region, provider)
+	if err != nil {
+		return nil, err
+	}
+	resource.Address = instanceType
+	resource.Count = count
+	// GPU types of the machine type are shared by the catalog, append to a copy
+	resource.GPUTypes = append(append([]string{}, resource.GPUTypes...), gpus...)
+	resource.Storage = resources.Storage{
+		SsdStorage: decimal.NewFromFloat(ssdGb),
+		HddStorage: decimal.NewFromFloat(hddGb),
+	}
+	return &resource, nil
+}
+
+func init() {
+	RootCmd.AddCommand(instanceCmd)
+
+	instanceCmd.Flags().String("provider", "gcp", "Cloud provider of the instance type: 'gcp' or 'aws'")
+	instanceCmd.Flags().String("region", "", "Region of the instance (ex: europe-west9, eu-west-3)")
+	instanceCmd.Flags().Int64("count", 1, "Number of instances")
+	instanceCmd.Flags().StringSlice("gpu", nil, "GPU types attached to each instance (ex: nvidia-tesla-t4), can be repeated")
+	instanceCmd.Flags().Float64("disk-ssd-gb", 0, "SSD storage of each instance in GB")
+	instanceCmd.Flags().Float64("disk-hdd-gb", 0, "HDD storage of each instance in GB")
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/polkeli/yaml/v3"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// mappingsCmd represents the mappings command
+var mappingsCmd = &cobra.Command{
+	Use:   "mappings",
+	Short: "Check the mappings of terraform resources",
+}
+
+// mappingsLintCmd represents the mappings lint command
+var mappingsLintCmd = &cobra.Command{
+	Use: "lint [mappings directory]",
+	Long: `Check the mappings of terraform resources (default: mappings embedded in carbonifer).
+
+The directory has a folder per provider (ex: internal/plan/mappings). Every mapping is parsed strictly,
+every jq path is compiled, and ${placeholders}, references and regexes are checked.
+Exits with code 1 if an issue is found.
+Example usages:
+	carbonifer mappings lint
+	carbonifer mappings lint internal/plan/mappings`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'mappings lint'")
+
+		var issues []plan.MappingIssue
+		var err error
+		if len(args) == 0 {
+			issues, err = plan.LintMappings()
+		} else {
+			issues, err = plan.LintMappingsFS(os.DirFS(args[0]))
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		for _, issue := range issues {
+			cmd.PrintErrln(issue.String())
+		}
+		if len(issues) > 0 {
+			cmd.PrintErrf("%v issues found\n", len(issues))
+			os.Exit(1)
+		}
+		cmd.Println("No issue found")
+	},
+}
+
+// mappingsTestCmd represents the mappings test command
+var mappingsTestCmd = &cobra.Command{
+	Use: "test <mapping> <plan.json> <expected.yaml>",
+	Long: `Read the resources of a mapping from a terraform plan and compare them with the expected ones.
+
+mapping is either a resource type of the embedded mappings (ex: google_compute_instance),
+or a mapping file, tested with the embedded general config of its provider.
+expected.yaml maps resource addresses to the expected values of their identification and specs, other values are ignored:
+
+	google_compute_instance.foo[0]:
+	  Identification:
+	    Region: europe-west9
+	  Specs:
+	    VCPUs: 2
+	    MemoryMb: 8192
+
+Exits with code 1 if a value differs.
+Example usages:
+	carbonifer mappings test google_compute_instance plan.json expected.yaml
+	carbonifer mappings test my_mapping.yaml plan.json expected.yaml`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'mappings test'")
+
+		resourceTypes := []string{args[0]}
+		if info, err := os.Stat(args[0]); err == nil && !info.IsDir() {
+			resourceTypes, err = plan.LoadMappingFile(args[0])
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		tfPlan, err := terraform.CarboniferPlan(resolveInput(args[1:2]))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		expectedContent, err := os.ReadFile(args[2])
+		if err != nil {
+			log.Fatal(errors.Wrap(err, "Cannot read expected resources"))
+		}
+		var expected map[string]interface{}
+		if err := yaml.Unmarshal(expectedContent, &expected); err != nil {
+			log.Fatal(errors.Wrap(err, "Cannot parse expected resources"))
+		}
+
+		differences, err := plan.CheckMappings(viper.GetViper(), tfPlan, resourceTypes, expected)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, difference := range differences {
+			cmd.PrintErrln(difference)
+		}
+		if len(differences) > 0 {
+			cmd.PrintErrf("%v differences found\n", len(differences))
+			os.Exit(1)
+		}
+		cmd.Printf("%v resources as expected\n", len(expected))
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(mappingsCmd)
+	mappingsCmd.AddCommand(mappingsLintCmd)
+	mappingsCmd.AddCommand(mappingsTestCmd)
+}
@@ -8,8 +8,10 @@ import (
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/carboniferio/carbonifer/internal/budget"
 	"github.com/carboniferio/carbonifer/internal/data" // <-- add this import
 	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
 	"github.com/carboniferio/carbonifer/internal/output"
 	"github.com/carboniferio/carbonifer/internal/plan"
 	"github.com/carboniferio/carbonifer/internal/terraform"
@@ -35,24 +37,15 @@ Example usages:
 	carbonifer plan
 	carbonifer plan /path/to/terraform/project
 	carbonifer plan /path/to/terraform/plan.json
-	carbonifer plan /path/to/terraform/plan.tfplan`,
+	carbonifer plan /path/to/terraform/plan.tfplan
+	carbonifer plan --offline /path/to/terraform/project
+	carbonifer plan --delta /path/to/terraform/plan.json`,
 	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		testPlanCmdHasRun = true
 		log.Debug("Running command 'plan'")
 
-		workdir, err := os.Getwd()
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		input := workdir
-		if len(args) != 0 {
-			input = args[0]
-			if !filepath.IsAbs(input) {
-				input = filepath.Join(workdir, input)
-			}
-		}
+		input := resolveInput(args)
 
 		// Generate or Read Terraform plan
 		tfPlan, err := terraform.CarboniferPlan(input)
@@ -60,67 +53,208 @@ Example usages:
 			log.Fatal(err)
 		}
 
-		// Read resources from terraform plan
-		resources, err := plan.GetResources(tfPlan)
+		forecastCarbonIntensity, forecastRegion := readForecastCarbonIntensity()
+
+		// Read resources from terraform plan and estimate CO2 emissions with forecast params
+		estimations, err := estimate.EstimatePlan(viper.GetViper(), nil, tfPlan, forecastCarbonIntensity, forecastRegion)
 		if err != nil {
-			errW := errors.Wrap(err, "Failed to get resources from terraform plan")
-			log.Panic(errW)
+			log.Fatal(err)
 		}
+		estimations.Info.Terraform = getTerraformInfo(tfPlan)
+		estimations.Unresolved = getUnresolvedAttributes()
 
-		// New code for forecast file
-		forecastFile := viper.GetString("carbon_intensity_file")
-		var forecastCarbonIntensity *decimal.Decimal
-		var forecastRegion string
-
-		if forecastFile != "" {
-			value, region, err := data.ReadForecastCarbonIntensity(forecastFile)
+		// Estimate the difference made by the plan, from its resource changes
+		if viper.GetBool("delta") {
+			changes, err := plan.GetResourcesChanges(viper.GetViper(), nil, tfPlan)
 			if err != nil {
-				log.Warnf("Error loading forecast carbon intensity, falling back to default: %v", err)
-			} else {
-				d := decimal.NewFromFloat(value)
-				forecastCarbonIntensity = &d
-				forecastRegion = region
-				log.Infof("Using forecast carbon intensity from %s (region: %s): %.6f gCO2eq/Wh", forecastFile, forecastRegion, value)
+				errW := errors.Wrap(err, "Failed to get resource changes from terraform plan")
+				log.Panic(errW)
 			}
-		} else {
-			log.Info("No forecast carbon intensity file provided — using static carbon intensities only")
+			estimations.Delta = estimate.EstimateDelta(viper.GetViper(), changes.Before, changes.After, changes.Actions, forecastCarbonIntensity, forecastRegion)
 		}
 
-		// Estimate CO2 emissions with forecast params
-		estimations := estimate.EstimateResources(resources, forecastCarbonIntensity, forecastRegion)
-
-		// Generate report
 		// Generate report
-		reportText := ""
-		if viper.Get("out.format") == "json" {
-			reportText = output.GenerateReportJSON(estimations)
-		} else {
-			reportText = output.GenerateReportText(estimations, forecastCarbonIntensity != nil)
-		}
+		reportText := generateReport(estimations, forecastCarbonIntensity != nil, tfPlan)
 
 		// Print out report
-		outFile := viper.Get("out.file").(string)
-		if outFile == "" {
-			log.Debug("output : stdout")
-			cmd.SetOut(os.Stdout)
-			cmd.Println(reportText)
+		writeReport(cmd, reportText)
+
+		// Fail if a carbon budget is breached
+		checkBudgets(cmd, estimations)
+	},
+}
+
+// budgetExceededExitCode is the exit code when a carbon budget is breached (errors exit with 1)
+const budgetExceededExitCode = 2
+
+// checkBudgets prints the violated budgets and exits with budgetExceededExitCode if any
+func checkBudgets(cmd *cobra.Command, estimations estimation.EstimationReport) {
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if budgets.IsEmpty() {
+		return
+	}
+	violations, err := budgets.Check(estimations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(violations) == 0 {
+		log.Info("All carbon budgets are respected")
+		return
+	}
+	cmd.PrintErrf("Carbon budget exceeded (%v violations):\n", len(violations))
+	for _, violation := range violations {
+		cmd.PrintErrf("  - %v\n", violation)
+	}
+	os.Exit(budgetExceededExitCode)
+}
+
+// getTerraformInfo returns the workspace, var files, variables and targets the plan was generated with,
+// nil if the plan was read from a file
+func getTerraformInfo(tfPlan *map[string]interface{}) *estimation.TerraformInfo {
+	settings := terraform.GetUsedPlanSettings()
+	if settings == nil {
+		return nil
+	}
+	return &estimation.TerraformInfo{
+		Workspace: settings.Workspace,
+		VarFiles:  settings.VarFiles,
+		Variables: terraform.GetPlanVariables(tfPlan),
+		Targets:   settings.Targets,
+	}
+}
+
+// getUnresolvedAttributes returns the attributes whose value is unknown in the plan, if generated offline
+func getUnresolvedAttributes() []estimation.UnresolvedAttribute {
+	unresolved := []estimation.UnresolvedAttribute{}
+	for _, attribute := range terraform.GetUsedPlanUnresolved() {
+		unresolved = append(unresolved, estimation.UnresolvedAttribute(attribute))
+	}
+	return unresolved
+}
+
+// resolveInput returns the absolute path of the input argument, or the current directory if not set
+func resolveInput(args []string) string {
+	workdir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	input := workdir
+	if len(args) != 0 {
+		input = args[0]
+		if !filepath.IsAbs(input) {
+			input = filepath.Join(workdir, input)
+		}
+	}
+	return input
+}
+
+// generateReport generates the report of estimations in the configured output format.
+// tfPlan locates resources in the terraform files for SARIF reports, it is nil if the estimations are not from a plan.
+func generateReport(estimations estimation.EstimationReport, isForecast bool, tfPlan *map[string]interface{}) string {
+	switch viper.GetString("out.format") {
+	case "json":
+		return output.GenerateReportJSON(estimations)
+	case "markdown":
+		return output.GenerateReportMarkdown(estimations, isForecast)
+	case "csv":
+		return output.GenerateReportCSV(estimations)
+	case "html":
+		return output.GenerateReportHTML(estimations, isForecast)
+	case "sarif":
+		return generateReportSARIF(estimations, tfPlan)
+	case "junit":
+		return generateReportJUnit(estimations)
+	default:
+		return output.GenerateReportText(estimations, isForecast)
+	}
+}
+
+// generateReportSARIF generates a SARIF report, with resources located in the terraform files of the workdir
+func generateReportSARIF(estimations estimation.EstimationReport, tfPlan *map[string]interface{}) string {
+	settings, err := output.GetSARIFSettings()
+	if err != nil {
+		log.Fatal(err)
+	}
+	locations := terraform.SourceLocations{}
+	if tfPlan != nil {
+		locations, err = terraform.GetSourceLocations(tfPlan, viper.GetString("workdir"))
+		if err != nil {
+			log.Warnf("Cannot locate resources in terraform files: %v", err)
+			locations = terraform.SourceLocations{}
+		}
+	}
+	sourceRoot, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return output.GenerateReportSARIF(estimations, *settings, locations, sourceRoot)
+}
+
+// generateReportJUnit generates a JUnit report, with a test case per resource and per configured budget
+func generateReportJUnit(estimations estimation.EstimationReport) string {
+	budgets, err := budget.GetBudgets()
+	if err != nil {
+		log.Fatal(err)
+	}
+	violations, err := budgets.Check(estimations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return output.GenerateReportJUnit(estimations, *budgets, violations, viper.GetBool("junit.strict"))
+}
+
+// writeReport prints out the report to the output file if configured, to stdout otherwise
+func writeReport(cmd *cobra.Command, reportText string) {
+	// Print out report
+	outFile := viper.Get("out.file").(string)
+	if outFile == "" {
+		log.Debug("output : stdout")
+		cmd.SetOut(os.Stdout)
+		cmd.Println(reportText)
+	} else {
+		log.Debug("output :", outFile)
+		f, err := os.Create(outFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		outWriter := bufio.NewWriter(f)
+		_, err = outWriter.WriteString(reportText)
+		if err != nil {
+			log.Fatal(err)
+		}
+		err = outWriter.Flush()
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// readForecastCarbonIntensity reads the forecast carbon intensity file if configured
+func readForecastCarbonIntensity() (*decimal.Decimal, string) {
+	// New code for forecast file
+	forecastFile := viper.GetString("carbon_intensity_file")
+	var forecastCarbonIntensity *decimal.Decimal
+	var forecastRegion string
+
+	if forecastFile != "" {
+		value, region, err := data.ReadForecastCarbonIntensity(forecastFile)
+		if err != nil {
+			log.Warnf("Error loading forecast carbon intensity, falling back to default: %v", err)
 		} else {
-			log.Debug("output :", outFile)
-			f, err := os.Create(outFile)
-			if err != nil {
-				log.Fatal(err)
-			}
-			outWriter := bufio.NewWriter(f)
-			_, err = outWriter.WriteString(reportText)
-			if err != nil {
-				log.Fatal(err)
-			}
-			err = outWriter.Flush()
-			if err != nil {
-				log.Fatal(err)
-			}
+			d := decimal.NewFromFloat(value)
+			forecastCarbonIntensity = &d
+			forecastRegion = region
+			log.Infof("Using forecast carbon intensity from %s (region: %s): %.6f gCO2eq/Wh", forecastFile, forecastRegion, value)
 		}
-	},
+	} else {
+		log.Info("No forecast carbon intensity file provided — using static carbon intensities only")
+	}
+
+	return forecastCarbonIntensity, forecastRegion
 }
 
 func init() {
@@ -129,4 +263,13 @@ func init() {
 	// Add CLI flag for forecast carbon intensity file
 	planCmd.Flags().String("carbon-intensity-file", "", "Path to JSON file with forecast carbon intensity data")
 	viper.BindPFlag("carbon_intensity_file", planCmd.Flags().Lookup("carbon-intensity-file"))
+
+	planCmd.Flags().Float64("budget-total", 0, "Maximum total carbon emissions (in unit.carbon/unit.time), exit with code 2 if exceeded")
+	viper.BindPFlag("budget.total", planCmd.Flags().Lookup("budget-total"))
+
+	planCmd.Flags().Bool("strict", false, "Fail the JUnit test cases of unsupported resources")
+	viper.BindPFlag("junit.strict", planCmd.Flags().Lookup("strict"))
+
+	planCmd.Flags().Bool("delta", false, "Also estimate the difference of emissions made by the plan (from its resource changes)")
+	viper.BindPFlag("delta", planCmd.Flags().Lookup("delta"))
 }
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/output"
+	"github.com/carboniferio/carbonifer/internal/recommend"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// recommendCmd represents the recommend command
+var recommendCmd = &cobra.Command{
+	Use:   "recommend",
+	Short: "Recommend changes to lower CO2 emissions",
+}
+
+// recommendRegionsCmd represents the recommend regions command
+var recommendRegionsCmd = &cobra.Command{
+	Use: "regions [directory]",
+	Long: `List the lowest carbon regions of the same provider for each resource, and the savings of moving there.
+
+The 'recommend regions' command takes the same input as 'plan'.
+Regions can be constrained to the same country, the same continent or a list of allowed regions.
+Example usages:
+	carbonifer recommend regions
+	carbonifer recommend regions --count 5 /path/to/terraform/plan.json
+	carbonifer recommend regions --same-continent /path/to/terraform/project
+	carbonifer recommend regions --allowed-regions europe-west1,europe-west9`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'recommend regions'")
+
+		input := resolveInput(args)
+
+		options, err := recommend.GetRegionsOptions()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Generate or Read Terraform plan
+		tfPlan, err := terraform.CarboniferPlan(input)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Read resources from terraform plan
+		// Static carbon intensities only, emissions are compared between regions
+		estimations, err := estimate.EstimatePlan(viper.GetViper(), nil, tfPlan, nil, "")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		regionsReport, err := recommend.RecommendRegions(viper.GetViper(), estimations, options)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Generate report
+		reportText := ""
+		if viper.Get("out.format") == "json" {
+			reportText = output.GenerateRegionsJSON(*regionsReport)
+		} else {
+			reportText = output.GenerateRegionsText(*regionsReport)
+		}
+
+		writeReport(cmd, reportText)
+	},
+}
+
+// recommendInstancesCmd represents the recommend instances command
+var recommendInstancesCmd = &cobra.Command{
+	Use: "instances [directory]",
+	Long: `List machine types with equal or greater vCPUs and memory and a lower estimated power for each resource,
+and the savings of switching to them.
+
+The 'recommend instances' command takes the same input as 'plan'. Alternatives are estimated like 'plan' does.
+Example usages:
+	carbonifer recommend instances
+	carbonifer recommend instances --count 5 /path/to/terraform/plan.json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'recommend instances'")
+
+		input := resolveInput(args)
+
+		options, err := recommend.GetInstancesOptions()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Generate or Read Terraform plan
+		tfPlan, err := terraform.CarboniferPlan(input)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// Read resources from terraform plan
+		// Static carbon intensities only, emissions are compared between machine types
+		estimations, err := estimate.EstimatePlan(viper.GetViper(), nil, tfPlan, nil, "")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		instancesReport := recommend.RecommendInstances(viper.GetViper(), estimations, options)
+
+		// Generate report
+		reportText := ""
+		if viper.Get("out.format") == "json" {
+			reportText = output.GenerateInstancesJSON(*instancesReport)
+		} else {
+			reportText = output.GenerateInstancesText(*instancesReport)
+		}
+
+		writeReport(cmd, reportText)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(recommendCmd)
+	recommendCmd.AddCommand(recommendRegionsCmd)
+	recommendCmd.AddCommand(recommendInstancesCmd)
+
+	recommendRegionsCmd.Flags().Int("count", 3, "Number of regions to recommend per resource")
+	viper.BindPFlag("recommend.regions.count", recommendRegionsCmd.Flags().Lookup("count"))
+	recommendRegionsCmd.Flags().Bool("same-country", false, "Only recommend regions in the same country")
+	viper.BindPFlag("recommend.regions.same_country", recommendRegionsCmd.Flags().Lookup("same-country"))
+	recommendRegionsCmd.Flags().Bool("same-continent", false, "Only recommend regions in the same continent")
+	viper.BindPFlag("recommend.regions.same_continent", recommendRegionsCmd.Flags().Lookup("same-continent"))
+	recommendRegionsCmd.Flags().StringSlice("allowed-regions", nil, "Only recommend these regions")
+	viper.BindPFlag("recommend.regions.allowed", recommendRegionsCmd.Flags().Lookup("allowed-regions"))
+
+	recommendInstancesCmd.Flags().Int("count", 3, "Number of machine types to recommend per resource")
+	viper.BindPFlag("recommend.instances.count", recommendInstancesCmd.Flags().Lookup("count"))
+}
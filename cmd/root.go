@@ -38,10 +38,19 @@ func init() {
 	// will be global for your application.
 
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.carbonifer.yaml)")
-	RootCmd.PersistentFlags().StringP("format", "f", "", "format of output ('text' or 'json').\ndefault: 'text'")
+	RootCmd.PersistentFlags().StringP("format", "f", "", "format of output ('text', 'json', 'markdown', 'csv', 'html', 'sarif' or 'junit').\ndefault: 'text'")
 	RootCmd.PersistentFlags().StringP("output", "o", "", "output file")
 	RootCmd.PersistentFlags().BoolP("debug", "d", false, "print debug logs")
 	RootCmd.PersistentFlags().BoolP("info", "i", false, "print info logs")
+	RootCmd.PersistentFlags().Bool("offline", false, "parse terraform files without running terraform (no providers nor credentials needed)")
+	RootCmd.PersistentFlags().StringArray("var-file", nil, "terraform variables file passed to terraform plan (can be repeated)")
+	RootCmd.PersistentFlags().StringArray("var", nil, "terraform variable 'name=value' passed to terraform plan (can be repeated)")
+	RootCmd.PersistentFlags().StringArray("target", nil, "resource address passed as target to terraform plan (can be repeated)")
+	RootCmd.PersistentFlags().String("workspace", "", "terraform workspace to plan, it must exist")
+	RootCmd.PersistentFlags().Bool("refresh", true, "refresh the state during terraform plan")
+	RootCmd.PersistentFlags().Bool("lock", true, "lock the state during terraform init and plan")
+	RootCmd.PersistentFlags().Bool("skip-credentials", false, "plan with a temporary override file configuring providers without credentials and a local backend")
+	RootCmd.PersistentFlags().Int("parallelism", 0, "maximum number of resources read and estimated at once (default is the number of CPUs)")
 
 }
 
@@ -69,4 +78,28 @@ func initConfig() {
 		log.Panic(err)
 	}
 
+	if err := viper.BindPFlag("offline", RootCmd.PersistentFlags().Lookup("offline")); err != nil {
+		log.Panic(err)
+	}
+
+	if err := viper.BindPFlag("parallelism", RootCmd.PersistentFlags().Lookup("parallelism")); err != nil {
+		log.Panic(err)
+	}
+
+	terraformFlags := map[string]string{
+		"terraform.var_files": "var-file",
+		"terraform.vars":      "var",
+		"terraform.targets":   "target",
+		"terraform.workspace": "workspace",
+		"terraform.refresh":   "refresh",
+		"terraform.lock":      "lock",
+
+		"terraform.skip_credentials": "skip-credentials",
+	}
+	for key, flag := range terraformFlags {
+		if err := viper.BindPFlag(key, RootCmd.PersistentFlags().Lookup(flag)); err != nil {
+			log.Panic(err)
+		}
+	}
+
 }
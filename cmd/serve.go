@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/carboniferio/carbonifer/internal/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use: "serve",
+	Long: `Start an HTTP API estimating CO2 emissions.
+
+Endpoints:
+	GET  /health    : health check
+	GET  /version   : version of carbonifer
+	POST /plan      : estimate a terraform plan JSON (body), returns the JSON report
+	GET  /instance  : estimate an instance type (?instance_type=...&zone=...&provider=...)
+	POST /instance  : same, with a JSON body {"instance_type", "zone", "provider", "config"}
+Config (units, average usage) can be overridden per request with query parameters (ex: ?unit.time=m)
+or in a "config" object of the body (for plans: {"plan": <plan JSON>, "config": {...}}).
+Example usages:
+	carbonifer serve
+	carbonifer serve --listen 127.0.0.1:9000`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		log.Debug("Running command 'serve'")
+
+		address := viper.GetString("serve.listen")
+		log.Warnf("Listening on %v", address)
+		err := http.ListenAndServe(address, server.NewServer(RootCmd.Version).Handler())
+		if err != nil {
+			log.Fatal(err)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().String("listen", ":8080", "Address to listen on")
+	viper.BindPFlag("serve.listen", serveCmd.Flags().Lookup("listen"))
+}
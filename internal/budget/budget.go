@@ -0,0 +1,149 @@
+package budget
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+)
+
+// Kinds of budget
+const (
+	KindTotal        = "total"
+	KindResourceType = "resource_type"
+	KindModule       = "module"
+	KindAddress      = "address"
+)
+
+// Budgets is the budget configuration, all values are in the report unit (unit.carbon / unit.time)
+type Budgets struct {
+	Total         *float64             `mapstructure:"total"`
+	ResourceTypes []ResourceTypeBudget `mapstructure:"resource_types"`
+	Modules       []ModuleBudget       `mapstructure:"modules"`
+	Addresses     []AddressBudget      `mapstructure:"addresses"`
+}
+
+// ResourceTypeBudget is the budget of all resources of a type
+type ResourceTypeBudget struct {
+	Type string  `mapstructure:"type"`
+	Max  float64 `mapstructure:"max"`
+}
+
+// ModuleBudget is the budget of all resources of a module (including its child modules)
+type ModuleBudget struct {
+	Module string  `mapstructure:"module"`
+	Max    float64 `mapstructure:"max"`
+}
+
+// AddressBudget is the budget of each resource whose address matches a glob pattern
+type AddressBudget struct {
+	Pattern string  `mapstructure:"pattern"`
+	Max     float64 `mapstructure:"max"`
+}
+
+// Violation is a budget breached by an estimation
+type Violation struct {
+	Kind   string
+	Target string // resource type, module, address or empty for total
+	Budget decimal.Decimal
+	Actual decimal.Decimal
+	Unit   string
+}
+
+func (v Violation) String() string {
+	target := v.Kind
+	if v.Target != "" {
+		target = fmt.Sprintf("%v '%v'", v.Kind, v.Target)
+	}
+	return fmt.Sprintf("%v: %v %v exceeds budget of %v %v", target, v.Actual.StringFixed(4), v.Unit, v.Budget.StringFixed(4), v.Unit)
+}
+
+// GetBudgets returns the budgets from the configuration (key "budget")
+func GetBudgets() (*Budgets, error) {
+	var budgets Budgets
+	if err := viper.UnmarshalKey("budget", &budgets); err != nil {
+		return nil, errors.Wrap(err, "Cannot read budget configuration")
+	}
+	// Nested keys do not see bound flags, read the total (flag or file) on its own
+	if viper.IsSet("budget.total") {
+		total := viper.GetFloat64("budget.total")
+		budgets.Total = &total
+	}
+	return &budgets, nil
+}
+
+// IsEmpty returns true if no budget is configured
+func (b *Budgets) IsEmpty() bool {
+	return b.Total == nil && len(b.ResourceTypes) == 0 && len(b.Modules) == 0 && len(b.Addresses) == 0
+}
+
+// Check returns the budgets breached by the estimation report
+func (b *Budgets) Check(report estimation.EstimationReport) ([]Violation, error) {
+	unit := report.Info.UnitCarbonEmissionsTime
+	violations := []Violation{}
+
+	if b.Total != nil {
+		violations = appendIfBreached(violations, KindTotal, "", *b.Total, report.Total.CarbonEmissions, unit)
+	}
+
+	for _, resourceTypeBudget := range b.ResourceTypes {
+		actual := decimal.Zero
+		for _, resource := range report.Resources {
+			if resource.Resource.GetIdentification().ResourceType == resourceTypeBudget.Type {
+				actual = actual.Add(resourceEmissions(resource))
+			}
+		}
+		violations = appendIfBreached(violations, KindResourceType, resourceTypeBudget.Type, resourceTypeBudget.Max, actual, unit)
+	}
+
+	for _, moduleBudget := range b.Modules {
+		actual := decimal.Zero
+		for _, resource := range report.Resources {
+			if IsInModule(resource.Resource.GetAddress(), moduleBudget.Module) {
+				actual = actual.Add(resourceEmissions(resource))
+			}
+		}
+		violations = appendIfBreached(violations, KindModule, moduleBudget.Module, moduleBudget.Max, actual, unit)
+	}
+
+	for _, addressBudget := range b.Addresses {
+		for _, resource := range report.Resources {
+			matched, err := path.Match(addressBudget.Pattern, resource.Resource.GetAddress())
+			if err != nil {
+				return nil, errors.Wrapf(err, "Invalid budget address pattern '%v'", addressBudget.Pattern)
+			}
+			if matched {
+				violations = appendIfBreached(violations, KindAddress, resource.Resource.GetAddress(), addressBudget.Max, resourceEmissions(resource), unit)
+			}
+		}
+	}
+	return violations, nil
+}
+
+// IsInModule returns true if the resource address is in the module or one of its child modules, including the
+// instances of modules with count or for_each (ex: module.db[0] or module.db["a"] are in module.db)
+func IsInModule(address string, module string) bool {
+	return strings.HasPrefix(address, module+".") || strings.HasPrefix(address, module+"[")
+}
+
+func resourceEmissions(resource estimation.EstimationResource) decimal.Decimal {
+	return resource.CarbonEmissions.Mul(resource.TotalCount)
+}
+
+func appendIfBreached(violations []Violation, kind string, target string, max float64, actual decimal.Decimal, unit string) []Violation {
+	budget := decimal.NewFromFloat(max)
+	if actual.GreaterThan(budget) {
+		violations = append(violations, Violation{
+			Kind:   kind,
+			Target: target,
+			Budget: budget,
+			Actual: actual,
+			Unit:   unit,
+		})
+	}
+	return violations
+}
@@ -0,0 +1,152 @@
+package budget
+
+import (
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	_ "github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func estimationOf(address string, resourceType string, emissions float64, count int64) estimation.EstimationResource {
+	return estimation.EstimationResource{
+		Resource: resources.ComputeResource{
+			Identification: &resources.ResourceIdentification{
+				Address:      address,
+				ResourceType: resourceType,
+			},
+		},
+		CarbonEmissions: decimal.NewFromFloat(emissions),
+		TotalCount:      decimal.NewFromInt(count),
+	}
+}
+
+var report = estimation.EstimationReport{
+	Info: estimation.EstimationInfo{
+		UnitCarbonEmissionsTime: "gCO2eq/h",
+	},
+	Resources: []estimation.EstimationResource{
+		estimationOf("google_compute_instance.web[0]", "google_compute_instance", 1.5, 1),
+		estimationOf("google_compute_instance.web[1]", "google_compute_instance", 0.5, 1),
+		estimationOf("module.db.google_sql_database_instance.main", "google_sql_database_instance", 2, 2),
+		estimationOf("module.db.module.replica.google_compute_disk.data", "google_compute_disk", 0.25, 1),
+	},
+	Total: estimation.EstimationTotal{
+		CarbonEmissions: decimal.NewFromFloat(6.25),
+	},
+}
+
+func TestBudgets_Check(t *testing.T) {
+	total := 6.0
+	budgets := Budgets{
+		Total: &total,
+		ResourceTypes: []ResourceTypeBudget{
+			{Type: "google_compute_instance", Max: 2.5},
+			{Type: "google_compute_disk", Max: 0.1},
+		},
+		Modules: []ModuleBudget{
+			{Module: "module.db", Max: 4},
+			{Module: "module.db.module.replica", Max: 1},
+		},
+		Addresses: []AddressBudget{
+			{Pattern: "google_compute_instance.web*", Max: 1},
+		},
+	}
+
+	violations, err := budgets.Check(report)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"total: 6.2500 gCO2eq/h exceeds budget of 6.0000 gCO2eq/h",
+		"resource_type 'google_compute_disk': 0.2500 gCO2eq/h exceeds budget of 0.1000 gCO2eq/h",
+		"module 'module.db': 4.2500 gCO2eq/h exceeds budget of 4.0000 gCO2eq/h",
+		"address 'google_compute_instance.web[0]': 1.5000 gCO2eq/h exceeds budget of 1.0000 gCO2eq/h",
+	}, violationStrings(violations))
+}
+
+func TestIsInModule(t *testing.T) {
+	assert.True(t, IsInModule("module.db.google_sql_database_instance.x", "module.db"))
+	assert.True(t, IsInModule(`module.db["a"].google_sql_database_instance.x`, "module.db"))
+	assert.True(t, IsInModule("module.db[0].module.replica.google_compute_disk.data", "module.db"))
+	assert.True(t, IsInModule("module.db[0].module.replica[1].google_compute_disk.data", "module.db[0].module.replica"))
+	assert.True(t, IsInModule(`module.db["a"].google_sql_database_instance.x`, `module.db["a"]`))
+	assert.False(t, IsInModule(`module.db["b"].google_sql_database_instance.x`, `module.db["a"]`))
+	assert.False(t, IsInModule("module.dbs.google_sql_database_instance.x", "module.db"))
+	assert.False(t, IsInModule("google_sql_database_instance.db", "module.db"))
+}
+
+func TestBudgets_CheckModuleInstances(t *testing.T) {
+	budgets := Budgets{Modules: []ModuleBudget{{Module: "module.db", Max: 2}}}
+	instancesReport := estimation.EstimationReport{
+		Info: estimation.EstimationInfo{UnitCarbonEmissionsTime: "gCO2eq/h"},
+		Resources: []estimation.EstimationResource{
+			estimationOf(`module.db["a"].google_sql_database_instance.x`, "google_sql_database_instance", 1, 1),
+			estimationOf("module.db[0].google_sql_database_instance.x", "google_sql_database_instance", 1.5, 1),
+		},
+	}
+
+	violations, err := budgets.Check(instancesReport)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"module 'module.db': 2.5000 gCO2eq/h exceeds budget of 2.0000 gCO2eq/h",
+	}, violationStrings(violations))
+}
+
+func TestGetBudgets(t *testing.T) {
+	viper.Set("budget", map[string]interface{}{
+		"total": 10,
+		"modules": []interface{}{
+			map[string]interface{}{"module": "module.db", "max": 4},
+		},
+	})
+	defer viper.Set("budget", nil)
+
+	budgets, err := GetBudgets()
+	assert.NoError(t, err)
+	assert.False(t, budgets.IsEmpty())
+	assert.Equal(t, 10.0, *budgets.Total)
+	assert.Equal(t, []ModuleBudget{{Module: "module.db", Max: 4}}, budgets.Modules)
+}
+
+func TestGetBudgets_TotalFlag(t *testing.T) {
+	bindTotalFlag := func(args ...string) {
+		flags := pflag.NewFlagSet("plan", pflag.ContinueOnError)
+		flags.Float64("budget-total", 0, "")
+		assert.NoError(t, flags.Parse(args))
+		assert.NoError(t, viper.BindPFlag("budget.total", flags.Lookup("budget-total")))
+	}
+	defer bindTotalFlag()
+
+	// Default of the flag is no budget
+	bindTotalFlag()
+	budgets, err := GetBudgets()
+	assert.NoError(t, err)
+	assert.True(t, budgets.IsEmpty())
+
+	// Config file
+	viper.Set("budget", map[string]interface{}{"total": 10})
+	defer viper.Set("budget", nil)
+	budgets, err = GetBudgets()
+	assert.NoError(t, err)
+	assert.Equal(t, 10.0, *budgets.Total)
+
+	// Flag overrides config file
+	viper.Set("budget", nil)
+	viper.SetDefault("budget", map[string]interface{}{"total": 10})
+	defer viper.SetDefault("budget", nil)
+	bindTotalFlag("--budget-total", "3.5")
+	budgets, err = GetBudgets()
+	assert.NoError(t, err)
+	assert.Equal(t, 3.5, *budgets.Total)
+}
+
+func violationStrings(violations []Violation) []string {
+	result := []string{}
+	for _, violation := range violations {
+		result = append(result, violation.String())
+	}
+	return result
+}
@@ -9,16 +9,13 @@ import (
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-
-	"github.com/spf13/viper"
 )
 
 //go:embed data/*
 var data embed.FS
 
-// ReadDataFile reads a file from the data directory
-func ReadDataFile(filename string) []byte {
-	dataPath := viper.GetString("data.path")
+// ReadDataFile reads a file from the data directory dataPath, or the embedded one if not in dataPath
+func ReadDataFile(dataPath string, filename string) ([]byte, error) {
 	if dataPath != "" {
 		// If the environment variable is set, read from the specified file
 		filePath := filepath.Join(dataPath, filename)
@@ -26,9 +23,9 @@ func ReadDataFile(filename string) []byte {
 			log.Debugf("  reading datafile '%v' from: %v", filename, filePath)
 			data, err := os.ReadFile(filePath)
 			if err != nil {
-				log.Fatal(err)
+				return nil, errors.Wrapf(err, "cannot read data file %v", filePath)
 			}
-			return data
+			return data, nil
 		}
 		return readEmbeddedFile(filename)
 
@@ -36,14 +33,19 @@ func ReadDataFile(filename string) []byte {
 	return readEmbeddedFile(filename)
 }
 
-func readEmbeddedFile(filename string) []byte {
+// IsDataFile returns true if the file is one of the embedded data files
+func IsDataFile(filename string) bool {
+	_, err := fs.Stat(data, "data/"+filename)
+	return err == nil
+}
+
+func readEmbeddedFile(filename string) ([]byte, error) {
 	log.Debugf("  reading datafile '%v' embedded", filename)
 	data, err := fs.ReadFile(data, "data/"+filename)
 	if err != nil {
-		errW := errors.Wrap(err, "cannot read embedded data file")
-		log.Fatal(errW)
+		return nil, errors.Wrap(err, "cannot read embedded data file")
 	}
-	return data
+	return data, nil
 }
 
 type ForecastFile struct {
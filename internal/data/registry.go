@@ -0,0 +1,75 @@
+package data
+
+import (
+	"encoding/json"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// datasetKey identifies a data file parsed into a type, for a data path
+type datasetKey struct {
+	dataPath string
+	filename string
+	dataType reflect.Type
+}
+
+type dataset struct {
+	once  sync.Once
+	value interface{}
+	err   error
+}
+
+// registry keeps the data files already parsed, so that each one is read once per data path
+var registry = struct {
+	sync.Mutex
+	datasets map[datasetKey]*dataset
+}{datasets: map[datasetKey]*dataset{}}
+
+// Load returns the content of a data file of dataPath (embedded files if empty) parsed by parse, reading and parsing
+// it only once per data path. It is safe for concurrent use, the value returned is shared and must not be modified.
+func Load[T any](dataPath string, filename string, parse func(content []byte) (T, error)) (T, error) {
+	key := datasetKey{
+		dataPath: dataPath,
+		filename: filename,
+		dataType: reflect.TypeOf((*T)(nil)).Elem(),
+	}
+	registry.Lock()
+	d, ok := registry.datasets[key]
+	if !ok {
+		d = &dataset{}
+		registry.datasets[key] = d
+	}
+	registry.Unlock()
+
+	d.once.Do(func() {
+		content, err := ReadDataFile(dataPath, filename)
+		if err != nil {
+			d.err = err
+			return
+		}
+		d.value, d.err = parse(content)
+	})
+	if d.err != nil {
+		var zero T
+		return zero, d.err
+	}
+	return d.value.(T), nil
+}
+
+// LoadJSON returns the content of a JSON data file, see Load
+func LoadJSON[T any](dataPath string, filename string) (T, error) {
+	return Load(dataPath, filename, func(content []byte) (T, error) {
+		var value T
+		err := json.Unmarshal(content, &value)
+		return value, errors.Wrapf(err, "cannot parse data file %v", filename)
+	})
+}
+
+// ResetRegistry forgets the data files already parsed
+func ResetRegistry() {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.datasets = map[datasetKey]*dataset{}
+}
@@ -0,0 +1,57 @@
+package data
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	ResetRegistry()
+
+	var parsed int32
+	parseSize := func(content []byte) (int, error) {
+		atomic.AddInt32(&parsed, 1)
+		return len(content), nil
+	}
+
+	// Parsed once, even when loaded concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			size, err := Load("", "gpu_watt.csv", parseSize)
+			assert.NoError(t, err)
+			content, _ := readEmbeddedFile("gpu_watt.csv")
+			assert.Equal(t, len(content), size)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), parsed)
+
+	// Parsed again for another data path
+	size, err := Load("../../test/data", "gpu_watt.csv", parseSize)
+	assert.NoError(t, err)
+	content, err := ReadDataFile("../../test/data", "gpu_watt.csv")
+	assert.NoError(t, err)
+	assert.Equal(t, len(content), size)
+	assert.Equal(t, int32(2), parsed)
+}
+
+func TestLoadJSON(t *testing.T) {
+	ResetRegistry()
+	instances, err := LoadJSON[map[string]interface{}]("", "aws_instances.json")
+	assert.NoError(t, err)
+	assert.Contains(t, instances, "t2.micro")
+
+	// Same file parsed into another type is another dataset
+	names, err := LoadJSON[map[string]struct{ InstanceType string }]("", "aws_instances.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "t2.micro", names["t2.micro"].InstanceType)
+
+	_, err = LoadJSON[[]string]("", "aws_instances.json")
+	assert.Error(t, err)
+}
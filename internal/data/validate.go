@@ -0,0 +1,329 @@
+package data
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FileValidation is the result of the validation of a data file
+type FileValidation struct {
+	File     string
+	Override bool     // the file of the data path overrides the embedded one
+	Errors   []string `json:",omitempty"`
+	Missing  []string `json:",omitempty"` // keys (regions, instance types...) of the embedded file missing in the overriding one
+}
+
+// ValidationReport is the result of the validation of a data path
+type ValidationReport struct {
+	DataPath     string
+	Files        []FileValidation
+	UnknownFiles []string `json:",omitempty"` // files of the data path that are not data files, thus ignored
+}
+
+// IsValid returns true if no file of the report has errors
+func (r ValidationReport) IsValid() bool {
+	for _, file := range r.Files {
+		if len(file.Errors) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type csvColumn struct {
+	name    string
+	numeric bool
+}
+
+// csvSchema lists the columns read by carbonifer, the key columns identify a row (region, CPU, GPU...)
+type csvSchema struct {
+	columns    []csvColumn
+	keyColumns []string
+}
+
+var regionEmissionsSchema = csvSchema{
+	columns: []csvColumn{
+		{name: "Region"},
+		{name: "Location"},
+		{name: "Grid carbon intensity (gCO2eq / kWh)", numeric: true},
+	},
+	keyColumns: []string{"Region"},
+}
+
+var csvSchemas = map[string]csvSchema{
+	"aws_co2_region.csv": regionEmissionsSchema,
+	"gcp_co2_region.csv": regionEmissionsSchema,
+	"gcp_watt_cpu.csv": {
+		columns: []csvColumn{
+			{name: "Architecture"},
+			{name: "Min Watts", numeric: true},
+			{name: "Max Watts", numeric: true},
+			{name: "GB/Chip", numeric: true},
+		},
+		keyColumns: []string{"Architecture"},
+	},
+	"gpu_watt.csv": {
+		columns: []csvColumn{
+			{name: "name"},
+			{name: "min watts", numeric: true},
+			{name: "max watts", numeric: true},
+		},
+		keyColumns: []string{"name"},
+	},
+	"regions_location.csv": {
+		columns: []csvColumn{
+			{name: "Provider"},
+			{name: "Region"},
+			{name: "Country"},
+			{name: "Continent"},
+		},
+		keyColumns: []string{"Provider", "Region"},
+	},
+}
+
+// jsonValidators check a JSON data file and return its keys.
+// Types mirror the ones of the providers and coefficients packages, which depend on this package.
+var jsonValidators = map[string]func(content []byte) ([]string, []string){
+	"gcp_instances.json": func(content []byte) ([]string, []string) {
+		var machineTypes map[string]struct {
+			Name     string   `json:"name"`
+			Vcpus    int32    `json:"vcpus"`
+			GPUTypes []string `json:"gpus"`
+			MemoryMb int32    `json:"memoryMb"`
+			CPUTypes []string `json:"cpuTypes"`
+		}
+		if err := json.Unmarshal(content, &machineTypes); err != nil {
+			return nil, []string{err.Error()}
+		}
+		errs := []string{}
+		for name, machineType := range machineTypes {
+			if machineType.Vcpus <= 0 || machineType.MemoryMb <= 0 {
+				errs = append(errs, fmt.Sprintf("%v: vcpus and memoryMb must be positive", name))
+			}
+		}
+		return mapKeys(machineTypes), errs
+	},
+	"aws_instances.json": func(content []byte) ([]string, []string) {
+		var instanceTypes map[string]struct {
+			InstanceType    string   `json:"InstanceType"`
+			VCPU            int32    `json:"VCPU"`
+			MemoryMb        int32    `json:"MemoryMb"`
+			GPUs            []string `json:"GPUs"`
+			InstanceStorage struct {
+				SizePerDiskGB int64  `json:"SizePerDiskGB"`
+				Count         int32  `json:"Count"`
+				Type          string `json:"Type"`
+			} `json:"InstanceStorage"`
+		}
+		if err := json.Unmarshal(content, &instanceTypes); err != nil {
+			return nil, []string{err.Error()}
+		}
+		errs := []string{}
+		for name, instanceType := range instanceTypes {
+			if instanceType.VCPU <= 0 || instanceType.MemoryMb <= 0 {
+				errs = append(errs, fmt.Sprintf("%v: VCPU and MemoryMb must be positive", name))
+			}
+			storageType := strings.ToLower(instanceType.InstanceStorage.Type)
+			if instanceType.InstanceStorage.Count > 0 && storageType != "ssd" && storageType != "hdd" {
+				errs = append(errs, fmt.Sprintf("%v: unknown instance storage type '%v'", name, instanceType.InstanceStorage.Type))
+			}
+		}
+		return mapKeys(instanceTypes), errs
+	},
+	"gcp_sql_tiers.json": func(content []byte) ([]string, []string) {
+		var tiers map[string]struct {
+			Name        string `json:"name"`
+			Vcpus       int64  `json:"vcpus"`
+			MemoryMb    int64  `json:"memoryMb"`
+			DiskQuotaGB int64  `json:"DiskQuotaGB"`
+		}
+		if err := json.Unmarshal(content, &tiers); err != nil {
+			return nil, []string{err.Error()}
+		}
+		errs := []string{}
+		for name, tier := range tiers {
+			if tier.Vcpus <= 0 || tier.MemoryMb <= 0 {
+				errs = append(errs, fmt.Sprintf("%v: vcpus and memoryMb must be positive", name))
+			}
+		}
+		return mapKeys(tiers), errs
+	},
+	"energy_coefficients.json": func(content []byte) ([]string, []string) {
+		var coefficients map[string]map[string]float64
+		if err := json.Unmarshal(content, &coefficients); err != nil {
+			return nil, []string{err.Error()}
+		}
+		errs := []string{}
+		for provider, providerCoefficients := range coefficients {
+			for _, name := range []string{"cpu_min_wh", "cpu_max_wh", "storage_hdd_wh_tb", "storage_ssd_wh_tb", "networking_wh_gb", "memory_wh_gb", "pue_average"} {
+				if _, ok := providerCoefficients[name]; !ok {
+					errs = append(errs, fmt.Sprintf("%v: missing coefficient %v", provider, name))
+				}
+			}
+		}
+		return mapKeys(coefficients), errs
+	},
+}
+
+// ValidateDataPath checks the files of a custom data path against the format of the embedded data files
+func ValidateDataPath(dataPath string) (*ValidationReport, error) {
+	info, err := os.Stat(dataPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot read data path %v", dataPath)
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("Data path %v is not a directory", dataPath)
+	}
+
+	embeddedFiles, err := fs.ReadDir(data, "data")
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot list embedded data files")
+	}
+	known := map[string]bool{}
+	report := ValidationReport{
+		DataPath:     dataPath,
+		Files:        []FileValidation{},
+		UnknownFiles: []string{},
+	}
+	for _, embeddedFile := range embeddedFiles {
+		filename := embeddedFile.Name()
+		known[filename] = true
+		validation := FileValidation{File: filename}
+		content, err := os.ReadFile(filepath.Join(dataPath, filename))
+		if os.IsNotExist(err) {
+			report.Files = append(report.Files, validation)
+			continue
+		}
+		validation.Override = true
+		if err != nil {
+			validation.Errors = []string{err.Error()}
+		} else {
+			validation.Errors, validation.Missing = validateFile(filename, content)
+		}
+		report.Files = append(report.Files, validation)
+	}
+
+	customFiles, err := os.ReadDir(dataPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot list data path %v", dataPath)
+	}
+	for _, customFile := range customFiles {
+		if !customFile.IsDir() && !known[customFile.Name()] {
+			report.UnknownFiles = append(report.UnknownFiles, customFile.Name())
+		}
+	}
+	return &report, nil
+}
+
+// validateFile returns the errors of a data file and the keys of the embedded file it misses
+func validateFile(filename string, content []byte) ([]string, []string) {
+	var keys, errs []string
+	if schema, ok := csvSchemas[filename]; ok {
+		keys, errs = validateCSV(content, schema)
+	} else if validator, ok := jsonValidators[filename]; ok {
+		keys, errs = validator(content)
+	} else {
+		return nil, nil
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errs, nil
+	}
+
+	embeddedContent, err := readEmbeddedFile(filename)
+	if err != nil {
+		return nil, nil
+	}
+	var embeddedKeys []string
+	if schema, ok := csvSchemas[filename]; ok {
+		embeddedKeys, _ = validateCSV(embeddedContent, schema)
+	} else {
+		embeddedKeys, _ = jsonValidators[filename](embeddedContent)
+	}
+	return nil, missingKeys(embeddedKeys, keys)
+}
+
+func validateCSV(content []byte, schema csvSchema) ([]string, []string) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, []string{err.Error()}
+	}
+	if len(records) == 0 {
+		return nil, []string{"file is empty"}
+	}
+
+	indexes := map[string]int{}
+	for i, column := range records[0] {
+		indexes[column] = i
+	}
+	errs := []string{}
+	for _, column := range schema.columns {
+		if _, ok := indexes[column.name]; !ok {
+			errs = append(errs, fmt.Sprintf("missing column '%v'", column.name))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	keys := []string{}
+	seen := map[string]bool{}
+	for line, record := range records[1:] {
+		for _, column := range schema.columns {
+			value := record[indexes[column.name]]
+			if column.numeric {
+				if _, err := strconv.ParseFloat(value, 64); err != nil {
+					errs = append(errs, fmt.Sprintf("line %v: column '%v' is not a number: '%v'", line+2, column.name, value))
+				}
+			}
+		}
+		keyValues := []string{}
+		for _, keyColumn := range schema.keyColumns {
+			keyValues = append(keyValues, record[indexes[keyColumn]])
+		}
+		key := strings.Join(keyValues, "/")
+		if strings.TrimSpace(strings.ReplaceAll(key, "/", "")) == "" {
+			errs = append(errs, fmt.Sprintf("line %v: empty %v", line+2, strings.Join(schema.keyColumns, "/")))
+			continue
+		}
+		if seen[key] {
+			errs = append(errs, fmt.Sprintf("line %v: duplicate %v", line+2, key))
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+	return keys, errs
+}
+
+func missingKeys(expected []string, actual []string) []string {
+	actualKeys := map[string]bool{}
+	for _, key := range actual {
+		actualKeys[key] = true
+	}
+	missing := []string{}
+	for _, key := range expected {
+		if !actualKeys[key] {
+			missing = append(missing, key)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,65 @@
+package data
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getFileValidation(report *ValidationReport, filename string) FileValidation {
+	for _, file := range report.Files {
+		if file.File == filename {
+			return file
+		}
+	}
+	return FileValidation{}
+}
+
+func TestValidateDataPath_TestData(t *testing.T) {
+	report, err := ValidateDataPath("../../test/data")
+	assert.NoError(t, err)
+	assert.True(t, report.IsValid())
+
+	gcpInstances := getFileValidation(report, "gcp_instances.json")
+	assert.True(t, gcpInstances.Override)
+	assert.Empty(t, gcpInstances.Errors)
+	assert.Contains(t, gcpInstances.Missing, "n2-standard-2")
+	assert.NotContains(t, gcpInstances.Missing, "e2-standard-2")
+
+	regionsLocation := getFileValidation(report, "regions_location.csv")
+	assert.False(t, regionsLocation.Override)
+}
+
+func TestValidateDataPath_Malformed(t *testing.T) {
+	dataPath := t.TempDir()
+	files := map[string]string{
+		"gcp_co2_region.csv":       "Region,Location,Grid carbon intensity (gCO2eq / kWh)\neurope-west9,Paris,low\neurope-west9,Paris,16\n",
+		"gpu_watt.csv":             "name,min watts\nnvidia-t4,8\n",
+		"aws_instances.json":       `{"m5.large": {"InstanceType": "m5.large", "VCPU": "2"}}`,
+		"energy_coefficients.json": `{"GCP": {"cpu_min_wh": 0.71}}`,
+		"notes.txt":                "not a data file",
+	}
+	for filename, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dataPath, filename), []byte(content), 0644))
+	}
+
+	report, err := ValidateDataPath(dataPath)
+	assert.NoError(t, err)
+	assert.False(t, report.IsValid())
+	assert.Equal(t, []string{"notes.txt"}, report.UnknownFiles)
+
+	assert.Equal(t, []string{
+		"line 2: column 'Grid carbon intensity (gCO2eq / kWh)' is not a number: 'low'",
+		"line 3: duplicate europe-west9",
+	}, getFileValidation(report, "gcp_co2_region.csv").Errors)
+	assert.Equal(t, []string{"missing column 'max watts'"}, getFileValidation(report, "gpu_watt.csv").Errors)
+	assert.Len(t, getFileValidation(report, "aws_instances.json").Errors, 1)
+	assert.Contains(t, getFileValidation(report, "energy_coefficients.json").Errors, "GCP: missing coefficient pue_average")
+}
+
+func TestValidateDataPath_NotDirectory(t *testing.T) {
+	_, err := ValidateDataPath("validate.go")
+	assert.Error(t, err)
+}
@@ -1,21 +1,16 @@
 package coefficients
 
 import (
+	"sort"
 	"strings"
 
-	"github.com/pkg/errors"
-
 	"github.com/carboniferio/carbonifer/internal/data"
 	"github.com/carboniferio/carbonifer/internal/providers"
 	"github.com/shopspring/decimal"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/yunabe/easycsv"
 )
 
-// EmissionsPerRegion is a map of regions to their emissions
-var EmissionsPerRegion map[string]Emissions
-
 // Emissions is the emissions of a region
 type Emissions struct {
 	Region              string
@@ -23,30 +18,58 @@ type Emissions struct {
 	GridCarbonIntensity decimal.Decimal
 }
 
-// RegionEmission returns the emissions of a region
-func RegionEmission(provider providers.Provider, region string) (*Emissions, error) {
-	var dataFile string
+// RegionEmissionsFile returns the data file of the emissions per region of a provider
+func RegionEmissionsFile(provider providers.Provider) string {
 	switch provider {
 	case providers.AWS:
-		dataFile = "aws_co2_region.csv"
+		return "aws_co2_region.csv"
 	case providers.GCP:
-		dataFile = "gcp_co2_region.csv"
+		return "gcp_co2_region.csv"
 	default:
-		return nil, errors.New("Provider not supported")
+		return ""
 	}
-	if EmissionsPerRegion == nil {
-		EmissionsPerRegion = loadEmissionsPerRegion(dataFile)
+}
+
+// RegionEmission returns the emissions of a region
+func RegionEmission(dataPath string, provider providers.Provider, region string) (*Emissions, error) {
+	dataFile := RegionEmissionsFile(provider)
+	if dataFile == "" {
+		return nil, &providers.UnsupportedProviderError{Provider: provider.String()}
 	}
 	if region == "" {
-		return nil, errors.New("Region cannot be empty")
+		return nil, &UnknownRegionError{Provider: provider}
 	}
-	emissions, ok := EmissionsPerRegion[region]
+	emissionsPerRegion, err := data.Load(dataPath, dataFile, loadEmissionsPerRegion)
+	if err != nil {
+		return nil, err
+	}
+	emissions, ok := emissionsPerRegion[region]
 	if !ok {
-		return nil, errors.Errorf("Region does not exist: '%v'", region)
+		return nil, &UnknownRegionError{Provider: provider, Region: region}
 	}
 	return &emissions, nil
 }
 
+// RegionsEmissions returns the emissions of all regions of a provider, sorted by region
+func RegionsEmissions(dataPath string, provider providers.Provider) ([]Emissions, error) {
+	dataFile := RegionEmissionsFile(provider)
+	if dataFile == "" {
+		return nil, &providers.UnsupportedProviderError{Provider: provider.String()}
+	}
+	emissionsPerRegion, err := data.Load(dataPath, dataFile, loadEmissionsPerRegion)
+	if err != nil {
+		return nil, err
+	}
+	regionsEmissions := []Emissions{}
+	for _, emissions := range emissionsPerRegion {
+		regionsEmissions = append(regionsEmissions, emissions)
+	}
+	sort.Slice(regionsEmissions, func(i, j int) bool {
+		return regionsEmissions[i].Region < regionsEmissions[j].Region
+	})
+	return regionsEmissions, nil
+}
+
 type emissionsCSV struct {
 	Region              string  `name:"Region"`
 	Location            string  `name:"Location"`
@@ -54,13 +77,11 @@ type emissionsCSV struct {
 }
 
 // Source: Google
-func loadEmissionsPerRegion(dataFile string) map[string]Emissions {
+func loadEmissionsPerRegion(regionEmissionFile []byte) (map[string]Emissions, error) {
 	// Read the CSV records
 	var records []emissionsCSV
-	regionEmissionFile := data.ReadDataFile(dataFile)
-	log.Debugf("reading GCP region/grid emissions from: %v", dataFile)
 	if err := easycsv.NewReader(strings.NewReader(string(regionEmissionFile))).ReadAll(&records); err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	// Create a map to store the data
@@ -75,5 +96,5 @@ func loadEmissionsPerRegion(dataFile string) map[string]Emissions {
 			GridCarbonIntensity: decimal.NewFromFloat(record.GridCarbonIntensity),
 		}
 	}
-	return data
+	return data, nil
 }
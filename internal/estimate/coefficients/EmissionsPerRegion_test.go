@@ -0,0 +1,24 @@
+package coefficients
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/providers"
+	_ "github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegionEmission_SeveralProviders(t *testing.T) {
+	gcpEmissions, err := RegionEmission(viper.GetString("data.path"), providers.GCP, "asia-east1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Taiwan", gcpEmissions.Location)
+
+	// Emissions of the first provider loaded are not used for the other one
+	awsEmissions, err := RegionEmission(viper.GetString("data.path"), providers.AWS, "us-east-1")
+	assert.NoError(t, err)
+	assert.True(t, decimal.NewFromFloat(415.755).Equal(awsEmissions.GridCarbonIntensity))
+	_, err = RegionEmission(viper.GetString("data.path"), providers.AWS, "asia-east1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package coefficients
+
+import (
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/data"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/pkg/errors"
+	"github.com/yunabe/easycsv"
+)
+
+// RegionLocation is the country and continent of a cloud provider region
+type RegionLocation struct {
+	Provider  providers.Provider
+	Region    string
+	Country   string
+	Continent string
+}
+
+// GetRegionLocation returns the country and continent of a region, false if unknown
+func GetRegionLocation(dataPath string, provider providers.Provider, region string) (RegionLocation, bool, error) {
+	regionLocations, err := data.Load(dataPath, "regions_location.csv", loadRegionLocations)
+	if err != nil {
+		return RegionLocation{}, false, errors.Wrap(err, "Cannot read regions locations")
+	}
+	location, ok := regionLocations[provider][region]
+	return location, ok, nil
+}
+
+type regionLocationCSV struct {
+	Provider  string `name:"Provider"`
+	Region    string `name:"Region"`
+	Country   string `name:"Country"`
+	Continent string `name:"Continent"`
+}
+
+func loadRegionLocations(regionLocationFile []byte) (map[providers.Provider]map[string]RegionLocation, error) {
+	var records []regionLocationCSV
+	if err := easycsv.NewReader(strings.NewReader(string(regionLocationFile))).ReadAll(&records); err != nil {
+		return nil, err
+	}
+
+	locations := make(map[providers.Provider]map[string]RegionLocation)
+	for _, record := range records {
+		provider, err := providers.ParseProvider(record.Provider)
+		if err != nil {
+			return nil, errors.Errorf("Unknown provider in regions_location.csv: %v", record.Provider)
+		}
+		if locations[provider] == nil {
+			locations[provider] = make(map[string]RegionLocation)
+		}
+		locations[provider][record.Region] = RegionLocation{
+			Provider:  provider,
+			Region:    record.Region,
+			Country:   record.Country,
+			Continent: record.Continent,
+		}
+	}
+	return locations, nil
+}
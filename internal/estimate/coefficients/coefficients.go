@@ -1,13 +1,11 @@
 package coefficients
 
 import (
-	"encoding/json"
 	"reflect"
 
 	"github.com/carboniferio/carbonifer/internal/data"
 	"github.com/carboniferio/carbonifer/internal/providers"
 	"github.com/shopspring/decimal"
-	log "github.com/sirupsen/logrus"
 )
 
 // Coefficients is a struct that contains the coefficients for the energy estimation
@@ -28,23 +26,14 @@ type CoefficientsProviders struct {
 	Azure Coefficients `json:"Azure"`
 }
 
-var coefficientsPerProviders *CoefficientsProviders
-
 // GetEnergyCoefficients returns the coefficients for the energy estimation
-func GetEnergyCoefficients() *CoefficientsProviders {
-	if coefficientsPerProviders == nil {
-		energyCoefFile := data.ReadDataFile("energy_coefficients.json")
-		err := json.Unmarshal(energyCoefFile, &coefficientsPerProviders)
-		if err != nil {
-			log.Fatal(err)
-		}
-	}
-	return coefficientsPerProviders
+func GetEnergyCoefficients(dataPath string) (*CoefficientsProviders, error) {
+	return data.LoadJSON[*CoefficientsProviders](dataPath, "energy_coefficients.json")
 }
 
 // GetByProvider returns the coefficients for the energy estimation of a provider
 func (cps *CoefficientsProviders) GetByProvider(provider providers.Provider) Coefficients {
-	return coefficientsPerProviders.getByProviderName(provider.String())
+	return cps.getByProviderName(provider.String())
 }
 
 func (cps *CoefficientsProviders) getByProviderName(name string) Coefficients {
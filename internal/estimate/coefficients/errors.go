@@ -0,0 +1,20 @@
+package coefficients
+
+import (
+	"fmt"
+
+	"github.com/carboniferio/carbonifer/internal/providers"
+)
+
+// UnknownRegionError is an error that occurs when a region has no carbon intensity in the data files
+type UnknownRegionError struct {
+	Provider providers.Provider
+	Region   string
+}
+
+func (e *UnknownRegionError) Error() string {
+	if e.Region == "" {
+		return fmt.Sprintf("No region for %v resource", e.Provider)
+	}
+	return fmt.Sprintf("Unknown %v region: '%v'", e.Provider, e.Region)
+}
@@ -0,0 +1,162 @@
+package estimate
+
+import (
+	"sort"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// EstimateDelta estimates the difference of power and carbon emissions between resources before and after a change,
+// see EstimateResources for config
+func EstimateDelta(config *viper.Viper, before map[string]resources.Resource, after map[string]resources.Resource, actions map[string]string, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) *estimation.EstimationDelta {
+	return computeDelta(
+		estimateForDelta(config, before, forecastCarbonIntensity, forecastRegion),
+		estimateForDelta(config, after, forecastCarbonIntensity, forecastRegion),
+		func(address string, _ *estimation.EstimationResource, _ *estimation.EstimationResource) string {
+			if action, ok := actions[address]; ok {
+				return action
+			}
+			return plan.ActionNoOp
+		},
+	)
+}
+
+// DiffReports computes the difference of power and carbon emissions between two estimation reports.
+// Resources only in head are "create", only in base are "delete", and "update" if their estimation changed.
+func DiffReports(base estimation.EstimationReport, head estimation.EstimationReport) *estimation.EstimationDelta {
+	return computeDelta(
+		reportEstimationsByAddress(base),
+		reportEstimationsByAddress(head),
+		func(_ string, before *estimation.EstimationResource, after *estimation.EstimationResource) string {
+			switch {
+			case before == nil:
+				return plan.ActionCreate
+			case after == nil:
+				return plan.ActionDelete
+			case !totalCarbonEmissions(before).Equal(totalCarbonEmissions(after)) || !totalPower(before).Equal(totalPower(after)):
+				return plan.ActionUpdate
+			default:
+				return plan.ActionNoOp
+			}
+		},
+	)
+}
+
+// SortDeltas sorts a list of resource deltas by absolute carbon emissions delta (biggest first), then by address
+func SortDeltas(deltas *[]estimation.EstimationResourceDelta) {
+	sort.SliceStable(*deltas, func(i, j int) bool {
+		absI := (*deltas)[i].CarbonEmissions.Abs()
+		absJ := (*deltas)[j].CarbonEmissions.Abs()
+		if !absI.Equal(absJ) {
+			return absI.GreaterThan(absJ)
+		}
+		return (*deltas)[i].Address < (*deltas)[j].Address
+	})
+}
+
+type actionResolver func(address string, before *estimation.EstimationResource, after *estimation.EstimationResource) string
+
+func computeDelta(before map[string]*estimation.EstimationResource, after map[string]*estimation.EstimationResource, resolveAction actionResolver) *estimation.EstimationDelta {
+	addresses := map[string]bool{}
+	for address := range before {
+		addresses[address] = true
+	}
+	for address := range after {
+		addresses[address] = true
+	}
+	sortedAddresses := []string{}
+	for address := range addresses {
+		sortedAddresses = append(sortedAddresses, address)
+	}
+	sort.Strings(sortedAddresses)
+
+	delta := estimation.EstimationDelta{
+		Resources: []estimation.EstimationResourceDelta{},
+		Before:    zeroTotal(),
+		After:     zeroTotal(),
+		Total:     zeroTotal(),
+	}
+	for _, address := range sortedAddresses {
+		resourceDelta := estimation.EstimationResourceDelta{
+			Address: address,
+			Before:  before[address],
+			After:   after[address],
+		}
+		resourceDelta.Action = resolveAction(address, resourceDelta.Before, resourceDelta.After)
+		addToTotal(&delta.Before, resourceDelta.Before)
+		addToTotal(&delta.After, resourceDelta.After)
+		resourceDelta.Power = totalPower(resourceDelta.After).Sub(totalPower(resourceDelta.Before))
+		resourceDelta.CarbonEmissions = totalCarbonEmissions(resourceDelta.After).Sub(totalCarbonEmissions(resourceDelta.Before))
+		delta.Resources = append(delta.Resources, resourceDelta)
+	}
+
+	delta.Total.Power = delta.After.Power.Sub(delta.Before.Power)
+	delta.Total.CarbonEmissions = delta.After.CarbonEmissions.Sub(delta.Before.CarbonEmissions)
+	delta.Total.ResourcesCount = delta.After.ResourcesCount.Sub(delta.Before.ResourcesCount)
+	return &delta
+}
+
+func estimateForDelta(config *viper.Viper, resourceList map[string]resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) map[string]*estimation.EstimationResource {
+	estimations := map[string]*estimation.EstimationResource{}
+	for address, resource := range resourceList {
+		estimationResource, err := EstimateResource(config, resource, forecastCarbonIntensity, forecastRegion)
+		if err != nil {
+			logrus.Warnf("Skipping %v: %v", resource.GetAddress(), err)
+			continue
+		}
+		estimations[address] = estimationResource
+	}
+	return estimations
+}
+
+func reportEstimationsByAddress(report estimation.EstimationReport) map[string]*estimation.EstimationResource {
+	estimations := map[string]*estimation.EstimationResource{}
+	for i := range report.Resources {
+		estimationResource := report.Resources[i]
+		estimations[estimationResource.Resource.GetAddress()] = &estimationResource
+	}
+	for _, resource := range report.UnsupportedResources {
+		unsupportedResource, ok := resource.(resources.UnsupportedResource)
+		if !ok {
+			continue
+		}
+		estimations[resource.GetAddress()] = estimateNotSupported(unsupportedResource)
+	}
+	return estimations
+}
+
+func zeroTotal() estimation.EstimationTotal {
+	return estimation.EstimationTotal{
+		Power:           decimal.Zero,
+		CarbonEmissions: decimal.Zero,
+		ResourcesCount:  decimal.Zero,
+	}
+}
+
+func addToTotal(total *estimation.EstimationTotal, estimationResource *estimation.EstimationResource) {
+	if estimationResource == nil {
+		return
+	}
+	total.Power = total.Power.Add(totalPower(estimationResource))
+	total.CarbonEmissions = total.CarbonEmissions.Add(totalCarbonEmissions(estimationResource))
+	total.ResourcesCount = total.ResourcesCount.Add(estimationResource.TotalCount)
+}
+
+func totalPower(estimationResource *estimation.EstimationResource) decimal.Decimal {
+	if estimationResource == nil {
+		return decimal.Zero
+	}
+	return estimationResource.Power.Mul(estimationResource.TotalCount)
+}
+
+func totalCarbonEmissions(estimationResource *estimation.EstimationResource) decimal.Decimal {
+	if estimationResource == nil {
+		return decimal.Zero
+	}
+	return estimationResource.CarbonEmissions.Mul(estimationResource.TotalCount)
+}
@@ -0,0 +1,77 @@
+package estimate
+
+import (
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/resources"
+	_ "github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimateDelta(t *testing.T) {
+	viper.Set("unit.carbon", "g")
+	viper.Set("unit.time", "h")
+
+	before := map[string]resources.Resource{
+		"google_compute_instance.machine-name-1": resourceGCPComputeBasic,
+		"google_compute_instance.machine-name-2": resourceGCPComputeCPUType,
+	}
+	after := map[string]resources.Resource{
+		"google_compute_instance.machine-name-2":        resourceGCPComputeCPUType,
+		"google_compute_instance_group.machine-group-1": resourceGCPInstanceGroup,
+	}
+	actions := map[string]string{
+		"google_compute_instance.machine-name-1":        "delete",
+		"google_compute_instance_group.machine-group-1": "create",
+	}
+
+	got := EstimateDelta(viper.GetViper(), before, after, actions, nil, "")
+
+	assert.Len(t, got.Resources, 3)
+	assert.Equal(t, "google_compute_instance.machine-name-1", got.Resources[0].Address)
+	assert.Equal(t, "delete", got.Resources[0].Action)
+	assert.Nil(t, got.Resources[0].After)
+	assert.Equal(t, decimal.NewFromFloat(-0.448446256).String(), got.Resources[0].CarbonEmissions.String())
+
+	assert.Equal(t, "no-op", got.Resources[1].Action)
+	assert.True(t, got.Resources[1].CarbonEmissions.IsZero())
+
+	assert.Equal(t, "create", got.Resources[2].Action)
+	assert.Nil(t, got.Resources[2].Before)
+	assert.Equal(t, decimal.NewFromFloat(1.345338768).String(), got.Resources[2].CarbonEmissions.String())
+
+	// 3 instances created, 1 deleted
+	assert.Equal(t, decimal.NewFromFloat(0.896892512).String(), got.Total.CarbonEmissions.String())
+	assert.Equal(t, decimal.NewFromInt(2).String(), got.Total.ResourcesCount.String())
+}
+
+func TestDiffReports(t *testing.T) {
+	viper.Set("unit.carbon", "g")
+	viper.Set("unit.time", "h")
+
+	base := EstimateResources(viper.GetViper(), map[string]resources.Resource{
+		"google_compute_instance.machine-name-1": resourceGCPComputeBasic,
+		"google_compute_instance.machine-name-2": resourceGCPComputeCPUType,
+	}, nil, "")
+	head := EstimateResources(viper.GetViper(), map[string]resources.Resource{
+		"google_compute_instance.machine-name-1":        resourceGCPComputeBasic,
+		"google_compute_instance_group.machine-group-1": resourceGCPInstanceGroup,
+	}, nil, "")
+
+	got := DiffReports(base, head)
+	SortDeltas(&got.Resources)
+
+	assert.Len(t, got.Resources, 3)
+	assert.Equal(t, "google_compute_instance_group.machine-group-1", got.Resources[0].Address)
+	assert.Equal(t, "create", got.Resources[0].Action)
+	assert.Equal(t, "google_compute_instance.machine-name-2", got.Resources[1].Address)
+	assert.Equal(t, "delete", got.Resources[1].Action)
+	assert.Equal(t, "google_compute_instance.machine-name-1", got.Resources[2].Address)
+	assert.Equal(t, "no-op", got.Resources[2].Action)
+
+	percentChange, ok := got.PercentChange()
+	assert.True(t, ok)
+	assert.Equal(t, "77.20", percentChange.StringFixed(2))
+}
@@ -10,26 +10,47 @@ import (
 
 	"github.com/carboniferio/carbonifer/internal/providers"
 	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/utils"
 	"github.com/shopspring/decimal"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
-// EstimateResources estimates the power and carbon emissions of a list of resources
-func EstimateResources(resourceList map[string]resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) estimation.EstimationReport {
+// EstimateResources estimates the power and carbon emissions of a list of resources with the units, usage and data of
+// config, resources that cannot be estimated are in the errors of the report
+func EstimateResources(config *viper.Viper, resourceList map[string]resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) estimation.EstimationReport {
 
 	var estimationResources []estimation.EstimationResource
 	var unsupportedResources []resources.Resource
+	var resourceErrors []resources.ResourceError
 	estimationTotal := estimation.EstimationTotal{
 		Power:           decimal.Zero,
 		CarbonEmissions: decimal.Zero,
 		ResourcesCount:  decimal.Zero,
 	}
 
-	for _, resource := range resourceList {
-		estimationResource, uerr := EstimateResource(resource, forecastCarbonIntensity, forecastRegion)
-		if uerr != nil {
-			logrus.Warnf("Skipping unsupported provider %v: %v.%v", uerr.Provider, resource.GetIdentification().ResourceType, resource.GetIdentification().Name)
+	// Resources are estimated in parallel, in the order of their keys so that the report is deterministic
+	keys := make([]string, 0, len(resourceList))
+	for key := range resourceList {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	type resourceEstimation struct {
+		estimation *estimation.EstimationResource
+		err        error
+	}
+	estimations := utils.ParallelMap(keys, func(key string) resourceEstimation {
+		estimationResource, err := EstimateResource(config, resourceList[key], forecastCarbonIntensity, forecastRegion)
+		return resourceEstimation{estimation: estimationResource, err: err}
+	})
+
+	for i, key := range keys {
+		resource := resourceList[key]
+		estimationResource, err := estimations[i].estimation, estimations[i].err
+		if err != nil {
+			logrus.Warnf("Skipping %v: %v", resource.GetAddress(), err)
+			resourceErrors = append(resourceErrors, resources.ResourceError{Address: resource.GetAddress(), Err: err})
+			continue
 		}
 
 		if resource.IsSupported() {
@@ -43,32 +64,14 @@ func EstimateResources(resourceList map[string]resources.Resource, forecastCarbo
 		estimationTotal.ResourcesCount = estimationTotal.ResourcesCount.Add(estimationResource.TotalCount)
 	}
 
-	unitTime := viper.GetString("unit.time")
-	if unitTime == "" {
-		unitTime = "h" // Fallback to "h"
-	}
-
-	return estimation.EstimationReport{
-		Info: estimation.EstimationInfo{
-			UnitTime:                unitTime,
-			UnitWattTime:            fmt.Sprintf("W%s", unitTime),
-			UnitCarbonEmissionsTime: fmt.Sprintf("%sCO2eq/%s", viper.GetString("unit.carbon"), unitTime),
-			DateTime:                time.Now(),
-			InfoByProvider: map[providers.Provider]estimation.InfoByProvider{
-				providers.GCP: {
-					AverageCPUUsage: viper.GetFloat64("provider.gcp.avg_cpu_use"),
-					AverageGPUUsage: viper.GetFloat64("provider.gcp.avg_gpu_use"),
-				},
-				providers.AWS: {
-					AverageCPUUsage: viper.GetFloat64("provider.gcp.avg_cpu_use"),
-					AverageGPUUsage: viper.GetFloat64("provider.gcp.avg_gpu_use"),
-				},
-			},
-		},
+	report := estimation.EstimationReport{
+		Info:                 getEstimationInfo(config),
 		Resources:            estimationResources,
 		UnsupportedResources: unsupportedResources,
 		Total:                estimationTotal,
 	}
+	report.AddErrors(resourceErrors...)
+	return report
 }
 
 // SortEstimations sorts a list of estimation resources by resource address
@@ -78,16 +81,14 @@ func SortEstimations(resources *[]estimation.EstimationResource) {
 	})
 }
 
-// EstimateResource estimates the power and carbon emissions of a resource
-func EstimateResource(resource resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) (*estimation.EstimationResource, *providers.UnsupportedProviderError) {
+// EstimateResource estimates the power and carbon emissions of a resource, see EstimateResources for config
+func EstimateResource(config *viper.Viper, resource resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) (*estimation.EstimationResource, error) {
 	if !resource.IsSupported() {
 		return estimateNotSupported(resource.(resources.UnsupportedResource)), nil
 	}
 	switch resource.GetIdentification().Provider {
-	case providers.AWS:
-		return estimate.EstimateSupportedResource(resource, forecastCarbonIntensity, forecastRegion), nil
-	case providers.GCP:
-		return estimate.EstimateSupportedResource(resource, forecastCarbonIntensity, forecastRegion), nil
+	case providers.AWS, providers.GCP:
+		return estimate.EstimateSupportedResource(config, resource, forecastCarbonIntensity, forecastRegion)
 	default:
 		return nil, &providers.UnsupportedProviderError{Provider: resource.GetIdentification().Provider.String()}
 	}
@@ -102,3 +103,52 @@ func estimateNotSupported(resource resources.UnsupportedResource) *estimation.Es
 		TotalCount:      decimal.Zero,
 	}
 }
+
+// getEstimationInfo returns the units and settings of config used for the estimations
+func getEstimationInfo(config *viper.Viper) estimation.EstimationInfo {
+	unitTime := config.GetString("unit.time")
+	if unitTime == "" {
+		unitTime = "h" // Fallback to "h"
+	}
+
+	return estimation.EstimationInfo{
+		UnitTime:                unitTime,
+		UnitWattTime:            fmt.Sprintf("W%s", unitTime),
+		UnitCarbonEmissionsTime: fmt.Sprintf("%sCO2eq/%s", config.GetString("unit.carbon"), unitTime),
+		DateTime:                time.Now(),
+		InfoByProvider: map[providers.Provider]estimation.InfoByProvider{
+			providers.GCP: {
+				AverageCPUUsage: config.GetFloat64("provider.gcp.avg_cpu_use"),
+				AverageGPUUsage: config.GetFloat64("provider.gcp.avg_gpu_use"),
+			},
+			providers.AWS: {
+				AverageCPUUsage: config.GetFloat64("provider.gcp.avg_cpu_use"),
+				AverageGPUUsage: config.GetFloat64("provider.gcp.avg_gpu_use"),
+			},
+		},
+	}
+}
+
+// ExplainResource estimates the power and carbon emissions of a resource, with each term of the estimation, see
+// EstimateResources for config
+func ExplainResource(config *viper.Viper, resource resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) (*estimation.EstimationExplanation, error) {
+	explanation := estimation.EstimationExplanation{
+		Info: getEstimationInfo(config),
+	}
+	if !resource.IsSupported() {
+		explanation.Estimation = *estimateNotSupported(resource.(resources.UnsupportedResource))
+		return &explanation, nil
+	}
+	switch resource.GetIdentification().Provider {
+	case providers.AWS, providers.GCP:
+		estimationResource, breakdown, err := estimate.ExplainSupportedResource(config, resource, forecastCarbonIntensity, forecastRegion)
+		if err != nil {
+			return nil, err
+		}
+		explanation.Estimation = *estimationResource
+		explanation.Breakdown = breakdown
+		return &explanation, nil
+	default:
+		return nil, &providers.UnsupportedProviderError{Provider: resource.GetIdentification().Provider.String()}
+	}
+}
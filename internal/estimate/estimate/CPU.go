@@ -12,21 +12,32 @@ import (
 	"github.com/spf13/viper"
 )
 
-func estimateWattCPU(resource *resources.ComputeResource) decimal.Decimal {
+// estimateWattCPUWithPlatform estimates the power of the CPUs of a resource, it also returns the CPU platform used,
+// nil if the provider coefficients were used
+func estimateWattCPUWithPlatform(config *viper.Viper, resource *resources.ComputeResource) (decimal.Decimal, *gcp.CPUWatt, error) {
 	provider := resource.Identification.Provider
 	// Get average CPU usage
-	averageCPUUse := decimal.NewFromFloat(viper.GetFloat64(fmt.Sprintf("provider.%s.avg_cpu_use", provider.String())))
+	averageCPUUse := decimal.NewFromFloat(config.GetFloat64(fmt.Sprintf("provider.%s.avg_cpu_use", provider.String())))
 
 	var avgWatts decimal.Decimal
+	var cpuWatt *gcp.CPUWatt
 	// Average Watts = Min Watts + Avg vCPU Utilization * (Max Watts - Min Watts)
 	cpuPlatform := resource.Specs.CPUType
 	if cpuPlatform != "" && resource.Identification.Provider == providers.GCP {
-		cpuPlatform := gcp.GetCPUWatt(strings.ToLower(cpuPlatform))
+		cpuPlatform, err := gcp.GetCPUWatt(config.GetString("data.path"), strings.ToLower(cpuPlatform))
+		if err != nil {
+			return decimal.Zero, nil, err
+		}
+		cpuWatt = &cpuPlatform
 		avgWatts = cpuPlatform.MinWatts.Add(averageCPUUse.Mul(cpuPlatform.MaxWatts.Sub(cpuPlatform.MinWatts)))
 	} else {
-		minWH := coefficients.GetEnergyCoefficients().GetByProvider(provider).CPUMinWh
-		maxWh := coefficients.GetEnergyCoefficients().GetByProvider(provider).CPUMaxWh
+		energyCoefficients, err := coefficients.GetEnergyCoefficients(config.GetString("data.path"))
+		if err != nil {
+			return decimal.Zero, nil, err
+		}
+		minWH := energyCoefficients.GetByProvider(provider).CPUMinWh
+		maxWh := energyCoefficients.GetByProvider(provider).CPUMaxWh
 		avgWatts = minWH.Add(averageCPUUse.Mul(maxWh.Sub(minWH)))
 	}
-	return avgWatts.Mul(decimal.NewFromInt32(resource.Specs.VCPUs))
+	return avgWatts.Mul(decimal.NewFromInt32(resource.Specs.VCPUs)), cpuWatt, nil
 }
@@ -1,24 +1,44 @@
 package estimate
 
 import (
+	"fmt"
+
 	"github.com/carboniferio/carbonifer/internal/estimate/coefficients"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
 	"github.com/carboniferio/carbonifer/internal/resources"
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
 )
 
+// explainWattHour estimates the power of a resource in Watt Hour, with each term of the estimation
 // Source: https://www.cloudcarbonfootprint.org/docs/methodology/#appendix-i-energy-coefficients
-// in Watt Hour
-func estimateWattHour(resource *resources.ComputeResource) decimal.Decimal {
-	cpuEstimationInWh := estimateWattCPU(resource)
+func explainWattHour(config *viper.Viper, resource *resources.ComputeResource) (*estimation.EstimationBreakdown, error) {
+	energyCoefficients, err := coefficients.GetEnergyCoefficients(config.GetString("data.path"))
+	if err != nil {
+		return nil, err
+	}
+	providerCoefficients := energyCoefficients.GetByProvider(resource.Identification.Provider)
+
+	cpuEstimationInWh, cpuWatt, err := estimateWattCPUWithPlatform(config, resource)
+	if err != nil {
+		return nil, err
+	}
 	log.Debugf("%v.%v CPU in Wh: %v", resource.Identification.ResourceType, resource.Identification.Name, cpuEstimationInWh)
-	memoryEstimationInWH := estimateWattMem(resource)
+	memoryEstimationInWH := estimateWattMem(resource, providerCoefficients)
 	log.Debugf("%v.%v Memory in Wh: %v", resource.Identification.ResourceType, resource.Identification.Name, memoryEstimationInWH)
-	storageInWh := estimateWattStorage(resource)
+	storageInWh := estimateWattStorage(resource, providerCoefficients)
 	log.Debugf("%v.%v Storage in Wh: %v", resource.Identification.ResourceType, resource.Identification.Name, storageInWh)
-	gpuEstimationInWh := EstimateWattGPU(resource)
+	gpuWatts, err := getGPUWatts(config, resource)
+	if err != nil {
+		return nil, err
+	}
+	gpuEstimationInWh, err := EstimateWattGPU(config, resource)
+	if err != nil {
+		return nil, err
+	}
 	log.Debugf("%v.%v GPUs in Wh: %v", resource.Identification.ResourceType, resource.Identification.Name, gpuEstimationInWh)
-	pue := coefficients.GetEnergyCoefficients().GetByProvider(resource.Identification.Provider).PueAverage
+	pue := providerCoefficients.PueAverage
 
 	log.Debugf("%v.%v PUE %v", resource.Identification.ResourceType, resource.Identification.Name, pue)
 	rawWattEstimate := decimal.Sum(
@@ -33,5 +53,21 @@ func estimateWattHour(resource *resources.ComputeResource) decimal.Decimal {
 	}
 	wattEstimate := pue.Mul(rawWattEstimate).Mul(decimal.NewFromInt32(replicationFactor))
 	log.Debugf("%v.%v Energy in Wh: %v", resource.Identification.ResourceType, resource.Identification.Name, wattEstimate)
-	return wattEstimate
+
+	provider := resource.Identification.Provider
+	return &estimation.EstimationBreakdown{
+		CPU:               cpuEstimationInWh,
+		Memory:            memoryEstimationInWH,
+		Storage:           storageInWh,
+		GPU:               gpuEstimationInWh,
+		RawPower:          rawWattEstimate,
+		PUE:               pue,
+		ReplicationFactor: replicationFactor,
+		Power:             wattEstimate,
+		AverageCPUUsage:   decimal.NewFromFloat(config.GetFloat64(fmt.Sprintf("provider.%s.avg_cpu_use", provider.String()))),
+		AverageGPUUsage:   decimal.NewFromFloat(config.GetFloat64(fmt.Sprintf("provider.%s.avg_gpu_use", provider.String()))),
+		Coefficients:      providerCoefficients,
+		CPUWatt:           cpuWatt,
+		GPUWatts:          gpuWatts,
+	}, nil
 }
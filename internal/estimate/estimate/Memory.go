@@ -6,7 +6,6 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-func estimateWattMem(resource *resources.ComputeResource) decimal.Decimal {
-	provider := resource.Identification.Provider
-	return decimal.NewFromInt32(resource.Specs.MemoryMb).Div(decimal.NewFromInt32(1024)).Mul(coefficients.GetEnergyCoefficients().GetByProvider(provider).MemoryWhGb)
+func estimateWattMem(resource *resources.ComputeResource, energyCoefficients coefficients.Coefficients) decimal.Decimal {
+	return decimal.NewFromInt32(resource.Specs.MemoryMb).Div(decimal.NewFromInt32(1024)).Mul(energyCoefficients.MemoryWhGb)
 }
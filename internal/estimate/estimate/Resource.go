@@ -1,6 +1,7 @@
 package estimate
 
 import (
+	"fmt"
 	"strings"
 
 	"github.com/carboniferio/carbonifer/internal/estimate/coefficients"
@@ -12,46 +13,36 @@ import (
 	"github.com/spf13/viper"
 )
 
-// EstimateSupportedResource gets the carbon emissions of a GCP resource
-func EstimateSupportedResource(resource resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) *estimation.EstimationResource {
+// EstimateSupportedResource gets the carbon emissions of a GCP resource, with the units, usage and data of config
+func EstimateSupportedResource(config *viper.Viper, resource resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) (*estimation.EstimationResource, error) {
+	estimationResource, _, err := ExplainSupportedResource(config, resource, forecastCarbonIntensity, forecastRegion)
+	return estimationResource, err
+}
+
+// ExplainSupportedResource gets the carbon emissions of a resource, with each term of the estimation. The estimation
+// is computed from the terms of the breakdown, so that both always match.
+func ExplainSupportedResource(config *viper.Viper, resource resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) (*estimation.EstimationResource, *estimation.EstimationBreakdown, error) {
 
 	var computeResource resources.ComputeResource = resource.(resources.ComputeResource)
 
 	// Electric power used per unit of time
-	avgWattHour := estimateWattHour(&computeResource) // Watt hour
+	breakdown, err := explainWattHour(config, &computeResource)
+	if err != nil {
+		return nil, nil, err
+	}
+	avgWattHour := breakdown.Power // Watt hour
 	avgKWattHour := avgWattHour.Div(decimal.NewFromInt(1000))
 
 	// Regional grid emission per unit of time
-	// Option 2 logic here:
-	var carbonIntensity decimal.Decimal
-
-	if forecastCarbonIntensity != nil && resource.GetIdentification().Region == forecastRegion {
-		carbonIntensity = *forecastCarbonIntensity
-		log.Infof("Applying forecast carbon intensity %.6f gCO2eq/Wh for resource %s in region %s", carbonIntensity, resource.GetIdentification().Name, resource.GetIdentification().Region)
-	} else {
-		regionEmissions, err := coefficients.RegionEmission(resource.GetIdentification().Provider, resource.GetIdentification().Region) // gCO2eq /kWh
-		if err != nil {
-			log.Fatalf("Error while getting region emissions for %v: %v", resource.GetAddress(), err)
-		}
-		carbonIntensity = regionEmissions.GridCarbonIntensity
-		log.Infof("Using static carbon intensity %.6f gCO2eq/Wh for resource %s in region %s", carbonIntensity, resource.GetIdentification().Name, resource.GetIdentification().Region)
+	breakdown.GridCarbonIntensity, breakdown.GridCarbonIntensitySource, err = getCarbonIntensity(config, resource, forecastCarbonIntensity, forecastRegion)
+	if err != nil {
+		return nil, nil, err
 	}
+	carbonIntensity := breakdown.GridCarbonIntensity
 
 	// Carbon Emissions
 	carbonEmissionInGCO2PerH := avgKWattHour.Mul(carbonIntensity)
-	carbonEmissionPerTime := carbonEmissionInGCO2PerH
-	if strings.ToLower(viper.GetString("unit.time")) == "d" {
-		carbonEmissionPerTime = carbonEmissionPerTime.Mul(decimal.NewFromInt(24))
-	}
-	if strings.ToLower(viper.GetString("unit.time")) == "m" {
-		carbonEmissionPerTime = carbonEmissionPerTime.Mul(decimal.NewFromInt(24 * 30))
-	}
-	if strings.ToLower(viper.GetString("unit.time")) == "y" {
-		carbonEmissionPerTime = carbonEmissionPerTime.Mul(decimal.NewFromInt(24 * 365))
-	}
-	if strings.ToLower(viper.GetString("unit.carbon")) == "kg" {
-		carbonEmissionPerTime = carbonEmissionPerTime.Div(decimal.NewFromInt(1000))
-	}
+	carbonEmissionPerTime := toCarbonEmissionPerTime(config, carbonEmissionInGCO2PerH)
 	carbonEmissionPerTimeStr := carbonEmissionPerTime.String()
 
 	log.Debugf(
@@ -72,9 +63,9 @@ func EstimateSupportedResource(resource resources.Resource, forecastCarbonIntens
 		"h",
 		resource.GetIdentification().Count,
 		carbonEmissionPerTimeStr,
-		viper.GetString("unit.carbon"),
-		viper.GetString("unit.power"),
-		viper.GetString("unit.time"),
+		config.GetString("unit.carbon"),
+		config.GetString("unit.power"),
+		config.GetString("unit.time"),
 		resource.GetIdentification().Count,
 	)
 
@@ -86,11 +77,51 @@ func EstimateSupportedResource(resource resources.Resource, forecastCarbonIntens
 	replicationFactor := int64(computeResource.Identification.ReplicationFactor)
 
 	est := &estimation.EstimationResource{
-		Resource:        &computeResource,
-		Power:           avgWattHour.RoundFloor(10),
-		CarbonEmissions: carbonEmissionPerTime.RoundFloor(10),
-		AverageCPUUsage: decimal.NewFromFloat(viper.GetFloat64("provider.gcp.avg_cpu_use")).RoundFloor(10),
-		TotalCount:      decimal.NewFromInt(count * replicationFactor),
+		Resource:            &computeResource,
+		Power:               avgWattHour.RoundFloor(10),
+		CarbonEmissions:     carbonEmissionPerTime.RoundFloor(10),
+		AverageCPUUsage:     decimal.NewFromFloat(config.GetFloat64("provider.gcp.avg_cpu_use")).RoundFloor(10),
+		TotalCount:          decimal.NewFromInt(count * replicationFactor),
+		GridCarbonIntensity: carbonIntensity,
+		PowerSplit: &estimation.PowerSplit{
+			CPU:     breakdown.CPU,
+			Memory:  breakdown.Memory,
+			Storage: breakdown.Storage,
+			GPU:     breakdown.GPU,
+		},
+	}
+	return est, breakdown, nil
+}
+
+// getCarbonIntensity returns the grid carbon intensity of the region of the resource (gCO2eq/kWh) and its source
+func getCarbonIntensity(config *viper.Viper, resource resources.Resource, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) (decimal.Decimal, string, error) {
+	// Option 2 logic here:
+	if forecastCarbonIntensity != nil && resource.GetIdentification().Region == forecastRegion {
+		log.Infof("Applying forecast carbon intensity %v gCO2eq/Wh for resource %s in region %s", *forecastCarbonIntensity, resource.GetIdentification().Name, resource.GetIdentification().Region)
+		return *forecastCarbonIntensity, fmt.Sprintf("forecast (%v)", config.GetString("carbon_intensity_file")), nil
+	}
+	regionEmissions, err := coefficients.RegionEmission(config.GetString("data.path"), resource.GetIdentification().Provider, resource.GetIdentification().Region) // gCO2eq /kWh
+	if err != nil {
+		return decimal.Zero, "", err
+	}
+	log.Infof("Using static carbon intensity %v gCO2eq/Wh for resource %s in region %s", regionEmissions.GridCarbonIntensity, resource.GetIdentification().Name, resource.GetIdentification().Region)
+	return regionEmissions.GridCarbonIntensity, fmt.Sprintf("static (%v)", coefficients.RegionEmissionsFile(resource.GetIdentification().Provider)), nil
+}
+
+// toCarbonEmissionPerTime converts carbon emissions in gCO2eq per hour to the configured units
+func toCarbonEmissionPerTime(config *viper.Viper, carbonEmissionInGCO2PerH decimal.Decimal) decimal.Decimal {
+	carbonEmissionPerTime := carbonEmissionInGCO2PerH
+	if strings.ToLower(config.GetString("unit.time")) == "d" {
+		carbonEmissionPerTime = carbonEmissionPerTime.Mul(decimal.NewFromInt(24))
+	}
+	if strings.ToLower(config.GetString("unit.time")) == "m" {
+		carbonEmissionPerTime = carbonEmissionPerTime.Mul(decimal.NewFromInt(24 * 30))
+	}
+	if strings.ToLower(config.GetString("unit.time")) == "y" {
+		carbonEmissionPerTime = carbonEmissionPerTime.Mul(decimal.NewFromInt(24 * 365))
+	}
+	if strings.ToLower(config.GetString("unit.carbon")) == "kg" {
+		carbonEmissionPerTime = carbonEmissionPerTime.Div(decimal.NewFromInt(1000))
 	}
-	return est
+	return carbonEmissionPerTime
 }
@@ -11,17 +11,33 @@ import (
 )
 
 // EstimateWattGPU estimates the power consumption of a GPU resource
-func EstimateWattGPU(resource *resources.ComputeResource) decimal.Decimal {
+func EstimateWattGPU(config *viper.Viper, resource *resources.ComputeResource) (decimal.Decimal, error) {
 	// Get average GPU usage
 	provider := strings.ToLower(resource.Identification.Provider.String())
-	averageCPUUse := decimal.NewFromFloat(viper.GetFloat64(fmt.Sprintf("provider.%s.avg_gpu_use", provider)))
+	averageCPUUse := decimal.NewFromFloat(config.GetFloat64(fmt.Sprintf("provider.%s.avg_gpu_use", provider)))
 
 	avgWattsTotal := decimal.Zero
 	// Average Watts = Min Watts + Avg GPU Utilization * (Max Watts - Min Watts)
-	for _, gpuType := range resource.Specs.GpuTypes {
-		gpuWatt := providers.GetGPUWatt(gpuType)
+	gpuWatts, err := getGPUWatts(config, resource)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	for _, gpuWatt := range gpuWatts {
 		avgWatts := gpuWatt.MinWatts.Add(averageCPUUse.Mul(gpuWatt.MaxWatts.Sub(gpuWatt.MinWatts)))
 		avgWattsTotal = avgWattsTotal.Add(avgWatts)
 	}
-	return avgWattsTotal
+	return avgWattsTotal, nil
+}
+
+// getGPUWatts returns the min and max watts of each GPU of a resource
+func getGPUWatts(config *viper.Viper, resource *resources.ComputeResource) ([]providers.GPUWatt, error) {
+	gpuWatts := []providers.GPUWatt{}
+	for _, gpuType := range resource.Specs.GpuTypes {
+		gpuWatt, err := providers.GetGPUWatt(config.GetString("data.path"), gpuType)
+		if err != nil {
+			return nil, err
+		}
+		gpuWatts = append(gpuWatts, gpuWatt)
+	}
+	return gpuWatts, nil
 }
@@ -1,6 +1,7 @@
 package estimate
 
 import (
+	"github.com/spf13/viper"
 	"testing"
 
 	"github.com/carboniferio/carbonifer/internal/providers"
@@ -57,7 +58,8 @@ func Test_estimateWattGPU(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := EstimateWattGPU(tt.args.resource)
+			got, err := EstimateWattGPU(viper.GetViper(), tt.args.resource)
+			assert.NoError(t, err)
 			assert.Equal(t, tt.want, got)
 
 		})
@@ -6,10 +6,9 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-func estimateWattStorage(resource *resources.ComputeResource) decimal.Decimal {
-	provider := resource.Identification.Provider
-	storageSsdWhGb := coefficients.GetEnergyCoefficients().GetByProvider(provider).StorageSsdWhTb.Div(decimal.NewFromInt32(1024))
-	storageHddWhGb := coefficients.GetEnergyCoefficients().GetByProvider(provider).StorageHddWhTb.Div(decimal.NewFromInt32(1024))
+func estimateWattStorage(resource *resources.ComputeResource, energyCoefficients coefficients.Coefficients) decimal.Decimal {
+	storageSsdWhGb := energyCoefficients.StorageSsdWhTb.Div(decimal.NewFromInt32(1024))
+	storageHddWhGb := energyCoefficients.StorageHddWhTb.Div(decimal.NewFromInt32(1024))
 	storageSSDWh := resource.Specs.SsdStorage.Mul(storageSsdWhGb)
 	storageHddWh := resource.Specs.HddStorage.Mul(storageHddWhGb)
 	return storageSSDWh.Add(storageHddWh)
@@ -1,9 +1,11 @@
 package estimate
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
+	"github.com/carboniferio/carbonifer/internal/estimate/coefficients"
 	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
 	"github.com/carboniferio/carbonifer/internal/providers"
 	"github.com/carboniferio/carbonifer/internal/resources"
@@ -126,7 +128,7 @@ func TestEstimateResource(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, _ := EstimateResource(tt.args.resource)
+			got, _ := EstimateResource(viper.GetViper(), tt.args.resource, nil, "")
 			EqualsEstimationResource(t, tt.want, got)
 		})
 	}
@@ -169,7 +171,7 @@ func TestEstimateResourceKilo(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, _ := EstimateResource(tt.args.resource)
+			got, _ := EstimateResource(viper.GetViper(), tt.args.resource, nil, "")
 			EqualsEstimationResource(t, tt.want, got)
 		})
 	}
@@ -192,7 +194,7 @@ func TestEstimateResourceUnsupported(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := EstimateResource(tt.args.resource)
+			_, err := EstimateResource(viper.GetViper(), tt.args.resource, nil, "")
 			//assert.Equal(t, got.Power, tt.want.Power)
 			if !reflect.DeepEqual(err, tt.want) {
 				t.Errorf("EstimateResource() = %v, want %v", err, tt.want)
@@ -281,7 +283,7 @@ func TestEstimateResources(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := EstimateResources(tt.args.resources)
+			got := EstimateResources(viper.GetViper(), tt.args.resources, nil, "")
 			assert.Equal(t, got.Info.UnitCarbonEmissionsTime, tt.want.Info.UnitCarbonEmissionsTime)
 			assert.Equal(t, got.Info.UnitTime, tt.want.Info.UnitTime)
 			assert.Equal(t, got.Info.UnitWattTime, tt.want.Info.UnitWattTime)
@@ -295,3 +297,48 @@ func TestEstimateResources(t *testing.T) {
 		})
 	}
 }
+
+func TestExplainResource(t *testing.T) {
+	explanation, uerr := ExplainResource(viper.GetViper(), resourceGCPComputeCPUType, nil, "")
+	assert.Nil(t, uerr)
+	estimationResource, _ := EstimateResource(viper.GetViper(), resourceGCPComputeCPUType, nil, "")
+	EqualsEstimationResource(t, estimationResource, &explanation.Estimation)
+
+	breakdown := explanation.Breakdown
+	assert.NotNil(t, breakdown.CPUWatt)
+	rawPower := decimal.Sum(breakdown.CPU, breakdown.Memory, breakdown.Storage, breakdown.GPU)
+	assert.Equal(t, rawPower.String(), breakdown.RawPower.String())
+	assert.Equal(t, "static (gcp_co2_region.csv)", breakdown.GridCarbonIntensitySource)
+	// The estimation is the one of the breakdown
+	assert.Equal(t, breakdown.Power.RoundFloor(10).String(), explanation.Estimation.Power.String())
+	assert.Equal(t, breakdown.GridCarbonIntensity.String(), explanation.Estimation.GridCarbonIntensity.String())
+
+	unsupported, uerr := ExplainResource(viper.GetViper(), resourceUnsupportedComputeBasic, nil, "")
+	assert.Nil(t, unsupported)
+	assert.NotNil(t, uerr)
+}
+
+func TestEstimateResources_UnknownRegion(t *testing.T) {
+	unknownRegion := resourceGCPComputeBasic
+	unknownRegion.Identification = &resources.ResourceIdentification{
+		Address:           "google_compute_instance.unknown-region",
+		Name:              "unknown-region",
+		ResourceType:      "type-1",
+		Provider:          providers.GCP,
+		Region:            "mars-north1",
+		ReplicationFactor: 1,
+		Count:             1,
+	}
+
+	got := EstimateResources(viper.GetViper(), map[string]resources.Resource{
+		"type-1.machine-name-1": resourceGCPComputeBasic,
+		"type-1.unknown-region": unknownRegion,
+	}, nil, "")
+
+	assert.Len(t, got.Resources, 1)
+	assert.Len(t, got.Errors, 1)
+	assert.Equal(t, "google_compute_instance.unknown-region", got.Errors[0].Address)
+	var regionErr *coefficients.UnknownRegionError
+	assert.True(t, errors.As(got.Errors[0], &regionErr))
+	assert.Equal(t, "mars-north1", regionErr.Region)
+}
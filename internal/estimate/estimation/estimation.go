@@ -3,7 +3,9 @@ package estimation
 import (
 	"time"
 
+	"github.com/carboniferio/carbonifer/internal/estimate/coefficients"
 	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/providers/gcp"
 	"github.com/carboniferio/carbonifer/internal/resources"
 	"github.com/shopspring/decimal"
 )
@@ -13,16 +15,42 @@ type EstimationReport struct {
 	Info                 EstimationInfo
 	Resources            []EstimationResource
 	UnsupportedResources []resources.Resource
+	Errors               []resources.ResourceError `json:",omitempty"` // resources that could not be read or estimated
+	Unresolved           []UnresolvedAttribute     `json:",omitempty"` // attributes of an offline plan with unknown values
 	Total                EstimationTotal
+	Delta                *EstimationDelta `json:",omitempty"`
+}
+
+// AddErrors adds errors of resources to the report, sorted by resource address
+func (r *EstimationReport) AddErrors(resourceErrors ...resources.ResourceError) {
+	r.Errors = append(r.Errors, resourceErrors...)
+	resources.SortResourceErrors(r.Errors)
+}
+
+// UnresolvedAttribute is an attribute of a resource whose value is unknown in an offline plan (--offline)
+type UnresolvedAttribute struct {
+	Address   string
+	Attribute string
+	Reason    string
 }
 
 // EstimationResource is the struct that contains the estimation of a resource
 type EstimationResource struct {
-	Resource        resources.Resource
-	Power           decimal.Decimal `json:"PowerPerInstance"`
-	CarbonEmissions decimal.Decimal `json:"CarbonEmissionsPerInstance"`
-	AverageCPUUsage decimal.Decimal
-	TotalCount      decimal.Decimal `json:"TotalCount"` // Count * ReplicationFactor
+	Resource            resources.Resource
+	Power               decimal.Decimal `json:"PowerPerInstance"`
+	CarbonEmissions     decimal.Decimal `json:"CarbonEmissionsPerInstance"`
+	AverageCPUUsage     decimal.Decimal
+	TotalCount          decimal.Decimal `json:"TotalCount"` // Count * ReplicationFactor
+	GridCarbonIntensity decimal.Decimal // gCO2eq/kWh of the grid of the region, zero if unsupported
+	PowerSplit          *PowerSplit     `json:",omitempty"` // nil if unsupported
+}
+
+// PowerSplit is the struct that contains the raw power of a resource by component, before PUE and replication
+type PowerSplit struct {
+	CPU     decimal.Decimal // Wh
+	Memory  decimal.Decimal // Wh
+	Storage decimal.Decimal // Wh
+	GPU     decimal.Decimal // Wh
 }
 
 // EstimationTotal is the struct that contains the total estimation
@@ -39,6 +67,15 @@ type EstimationInfo struct {
 	UnitCarbonEmissionsTime string
 	DateTime                time.Time
 	InfoByProvider          map[providers.Provider]InfoByProvider
+	Terraform               *TerraformInfo `json:",omitempty"` // nil if the plan was not generated by carbonifer
+}
+
+// TerraformInfo is the struct that contains the workspace and variables the plan was generated with
+type TerraformInfo struct {
+	Workspace string
+	VarFiles  []string               `json:",omitempty"`
+	Variables map[string]interface{} `json:",omitempty"`
+	Targets   []string               `json:",omitempty"`
 }
 
 // InfoByProvider is the struct that contains the info of the estimation by provider
@@ -46,3 +83,64 @@ type InfoByProvider struct {
 	AverageCPUUsage float64
 	AverageGPUUsage float64
 }
+
+// EstimationDelta is the struct that contains the difference of estimation before and after a change
+type EstimationDelta struct {
+	Resources []EstimationResourceDelta
+	Before    EstimationTotal
+	After     EstimationTotal
+	Total     EstimationTotal // After - Before
+}
+
+// EstimationResourceDelta is the struct that contains the difference of estimation of a resource before and after a change
+type EstimationResourceDelta struct {
+	Address         string
+	Action          string
+	Before          *EstimationResource `json:",omitempty"`
+	After           *EstimationResource `json:",omitempty"`
+	Power           decimal.Decimal     // Difference of total power (all instances)
+	CarbonEmissions decimal.Decimal     // Difference of total carbon emissions (all instances)
+}
+
+// PercentChange returns the change of total carbon emissions in percent of the emissions before the change.
+// It returns false if there were no emissions before.
+func (d EstimationDelta) PercentChange() (decimal.Decimal, bool) {
+	if d.Before.CarbonEmissions.IsZero() {
+		return decimal.Zero, false
+	}
+	return d.Total.CarbonEmissions.Div(d.Before.CarbonEmissions).Mul(decimal.NewFromInt(100)), true
+}
+
+// EstimationDiffReport is the struct that contains the comparison of two estimations (ex: a branch against main)
+type EstimationDiffReport struct {
+	Info          EstimationInfo
+	Delta         EstimationDelta
+	PercentChange *decimal.Decimal `json:",omitempty"` // nil if there were no emissions before
+}
+
+// EstimationBreakdown is the struct that contains each term of the estimation of a resource
+type EstimationBreakdown struct {
+	CPU                       decimal.Decimal // Wh
+	Memory                    decimal.Decimal // Wh
+	Storage                   decimal.Decimal // Wh
+	GPU                       decimal.Decimal // Wh
+	RawPower                  decimal.Decimal // CPU + Memory + Storage + GPU, in Wh
+	PUE                       decimal.Decimal
+	ReplicationFactor         int32
+	Power                     decimal.Decimal // PUE * RawPower * ReplicationFactor, in Wh
+	AverageCPUUsage           decimal.Decimal
+	AverageGPUUsage           decimal.Decimal
+	Coefficients              coefficients.Coefficients
+	CPUWatt                   *gcp.CPUWatt `json:",omitempty"` // CPU platform, if not using Coefficients
+	GPUWatts                  []providers.GPUWatt
+	GridCarbonIntensity       decimal.Decimal // gCO2eq/kWh
+	GridCarbonIntensitySource string
+}
+
+// EstimationExplanation is the struct that contains the full estimation of a resource, with each of its terms
+type EstimationExplanation struct {
+	Info         EstimationInfo
+	Estimation   EstimationResource
+	Breakdown    *EstimationBreakdown `json:",omitempty"` // nil if resource is not supported
+	SpecsSources []resources.PropertySource
+}
@@ -0,0 +1,49 @@
+package estimation
+
+import (
+	"encoding/json"
+
+	"github.com/carboniferio/carbonifer/internal/resources"
+)
+
+// UnmarshalJSON reads back an estimation report generated by output.GenerateReportJSON
+func (r *EstimationReport) UnmarshalJSON(data []byte) error {
+	type reportAlias EstimationReport
+	var report struct {
+		reportAlias
+		UnsupportedResources []resources.UnsupportedResource
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return err
+	}
+	*r = EstimationReport(report.reportAlias)
+	r.UnsupportedResources = []resources.Resource{}
+	for _, resource := range report.UnsupportedResources {
+		r.UnsupportedResources = append(r.UnsupportedResources, resource)
+	}
+	return nil
+}
+
+// UnmarshalJSON reads back an estimated resource, a compute resource if it has specs, unsupported otherwise
+func (r *EstimationResource) UnmarshalJSON(data []byte) error {
+	type resourceAlias EstimationResource
+	var estimationResource struct {
+		resourceAlias
+		Resource json.RawMessage
+	}
+	if err := json.Unmarshal(data, &estimationResource); err != nil {
+		return err
+	}
+	*r = EstimationResource(estimationResource.resourceAlias)
+
+	var computeResource resources.ComputeResource
+	if err := json.Unmarshal(estimationResource.Resource, &computeResource); err != nil {
+		return err
+	}
+	if computeResource.Specs != nil {
+		r.Resource = computeResource
+	} else {
+		r.Resource = resources.UnsupportedResource{Identification: computeResource.Identification}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package estimate
+
+import (
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+)
+
+// EstimatePlan estimates the resources of a terraform plan, read with mappings (nil for the mappings in use) and
+// config. Resources that cannot be read or estimated are in the errors of the report, other errors (invalid plan or
+// mappings...) are returned.
+func EstimatePlan(config *viper.Viper, mappings *plan.Mappings, tfPlan *map[string]interface{}, forecastCarbonIntensity *decimal.Decimal, forecastRegion string) (estimation.EstimationReport, error) {
+	resourceList, err := plan.GetResources(config, mappings, tfPlan)
+	resourceErrors, err := resources.SplitResourceErrors(err)
+	if err != nil {
+		return estimation.EstimationReport{}, errors.Wrap(err, "Failed to get resources from terraform plan")
+	}
+	report := EstimateResources(config, resourceList, forecastCarbonIntensity, forecastRegion)
+	report.AddErrors(resourceErrors...)
+	return report, nil
+}
@@ -0,0 +1,124 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	log "github.com/sirupsen/logrus"
+)
+
+// csvHeader is the header of CSV reports. Columns are only added at the end, so that spreadsheets reading it don't break.
+var csvHeader = []string{
+	"address",
+	"type",
+	"provider",
+	"region",
+	"count",
+	"replication_factor",
+	"vcpus",
+	"memory_mb",
+	"hdd_storage_gb",
+	"ssd_storage_gb",
+	"gpu_types",
+	"power_per_instance",
+	"power_unit",
+	"emissions_per_instance",
+	"total_emissions",
+	"emissions_unit",
+	"grid_carbon_intensity_gco2eq_per_kwh",
+	"status",
+	"error",
+}
+
+// Status of a resource in CSV reports
+const (
+	csvStatusEstimated    = "estimated"
+	csvStatusUnsupported  = "unsupported"
+	csvStatusNotEstimated = "not_estimated"
+)
+
+// GenerateReportCSV generates a CSV report from an estimation report, with one row per resource
+func GenerateReportCSV(report estimation.EstimationReport) string {
+	log.Debug("Generating CSV report")
+	builder := &strings.Builder{}
+	writer := csv.NewWriter(builder)
+
+	rows := [][]string{csvHeader}
+
+	// Default sort
+	estimations := report.Resources
+	estimate.SortEstimations(&estimations)
+	for _, estimationResource := range estimations {
+		row := csvIdentificationColumns(estimationResource.Resource)
+		row = append(row, csvSpecsColumns(estimationResource.Resource)...)
+		row = append(row,
+			estimationResource.Power.StringFixed(4),
+			report.Info.UnitWattTime,
+			estimationResource.CarbonEmissions.StringFixed(4),
+			estimationResource.CarbonEmissions.Mul(estimationResource.TotalCount).StringFixed(4),
+			report.Info.UnitCarbonEmissionsTime,
+			estimationResource.GridCarbonIntensity.String(),
+			csvStatusEstimated,
+			"",
+		)
+		rows = append(rows, row)
+	}
+
+	for _, resource := range report.UnsupportedResources {
+		row := csvIdentificationColumns(resource)
+		row = append(row, csvSpecsColumns(resource)...)
+		row = append(row, "", "", "", "", "", "", csvStatusUnsupported, "")
+		rows = append(rows, row)
+	}
+
+	for _, resourceError := range report.Errors {
+		row := make([]string, len(csvHeader))
+		row[0] = resourceError.Address
+		row[len(row)-2] = csvStatusNotEstimated
+		row[len(row)-1] = resourceError.Err.Error()
+		rows = append(rows, row)
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		log.Fatal(err)
+	}
+	return builder.String()
+}
+
+// csvIdentificationColumns returns the address, type, provider, region, count and replication factor columns
+func csvIdentificationColumns(resource resources.Resource) []string {
+	identification := resource.GetIdentification()
+	return []string{
+		identification.Address,
+		identification.ResourceType,
+		identification.Provider.String(),
+		identification.Region,
+		fmt.Sprintf("%v", identification.Count),
+		fmt.Sprintf("%v", identification.ReplicationFactor),
+	}
+}
+
+// csvSpecsColumns returns the vCPUs, memory, storage and GPU columns, empty if the resource has no specs
+func csvSpecsColumns(resource resources.Resource) []string {
+	var specs *resources.ComputeResourceSpecs
+	switch computeResource := resource.(type) {
+	case resources.ComputeResource:
+		specs = computeResource.Specs
+	case *resources.ComputeResource:
+		specs = computeResource.Specs
+	}
+	if specs == nil {
+		return []string{"", "", "", "", ""}
+	}
+	return []string{
+		fmt.Sprintf("%v", specs.VCPUs),
+		fmt.Sprintf("%v", specs.MemoryMb),
+		specs.HddStorage.String(),
+		specs.SsdStorage.String(),
+		strings.Join(specs.GpuTypes, ";"),
+	}
+}
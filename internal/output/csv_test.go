@@ -0,0 +1,74 @@
+package output
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
+)
+
+func TestGenerateReportCSV(t *testing.T) {
+	computeResource := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:           "google_compute_instance.first",
+			Name:              "first",
+			ResourceType:      "google_compute_instance",
+			Provider:          providers.GCP,
+			Region:            "europe-west9",
+			Count:             2,
+			ReplicationFactor: 1,
+		},
+		Specs: &resources.ComputeResourceSpecs{
+			VCPUs:      2,
+			MemoryMb:   4096,
+			HddStorage: decimal.Zero,
+			SsdStorage: decimal.NewFromInt(10),
+			GpuTypes:   []string{"nvidia-tesla-t4", "nvidia-tesla-t4"},
+		},
+	}
+	unsupportedResource := resources.UnsupportedResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_compute_network.vpc",
+			Name:         "vpc",
+			ResourceType: "google_compute_network",
+			Provider:     providers.GCP,
+			Count:        1,
+		},
+	}
+	report := estimation.EstimationReport{
+		Info: estimation.EstimationInfo{
+			UnitTime:                "h",
+			UnitWattTime:            "Wh",
+			UnitCarbonEmissionsTime: "gCO2eq/h",
+		},
+		Resources: []estimation.EstimationResource{
+			{
+				Resource:            &computeResource,
+				Power:               decimal.NewFromFloat(7.6),
+				CarbonEmissions:     decimal.NewFromFloat(0.44),
+				TotalCount:          decimal.NewFromInt(2),
+				GridCarbonIntensity: decimal.NewFromInt(59),
+			},
+		},
+		UnsupportedResources: []resources.Resource{unsupportedResource},
+		Errors: []resources.ResourceError{
+			{Address: "google_compute_instance.broken", Err: errors.New("Unknown unit for memory: xb")},
+		},
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(GenerateReportCSV(report))).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"address", "type", "provider", "region", "count", "replication_factor", "vcpus", "memory_mb", "hdd_storage_gb", "ssd_storage_gb", "gpu_types", "power_per_instance", "power_unit", "emissions_per_instance", "total_emissions", "emissions_unit", "grid_carbon_intensity_gco2eq_per_kwh", "status", "error"},
+		{"google_compute_instance.first", "google_compute_instance", "GCP", "europe-west9", "2", "1", "2", "4096", "0", "10", "nvidia-tesla-t4;nvidia-tesla-t4", "7.6000", "Wh", "0.4400", "0.8800", "gCO2eq/h", "59", "estimated", ""},
+		{"google_compute_network.vpc", "google_compute_network", "GCP", "", "1", "0", "", "", "", "", "", "", "", "", "", "", "", "unsupported", ""},
+		{"google_compute_instance.broken", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "", "not_estimated", "Unknown unit for memory: xb"},
+	}, rows)
+}
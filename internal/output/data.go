@@ -0,0 +1,63 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/data"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxListedMissing is the number of missing keys listed per file in text reports
+const maxListedMissing = 10
+
+// GenerateDataValidationJSON generates a JSON report from the validation of a data path
+func GenerateDataValidationJSON(report data.ValidationReport) string {
+	log.Debug("Generating JSON data validation report")
+
+	reportTextBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(reportTextBytes)
+}
+
+// GenerateDataValidationText generates a text report from the validation of a data path
+func GenerateDataValidationText(report data.ValidationReport) string {
+	log.Debug("Generating text data validation report")
+	builder := &strings.Builder{}
+
+	builder.WriteString(fmt.Sprintf("\n  Data files of %v:\n\n", report.DataPath))
+	table := newReportTable(builder, []string{"file", "source", "status", "details"})
+	for _, file := range report.Files {
+		source := "embedded"
+		if file.Override {
+			source = "custom"
+		}
+		switch {
+		case len(file.Errors) > 0:
+			table.Append([]string{file.File, source, "invalid", file.Errors[0]})
+			for _, err := range file.Errors[1:] {
+				table.Append([]string{"", "", "", err})
+			}
+		case len(file.Missing) > 0:
+			table.Append([]string{file.File, source, "valid", fmt.Sprintf("%v missing: %v", len(file.Missing), listMissing(file.Missing))})
+		default:
+			table.Append([]string{file.File, source, "valid", ""})
+		}
+	}
+	table.Render()
+
+	if len(report.UnknownFiles) > 0 {
+		builder.WriteString(fmt.Sprintf("\n  Ignored files (not data files): %v\n", strings.Join(report.UnknownFiles, ", ")))
+	}
+	return builder.String()
+}
+
+func listMissing(missing []string) string {
+	if len(missing) <= maxListedMissing {
+		return strings.Join(missing, ", ")
+	}
+	return fmt.Sprintf("%v... (+%v)", strings.Join(missing[:maxListedMissing], ", "), len(missing)-maxListedMissing)
+}
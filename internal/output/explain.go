@@ -0,0 +1,104 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/olekukonko/tablewriter"
+	log "github.com/sirupsen/logrus"
+)
+
+// GenerateExplanationJSON generates a JSON report from the explanation of a resource estimation
+func GenerateExplanationJSON(explanation estimation.EstimationExplanation) string {
+	log.Debug("Generating JSON explanation")
+
+	reportTextBytes, err := json.MarshalIndent(explanation, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(reportTextBytes)
+}
+
+// GenerateExplanationText generates a text report from the explanation of a resource estimation
+func GenerateExplanationText(explanation estimation.EstimationExplanation) string {
+	log.Debug("Generating text explanation")
+	builder := &strings.Builder{}
+	resource := explanation.Estimation.Resource
+	identification := resource.GetIdentification()
+	unitWatt := "Wh"
+	unit := explanation.Info.UnitCarbonEmissionsTime
+
+	builder.WriteString(fmt.Sprintf("\n  Estimation of %v\n\n", resource.GetAddress()))
+	builder.WriteString(fmt.Sprintf("  Provider: %v, region: %v, type: %v\n", identification.Provider, identification.Region, identification.ResourceType))
+
+	if explanation.Breakdown == nil {
+		builder.WriteString("\n  Resource is not supported, emissions are not estimated.\n")
+		writeSpecsSources(builder, explanation)
+		return builder.String()
+	}
+	breakdown := explanation.Breakdown
+
+	builder.WriteString("\n  Power per instance:\n\n")
+	table := newReportTable(builder, []string{"term", "value", "details"})
+	cpuDetails := fmt.Sprintf("avg CPU use %v, min %v Wh, max %v Wh per vCPU", breakdown.AverageCPUUsage, breakdown.Coefficients.CPUMinWh, breakdown.Coefficients.CPUMaxWh)
+	if breakdown.CPUWatt != nil {
+		cpuDetails = fmt.Sprintf("avg CPU use %v, %v: min %v Wh, max %v Wh per vCPU", breakdown.AverageCPUUsage, breakdown.CPUWatt.Architecture, breakdown.CPUWatt.MinWatts, breakdown.CPUWatt.MaxWatts)
+	}
+	gpuNames := []string{}
+	for _, gpuWatt := range breakdown.GPUWatts {
+		gpuNames = append(gpuNames, fmt.Sprintf("%v: min %v Wh, max %v Wh", gpuWatt.Name, gpuWatt.MinWatts, gpuWatt.MaxWatts))
+	}
+	gpuDetails := ""
+	if len(gpuNames) > 0 {
+		gpuDetails = fmt.Sprintf("avg GPU use %v, %v", breakdown.AverageGPUUsage, strings.Join(gpuNames, ", "))
+	}
+	table.Append([]string{"CPU", fmt.Sprintf(" %v %v", breakdown.CPU.StringFixed(4), unitWatt), cpuDetails})
+	table.Append([]string{"Memory", fmt.Sprintf(" %v %v", breakdown.Memory.StringFixed(4), unitWatt), fmt.Sprintf("%v Wh per GB", breakdown.Coefficients.MemoryWhGb)})
+	table.Append([]string{"Storage", fmt.Sprintf(" %v %v", breakdown.Storage.StringFixed(4), unitWatt), fmt.Sprintf("SSD %v Wh per TB, HDD %v Wh per TB", breakdown.Coefficients.StorageSsdWhTb, breakdown.Coefficients.StorageHddWhTb)})
+	table.Append([]string{"GPU", fmt.Sprintf(" %v %v", breakdown.GPU.StringFixed(4), unitWatt), gpuDetails})
+	table.Append([]string{"Subtotal", fmt.Sprintf(" %v %v", breakdown.RawPower.StringFixed(4), unitWatt), ""})
+	table.Append([]string{"PUE", fmt.Sprintf(" x %v", breakdown.PUE), "power usage effectiveness of the provider"})
+	table.Append([]string{"Replication", fmt.Sprintf(" x %v", breakdown.ReplicationFactor), ""})
+	table.SetFooter([]string{"Power", fmt.Sprintf(" %v %v", breakdown.Power.StringFixed(4), unitWatt), ""})
+	table.Render()
+
+	builder.WriteString("\n  Emissions:\n\n")
+	table = newReportTable(builder, []string{"term", "value", "details"})
+	table.Append([]string{"Grid carbon intensity", fmt.Sprintf(" %v gCO2eq/kWh", breakdown.GridCarbonIntensity), breakdown.GridCarbonIntensitySource})
+	table.Append([]string{"Emissions per instance", fmt.Sprintf(" %v %v", explanation.Estimation.CarbonEmissions.StringFixed(4), unit), "power x grid carbon intensity"})
+	table.Append([]string{"Count", fmt.Sprintf(" x %v", explanation.Estimation.TotalCount), ""})
+	table.SetFooter([]string{"Total", fmt.Sprintf(" %v %v", explanation.Estimation.CarbonEmissions.Mul(explanation.Estimation.TotalCount).StringFixed(4), unit), ""})
+	table.Render()
+
+	writeSpecsSources(builder, explanation)
+	return builder.String()
+}
+
+// writeSpecsSources lists where each property of the resource comes from
+func writeSpecsSources(builder *strings.Builder, explanation estimation.EstimationExplanation) {
+	if len(explanation.SpecsSources) == 0 {
+		return
+	}
+	builder.WriteString("\n  Specs sources:\n\n")
+	table := newReportTable(builder, []string{"property", "value", "path", "reference"})
+	for _, source := range explanation.SpecsSources {
+		table.Append([]string{source.Property, source.Value, source.Path, source.Reference})
+	}
+	table.Render()
+}
+
+func newReportTable(builder *strings.Builder, header []string) *tablewriter.Table {
+	table := tablewriter.NewWriter(builder)
+	table.SetHeader(header)
+	table.SetAutoFormatHeaders(false)
+	table.SetAutoWrapText(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetFooterAlignment(tablewriter.ALIGN_LEFT)
+	table.SetBorder(true)
+	table.SetColumnSeparator(" ")
+	table.SetCenterSeparator(" ")
+	return table
+}
@@ -0,0 +1,177 @@
+package output
+
+import (
+	_ "embed"
+	"html/template"
+	"sort"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed templates/report.html.tmpl
+var htmlReportTemplate string
+
+// htmlTemplate is the template of HTML reports. All styles are inlined, so that the report can be opened offline.
+var htmlTemplate = template.Must(template.New("report").Parse(htmlReportTemplate))
+
+// htmlReport is the data of the HTML report template
+type htmlReport struct {
+	Title                   string
+	DateTime                string
+	UnitCarbonEmissionsTime string
+	UnitWattTime            string
+	TotalCarbonEmissions    string
+	TotalPower              string
+	ResourcesCount          string
+	Resources               []htmlBar
+	ByProvider              []htmlBar
+	ByRegion                []htmlBar
+	ByType                  []htmlBar
+	ByComponent             []htmlBar
+	Unsupported             []htmlUnsupported
+	Errors                  []htmlError
+}
+
+// htmlBar is a bar of a chart of the HTML report
+type htmlBar struct {
+	Label           string
+	CarbonEmissions string
+	Share           string // percent of the total of the chart
+	Width           string // percent of the largest bar of the chart
+}
+
+// htmlUnsupported is an unsupported resource of the HTML report
+type htmlUnsupported struct {
+	Address      string
+	ResourceType string
+	Provider     string
+}
+
+// htmlError is a resource that could not be estimated, in the HTML report
+type htmlError struct {
+	Address string
+	Error   string
+}
+
+// GenerateReportHTML generates a self-contained HTML report from an estimation report, with charts of emissions
+func GenerateReportHTML(report estimation.EstimationReport, isForecast bool) string {
+	log.Debug("Generating HTML report")
+	data := htmlReport{
+		Title:                   "Carbonifer: estimation of CO2 emissions",
+		DateTime:                report.Info.DateTime.Format("2006-01-02 15:04:05 MST"),
+		UnitCarbonEmissionsTime: report.Info.UnitCarbonEmissionsTime,
+		UnitWattTime:            report.Info.UnitWattTime,
+		TotalCarbonEmissions:    report.Total.CarbonEmissions.StringFixed(4),
+		TotalPower:              report.Total.Power.StringFixed(4),
+		ResourcesCount:          report.Total.ResourcesCount.String(),
+	}
+	if isForecast {
+		data.Title = "Carbonifer: forecast of CO2 emissions"
+	}
+
+	byResource := map[string]decimal.Decimal{}
+	byProvider := map[string]decimal.Decimal{}
+	byRegion := map[string]decimal.Decimal{}
+	byType := map[string]decimal.Decimal{}
+	byComponent := map[string]decimal.Decimal{}
+	for _, estimationResource := range report.Resources {
+		identification := estimationResource.Resource.GetIdentification()
+		emissions := estimationResource.CarbonEmissions.Mul(estimationResource.TotalCount)
+		addEmissions(byResource, estimationResource.Resource.GetAddress(), emissions)
+		addEmissions(byProvider, identification.Provider.String(), emissions)
+		addEmissions(byRegion, identification.Region, emissions)
+		addEmissions(byType, identification.ResourceType, emissions)
+
+		// Emissions are proportional to the raw power, so they are split as the power of each component
+		split := estimationResource.PowerSplit
+		if split == nil {
+			continue
+		}
+		rawPower := decimal.Sum(split.CPU, split.Memory, split.Storage, split.GPU)
+		if rawPower.IsZero() {
+			continue
+		}
+		addEmissions(byComponent, "CPU", emissions.Mul(split.CPU).Div(rawPower))
+		addEmissions(byComponent, "Memory", emissions.Mul(split.Memory).Div(rawPower))
+		addEmissions(byComponent, "Storage", emissions.Mul(split.Storage).Div(rawPower))
+		addEmissions(byComponent, "GPU", emissions.Mul(split.GPU).Div(rawPower))
+	}
+	data.Resources = htmlBars(byResource)
+	data.ByProvider = htmlBars(byProvider)
+	data.ByRegion = htmlBars(byRegion)
+	data.ByType = htmlBars(byType)
+	data.ByComponent = htmlBars(byComponent)
+
+	for _, resource := range report.UnsupportedResources {
+		identification := resource.GetIdentification()
+		data.Unsupported = append(data.Unsupported, htmlUnsupported{
+			Address:      identification.Address,
+			ResourceType: identification.ResourceType,
+			Provider:     identification.Provider.String(),
+		})
+	}
+	for _, resourceError := range report.Errors {
+		data.Errors = append(data.Errors, htmlError{
+			Address: resourceError.Address,
+			Error:   resourceError.Err.Error(),
+		})
+	}
+
+	builder := &strings.Builder{}
+	if err := htmlTemplate.Execute(builder, data); err != nil {
+		log.Fatal(err)
+	}
+	return builder.String()
+}
+
+// addEmissions adds emissions to the emissions of a label
+func addEmissions(emissionsByLabel map[string]decimal.Decimal, label string, emissions decimal.Decimal) {
+	if label == "" {
+		label = "unknown"
+	}
+	emissionsByLabel[label] = emissionsByLabel[label].Add(emissions)
+}
+
+// htmlBars returns the bars of a chart of emissions by label, sorted by decreasing emissions then by label
+func htmlBars(emissionsByLabel map[string]decimal.Decimal) []htmlBar {
+	labels := make([]string, 0, len(emissionsByLabel))
+	total := decimal.Zero
+	largest := decimal.Zero
+	for label, emissions := range emissionsByLabel {
+		labels = append(labels, label)
+		total = total.Add(emissions)
+		if emissions.GreaterThan(largest) {
+			largest = emissions
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		emissionsI, emissionsJ := emissionsByLabel[labels[i]], emissionsByLabel[labels[j]]
+		if !emissionsI.Equal(emissionsJ) {
+			return emissionsI.GreaterThan(emissionsJ)
+		}
+		return labels[i] < labels[j]
+	})
+
+	hundred := decimal.NewFromInt(100)
+	bars := make([]htmlBar, 0, len(labels))
+	for _, label := range labels {
+		emissions := emissionsByLabel[label]
+		bar := htmlBar{
+			Label:           label,
+			CarbonEmissions: emissions.StringFixed(4),
+			Share:           "0.0",
+			Width:           "0.0",
+		}
+		if !total.IsZero() {
+			bar.Share = emissions.Div(total).Mul(hundred).StringFixed(1)
+		}
+		if !largest.IsZero() {
+			bar.Width = emissions.Div(largest).Mul(hundred).StringFixed(1)
+		}
+		bars = append(bars, bar)
+	}
+	return bars
+}
@@ -0,0 +1,107 @@
+package output
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
+)
+
+func TestGenerateReportHTML(t *testing.T) {
+	first := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_compute_instance.first",
+			ResourceType: "google_compute_instance",
+			Provider:     providers.GCP,
+			Region:       "europe-west9",
+			Count:        1,
+		},
+	}
+	second := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_sql_database_instance.<db>",
+			ResourceType: "google_sql_database_instance",
+			Provider:     providers.GCP,
+			Region:       "us-central1",
+			Count:        1,
+		},
+	}
+	report := estimation.EstimationReport{
+		Info: estimation.EstimationInfo{
+			UnitTime:                "h",
+			UnitWattTime:            "Wh",
+			UnitCarbonEmissionsTime: "gCO2eq/h",
+		},
+		Resources: []estimation.EstimationResource{
+			{
+				Resource:        &first,
+				Power:           decimal.NewFromInt(10),
+				CarbonEmissions: decimal.NewFromInt(1),
+				TotalCount:      decimal.NewFromInt(3),
+				PowerSplit: &estimation.PowerSplit{
+					CPU:    decimal.NewFromInt(6),
+					Memory: decimal.NewFromInt(2),
+				},
+			},
+			{
+				Resource:        &second,
+				Power:           decimal.NewFromInt(10),
+				CarbonEmissions: decimal.NewFromInt(1),
+				TotalCount:      decimal.NewFromInt(1),
+				PowerSplit: &estimation.PowerSplit{
+					Storage: decimal.NewFromInt(1),
+				},
+			},
+		},
+		UnsupportedResources: []resources.Resource{
+			resources.UnsupportedResource{
+				Identification: &resources.ResourceIdentification{
+					Address:      "google_compute_network.vpc",
+					ResourceType: "google_compute_network",
+					Provider:     providers.GCP,
+				},
+			},
+		},
+		Errors: []resources.ResourceError{
+			{Address: "google_compute_instance.broken", Err: errors.New("Unknown unit for memory: xb")},
+		},
+		Total: estimation.EstimationTotal{
+			Power:           decimal.NewFromInt(40),
+			CarbonEmissions: decimal.NewFromInt(4),
+			ResourcesCount:  decimal.NewFromInt(4),
+		},
+	}
+
+	got := GenerateReportHTML(report, false)
+
+	assert.Contains(t, got, "<title>Carbonifer: estimation of CO2 emissions</title>")
+	assert.Contains(t, got, `<div class="number">4.0000</div><div class="unit">gCO2eq/h</div>`)
+	assert.NotContains(t, got, "<script")
+	assert.NotContains(t, got, "http")
+	// Addresses are escaped
+	assert.Contains(t, got, "google_sql_database_instance.&lt;db&gt;")
+	assert.Contains(t, got, "<code>google_compute_network.vpc</code>")
+	assert.Contains(t, got, "Unknown unit for memory: xb")
+
+	// Largest bar first, the others relative to it
+	assert.Regexp(t, `(?s)<th scope="row">google_compute_instance.first</th>\s*<td class="bar"><div style="width: 100.0%"></div></td>\s*<td class="value">3.0000</td>\s*<td class="share">75.0%</td>`, got)
+	assert.Regexp(t, `(?s)<th scope="row">europe-west9</th>\s*<td class="bar"><div style="width: 100.0%"></div></td>\s*<td class="value">3.0000</td>`, got)
+	assert.Regexp(t, `(?s)<th scope="row">us-central1</th>\s*<td class="bar"><div style="width: 33.3%"></div></td>\s*<td class="value">1.0000</td>\s*<td class="share">25.0%</td>`, got)
+	assert.Regexp(t, `(?s)<th scope="row">GCP</th>\s*<td class="bar"><div style="width: 100.0%"></div></td>\s*<td class="value">4.0000</td>\s*<td class="share">100.0%</td>`, got)
+
+	// Emissions of a resource are split as the power of its components
+	assert.Regexp(t, `(?s)<th scope="row">CPU</th>\s*<td class="bar"><div style="width: 100.0%"></div></td>\s*<td class="value">2.2500</td>`, got)
+	assert.Regexp(t, `(?s)<th scope="row">Storage</th>\s*<td class="bar"><div style="width: 44.4%"></div></td>\s*<td class="value">1.0000</td>`, got)
+	assert.Regexp(t, `(?s)<th scope="row">Memory</th>\s*<td class="bar"><div style="width: 33.3%"></div></td>\s*<td class="value">0.7500</td>`, got)
+}
+
+func TestGenerateReportHTMLForecast(t *testing.T) {
+	got := GenerateReportHTML(estimation.EstimationReport{}, true)
+	assert.Contains(t, got, "<title>Carbonifer: forecast of CO2 emissions</title>")
+	assert.Contains(t, got, `<td class="empty">No emissions</td>`)
+}
@@ -17,3 +17,14 @@ func GenerateReportJSON(estimations estimation.EstimationReport) string {
 	}
 	return string(reportTextBytes)
 }
+
+// GenerateDiffJSON generates a JSON report from the comparison of two estimations
+func GenerateDiffJSON(diff estimation.EstimationDiffReport) string {
+	log.Debug("Generating JSON diff report")
+
+	reportTextBytes, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(reportTextBytes)
+}
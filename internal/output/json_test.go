@@ -1,6 +1,8 @@
 package output
 
 import (
+	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path"
@@ -14,6 +16,8 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
 	"github.com/carboniferio/carbonifer/internal/testutils"
 	_ "github.com/carboniferio/carbonifer/internal/testutils"
 )
@@ -39,7 +43,7 @@ func TestGenerateReportJson_Empty(t *testing.T) {
 	}
 
 	want := loadOutput("nothing.txt")
-	got := GenerateReportText(estimations)
+	got := GenerateReportText(estimations, false)
 
 	assert.Equal(t, strings.TrimSpace(want), strings.TrimSpace(got))
 }
@@ -58,3 +62,60 @@ func loadOutput(name string) string {
 	return string(content)
 
 }
+
+func TestGenerateReportJson_ReadBack(t *testing.T) {
+	computeResource := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_compute_instance.first",
+			Name:         "first",
+			ResourceType: "google_compute_instance",
+			Provider:     providers.GCP,
+			Region:       "europe-west9",
+			Count:        1,
+		},
+		Specs: &resources.ComputeResourceSpecs{
+			VCPUs:      2,
+			MemoryMb:   4096,
+			HddStorage: decimal.Zero,
+			SsdStorage: decimal.NewFromInt(10),
+		},
+	}
+	unsupportedResource := resources.UnsupportedResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_compute_network.vpc",
+			Name:         "vpc",
+			ResourceType: "google_compute_network",
+			Provider:     providers.GCP,
+			Count:        1,
+		},
+	}
+	estimations := estimation.EstimationReport{
+		Resources: []estimation.EstimationResource{
+			{
+				Resource:        computeResource,
+				Power:           decimal.NewFromFloat(7.6),
+				CarbonEmissions: decimal.NewFromFloat(0.44),
+				AverageCPUUsage: decimal.NewFromFloat(0.5),
+				TotalCount:      decimal.NewFromInt(1),
+			},
+		},
+		UnsupportedResources: []resources.Resource{unsupportedResource},
+		Errors: []resources.ResourceError{
+			{Address: "google_compute_instance.broken", Err: errors.New("Unknown unit for memory: xb")},
+		},
+	}
+
+	var got estimation.EstimationReport
+	err := json.Unmarshal([]byte(GenerateReportJSON(estimations)), &got)
+	assert.NoError(t, err)
+	gotResource, ok := got.Resources[0].Resource.(resources.ComputeResource)
+	assert.True(t, ok)
+	assert.Equal(t, computeResource.Identification, gotResource.Identification)
+	assert.Equal(t, computeResource.Specs.VCPUs, gotResource.Specs.VCPUs)
+	assert.Equal(t, computeResource.Specs.SsdStorage.String(), gotResource.Specs.SsdStorage.String())
+	assert.Equal(t, "0.44", got.Resources[0].CarbonEmissions.String())
+	assert.Equal(t, []resources.Resource{unsupportedResource}, got.UnsupportedResources)
+	assert.Len(t, got.Errors, 1)
+	assert.Equal(t, "google_compute_instance.broken", got.Errors[0].Address)
+	assert.EqualError(t, got.Errors[0].Err, "Unknown unit for memory: xb")
+}
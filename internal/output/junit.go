@@ -0,0 +1,184 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/budget"
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	log "github.com/sirupsen/logrus"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Errors     int             `xml:"errors,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+}
+
+// GenerateReportJUnit generates a JUnit report from an estimation report, with a test case per resource and per
+// budget. Resources fail if they breach an address budget, or if they are unsupported in strict mode.
+func GenerateReportJUnit(report estimation.EstimationReport, budgets budget.Budgets, violations []budget.Violation, strict bool) string {
+	log.Debug("Generating JUnit report")
+	unit := report.Info.UnitCarbonEmissionsTime
+	suite := junitTestSuite{
+		Name: "carbonifer",
+		Properties: []junitProperty{
+			{Name: "total.carbon_emissions", Value: report.Total.CarbonEmissions.StringFixed(4)},
+			{Name: "total.power", Value: report.Total.Power.StringFixed(4)},
+			{Name: "total.resources_count", Value: report.Total.ResourcesCount.String()},
+			{Name: "unit.carbon_emissions", Value: unit},
+			{Name: "unit.power", Value: report.Info.UnitWattTime},
+		},
+	}
+	if !report.Info.DateTime.IsZero() {
+		suite.Timestamp = report.Info.DateTime.Format("2006-01-02T15:04:05")
+	}
+
+	violationsByKind := map[string]map[string][]budget.Violation{}
+	for _, violation := range violations {
+		if violationsByKind[violation.Kind] == nil {
+			violationsByKind[violation.Kind] = map[string][]budget.Violation{}
+		}
+		violationsByKind[violation.Kind][violation.Target] = append(violationsByKind[violation.Kind][violation.Target], violation)
+	}
+
+	// Default sort
+	estimations := report.Resources
+	estimate.SortEstimations(&estimations)
+	for _, estimationResource := range estimations {
+		address := estimationResource.Resource.GetAddress()
+		testCase := junitTestCase{
+			ClassName: estimationResource.Resource.GetIdentification().ResourceType,
+			Name:      address,
+			SystemOut: fmt.Sprintf("%v %v per instance, %v instances, %v %v in total",
+				estimationResource.CarbonEmissions.StringFixed(4), unit,
+				estimationResource.TotalCount,
+				estimationResource.CarbonEmissions.Mul(estimationResource.TotalCount).StringFixed(4), unit,
+			),
+		}
+		testCase.Failure = junitBudgetFailure(violationsByKind[budget.KindAddress][address])
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	for _, resource := range report.UnsupportedResources {
+		testCase := junitTestCase{
+			ClassName: resource.GetIdentification().ResourceType,
+			Name:      resource.GetAddress(),
+		}
+		message := &junitMessage{Message: fmt.Sprintf("resource type %v is not supported", resource.GetIdentification().ResourceType)}
+		if strict {
+			message.Type = "unsupported"
+			testCase.Failure = message
+		} else {
+			testCase.Skipped = message
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	for _, resourceError := range report.Errors {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			ClassName: "not_estimated",
+			Name:      resourceError.Address,
+			Error:     &junitMessage{Message: resourceError.Err.Error()},
+		})
+	}
+
+	totalCase := junitTestCase{
+		ClassName: "budget",
+		Name:      budget.KindTotal,
+		SystemOut: fmt.Sprintf("%v %v", report.Total.CarbonEmissions.StringFixed(4), unit),
+	}
+	if budgets.Total == nil {
+		totalCase.Skipped = &junitMessage{Message: "no total budget configured"}
+	} else {
+		totalCase.Failure = junitBudgetFailure(violationsByKind[budget.KindTotal][""])
+	}
+	suite.Cases = append(suite.Cases, totalCase)
+	for _, resourceTypeBudget := range budgets.ResourceTypes {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			ClassName: "budget",
+			Name:      fmt.Sprintf("%v %v", budget.KindResourceType, resourceTypeBudget.Type),
+			Failure:   junitBudgetFailure(violationsByKind[budget.KindResourceType][resourceTypeBudget.Type]),
+		})
+	}
+	for _, moduleBudget := range budgets.Modules {
+		suite.Cases = append(suite.Cases, junitTestCase{
+			ClassName: "budget",
+			Name:      fmt.Sprintf("%v %v", budget.KindModule, moduleBudget.Module),
+			Failure:   junitBudgetFailure(violationsByKind[budget.KindModule][moduleBudget.Module]),
+		})
+	}
+
+	for _, testCase := range suite.Cases {
+		suite.Tests++
+		switch {
+		case testCase.Failure != nil:
+			suite.Failures++
+		case testCase.Error != nil:
+			suite.Errors++
+		case testCase.Skipped != nil:
+			suite.Skipped++
+		}
+	}
+
+	junitXML, err := xml.MarshalIndent(junitTestSuites{
+		Name:     suite.Name,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Skipped:  suite.Skipped,
+		Suites:   []junitTestSuite{suite},
+	}, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return xml.Header + string(junitXML)
+}
+
+// junitBudgetFailure returns the failure of the budgets breached by a test case, nil if none
+func junitBudgetFailure(violations []budget.Violation) *junitMessage {
+	if len(violations) == 0 {
+		return nil
+	}
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = violation.String()
+	}
+	return &junitMessage{Message: strings.Join(messages, "; "), Type: "budget"}
+}
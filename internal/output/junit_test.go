@@ -0,0 +1,135 @@
+package output
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carboniferio/carbonifer/internal/budget"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
+)
+
+func junitTestReport() estimation.EstimationReport {
+	web := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_compute_instance.web",
+			ResourceType: "google_compute_instance",
+			Provider:     providers.GCP,
+			Count:        2,
+		},
+	}
+	db := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_sql_database_instance.db",
+			ResourceType: "google_sql_database_instance",
+			Provider:     providers.GCP,
+			Count:        1,
+		},
+	}
+	return estimation.EstimationReport{
+		Info: estimation.EstimationInfo{UnitCarbonEmissionsTime: "gCO2eq/h", UnitWattTime: "Wh"},
+		Resources: []estimation.EstimationResource{
+			{Resource: &web, CarbonEmissions: decimal.NewFromInt(3), TotalCount: decimal.NewFromInt(2)},
+			{Resource: &db, CarbonEmissions: decimal.NewFromInt(1), TotalCount: decimal.NewFromInt(1)},
+		},
+		UnsupportedResources: []resources.Resource{
+			resources.UnsupportedResource{
+				Identification: &resources.ResourceIdentification{
+					Address:      "google_compute_network.vpc",
+					ResourceType: "google_compute_network",
+					Provider:     providers.GCP,
+				},
+			},
+		},
+		Errors: []resources.ResourceError{
+			{Address: "google_compute_instance.broken", Err: errors.New("Unknown unit for memory: xb")},
+		},
+		Total: estimation.EstimationTotal{
+			Power:           decimal.NewFromInt(100),
+			CarbonEmissions: decimal.NewFromInt(7),
+			ResourcesCount:  decimal.NewFromInt(3),
+		},
+	}
+}
+
+func TestGenerateReportJUnit(t *testing.T) {
+	report := junitTestReport()
+	total := 5.0
+	budgets := budget.Budgets{
+		Total:     &total,
+		Modules:   []budget.ModuleBudget{{Module: "module.backend", Max: 1}},
+		Addresses: []budget.AddressBudget{{Pattern: "google_compute_instance.*", Max: 4}},
+	}
+	violations, err := budgets.Check(report)
+	assert.NoError(t, err)
+
+	var suites junitTestSuites
+	err = xml.Unmarshal([]byte(GenerateReportJUnit(report, budgets, violations, true)), &suites)
+	assert.NoError(t, err)
+	assert.Equal(t, 6, suites.Tests)
+	assert.Equal(t, 3, suites.Failures)
+	assert.Equal(t, 1, suites.Errors)
+	assert.Equal(t, 0, suites.Skipped)
+
+	suite := suites.Suites[0]
+	assert.Equal(t, []junitProperty{
+		{Name: "total.carbon_emissions", Value: "7.0000"},
+		{Name: "total.power", Value: "100.0000"},
+		{Name: "total.resources_count", Value: "3"},
+		{Name: "unit.carbon_emissions", Value: "gCO2eq/h"},
+		{Name: "unit.power", Value: "Wh"},
+	}, suite.Properties)
+	assert.Equal(t, []junitTestCase{
+		{
+			ClassName: "google_compute_instance",
+			Name:      "google_compute_instance.web",
+			Failure:   &junitMessage{Message: "address 'google_compute_instance.web': 6.0000 gCO2eq/h exceeds budget of 4.0000 gCO2eq/h", Type: "budget"},
+			SystemOut: "3.0000 gCO2eq/h per instance, 2 instances, 6.0000 gCO2eq/h in total",
+		},
+		{
+			ClassName: "google_sql_database_instance",
+			Name:      "google_sql_database_instance.db",
+			SystemOut: "1.0000 gCO2eq/h per instance, 1 instances, 1.0000 gCO2eq/h in total",
+		},
+		{
+			ClassName: "google_compute_network",
+			Name:      "google_compute_network.vpc",
+			Failure:   &junitMessage{Message: "resource type google_compute_network is not supported", Type: "unsupported"},
+		},
+		{
+			ClassName: "not_estimated",
+			Name:      "google_compute_instance.broken",
+			Error:     &junitMessage{Message: "Unknown unit for memory: xb"},
+		},
+		{
+			ClassName: "budget",
+			Name:      "total",
+			Failure:   &junitMessage{Message: "total: 7.0000 gCO2eq/h exceeds budget of 5.0000 gCO2eq/h", Type: "budget"},
+			SystemOut: "7.0000 gCO2eq/h",
+		},
+		{
+			ClassName: "budget",
+			Name:      "module module.backend",
+		},
+	}, suite.Cases)
+}
+
+func TestGenerateReportJUnitNotStrict(t *testing.T) {
+	report := junitTestReport()
+
+	var suites junitTestSuites
+	err := xml.Unmarshal([]byte(GenerateReportJUnit(report, budget.Budgets{}, nil, false)), &suites)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, suites.Tests)
+	assert.Equal(t, 0, suites.Failures)
+	assert.Equal(t, 2, suites.Skipped)
+
+	cases := suites.Suites[0].Cases
+	assert.Equal(t, &junitMessage{Message: "resource type google_compute_network is not supported"}, cases[2].Skipped)
+	assert.Equal(t, &junitMessage{Message: "no total budget configured"}, cases[4].Skipped)
+}
@@ -0,0 +1,133 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/plan"
+	log "github.com/sirupsen/logrus"
+)
+
+// GenerateReportMarkdown generates a markdown report from an estimation report, to be pasted in a pull request
+func GenerateReportMarkdown(report estimation.EstimationReport, isForecast bool) string {
+	log.Debug("Generating markdown report")
+	unit := report.Info.UnitCarbonEmissionsTime
+	builder := &strings.Builder{}
+
+	if isForecast {
+		builder.WriteString("### Carbonifer: forecast of CO2 emissions\n\n")
+	} else {
+		builder.WriteString("### Carbonifer: estimation of CO2 emissions\n\n")
+	}
+	builder.WriteString(fmt.Sprintf("**Total:** %v %v for %v resources (%v %v)",
+		report.Total.CarbonEmissions.StringFixed(4), unit,
+		report.Total.ResourcesCount.String(),
+		report.Total.Power.StringFixed(4), report.Info.UnitWattTime,
+	))
+	if len(report.UnsupportedResources) > 0 {
+		builder.WriteString(fmt.Sprintf(", %v unsupported", len(report.UnsupportedResources)))
+	}
+	if len(report.Errors) > 0 {
+		builder.WriteString(fmt.Sprintf(", %v not estimated", len(report.Errors)))
+	}
+	builder.WriteString("\n\n")
+
+	// Default sort
+	estimations := report.Resources
+	estimate.SortEstimations(&estimations)
+
+	builder.WriteString(fmt.Sprintf("<details><summary>Emissions per resource (%v)</summary>\n\n", len(estimations)))
+	builder.WriteString("| Resource | Count | Replicas | Power per instance | Emissions per instance |\n")
+	builder.WriteString("|---|---:|---:|---:|---:|\n")
+	for _, resource := range estimations {
+		builder.WriteString(fmt.Sprintf("| `%v` | %v | %v | %v %v | %v %v |\n",
+			resource.Resource.GetAddress(),
+			resource.Resource.GetIdentification().Count,
+			resource.Resource.GetIdentification().ReplicationFactor,
+			resource.Power.StringFixed(4), report.Info.UnitWattTime,
+			resource.CarbonEmissions.StringFixed(4), unit,
+		))
+	}
+	builder.WriteString(fmt.Sprintf("| **Total** | %v | | %v %v | **%v %v** |\n",
+		report.Total.ResourcesCount.String(),
+		report.Total.Power.StringFixed(4), report.Info.UnitWattTime,
+		report.Total.CarbonEmissions.StringFixed(4), unit,
+	))
+	builder.WriteString("\n</details>\n")
+
+	if len(report.UnsupportedResources) > 0 {
+		builder.WriteString(fmt.Sprintf("\n#### Unsupported resources (%v)\n\n", len(report.UnsupportedResources)))
+		for _, resource := range report.UnsupportedResources {
+			builder.WriteString(fmt.Sprintf("- `%v`\n", resource.GetAddress()))
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		builder.WriteString(fmt.Sprintf("\n#### Resources not estimated (%v)\n\n", len(report.Errors)))
+		for _, resourceError := range report.Errors {
+			builder.WriteString(fmt.Sprintf("- `%v`: %v\n", resourceError.Address, resourceError.Err))
+		}
+	}
+
+	if len(report.Unresolved) > 0 {
+		builder.WriteString(fmt.Sprintf("\n#### Attributes not resolved offline (%v)\n\n", len(report.Unresolved)))
+		for _, attribute := range report.Unresolved {
+			builder.WriteString(fmt.Sprintf("- `%v.%v`: %v\n", attribute.Address, attribute.Attribute, attribute.Reason))
+		}
+	}
+
+	if report.Delta != nil {
+		added, removed, changed := countDeltaActions(*report.Delta)
+		builder.WriteString("\n#### Estimated change of CO2 emissions\n\n")
+		builder.WriteString(fmt.Sprintf("**Delta:** %v, %v added, %v removed, %v changed\n\n",
+			strings.TrimSpace(formatSignedEmissions(report.Delta.Total.CarbonEmissions, unit)),
+			added, removed, changed,
+		))
+		writeDeltaMarkdownTable(builder, report.Delta, unit)
+	}
+	return builder.String()
+}
+
+// GenerateDiffMarkdown generates a markdown report from the comparison of two estimations
+func GenerateDiffMarkdown(diff estimation.EstimationDiffReport) string {
+	log.Debug("Generating markdown diff report")
+	unit := diff.Info.UnitCarbonEmissionsTime
+	builder := &strings.Builder{}
+
+	added, removed, changed := countDeltaActions(diff.Delta)
+	builder.WriteString("### Carbonifer: comparison of CO2 emissions\n\n")
+	builder.WriteString(fmt.Sprintf("**Total delta:** %v (%v), from %v %v to %v %v\n\n",
+		strings.TrimSpace(formatSignedEmissions(diff.Delta.Total.CarbonEmissions, unit)),
+		formatPercentChange(diff.PercentChange),
+		diff.Delta.Before.CarbonEmissions.StringFixed(4), unit,
+		diff.Delta.After.CarbonEmissions.StringFixed(4), unit,
+	))
+	builder.WriteString(fmt.Sprintf("%v added, %v removed, %v changed\n\n", added, removed, changed))
+
+	writeDeltaMarkdownTable(builder, &diff.Delta, unit)
+	return builder.String()
+}
+
+func writeDeltaMarkdownTable(builder *strings.Builder, delta *estimation.EstimationDelta, unit string) {
+	builder.WriteString("| Resource | Action | Before | After | Delta |\n")
+	builder.WriteString("|---|---|---:|---:|---:|\n")
+	for _, resourceDelta := range delta.Resources {
+		if resourceDelta.Action == plan.ActionNoOp && resourceDelta.CarbonEmissions.IsZero() {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("| `%v` | %v | %v | %v | %v |\n",
+			resourceDelta.Address,
+			resourceDelta.Action,
+			strings.TrimSpace(formatDeltaSide(resourceDelta.Before, unit)),
+			strings.TrimSpace(formatDeltaSide(resourceDelta.After, unit)),
+			strings.TrimSpace(formatSignedEmissions(resourceDelta.CarbonEmissions, unit)),
+		))
+	}
+	builder.WriteString(fmt.Sprintf("| **Total** | | %v %v | %v %v | **%v** |\n",
+		delta.Before.CarbonEmissions.StringFixed(4), unit,
+		delta.After.CarbonEmissions.StringFixed(4), unit,
+		strings.TrimSpace(formatSignedEmissions(delta.Total.CarbonEmissions, unit)),
+	))
+}
@@ -0,0 +1,92 @@
+package output
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
+)
+
+func TestGenerateReportMarkdown(t *testing.T) {
+	computeResource := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:           "google_compute_instance.first",
+			Name:              "first",
+			ResourceType:      "google_compute_instance",
+			Provider:          providers.GCP,
+			Region:            "europe-west9",
+			Count:             2,
+			ReplicationFactor: 1,
+		},
+		Specs: &resources.ComputeResourceSpecs{VCPUs: 2, MemoryMb: 4096},
+	}
+	unsupportedResource := resources.UnsupportedResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_compute_network.vpc",
+			Name:         "vpc",
+			ResourceType: "google_compute_network",
+			Provider:     providers.GCP,
+			Count:        1,
+		},
+	}
+	estimationResource := estimation.EstimationResource{
+		Resource:        computeResource,
+		Power:           decimal.NewFromFloat(7.6),
+		CarbonEmissions: decimal.NewFromFloat(0.44),
+		TotalCount:      decimal.NewFromInt(2),
+	}
+	report := estimation.EstimationReport{
+		Info: estimation.EstimationInfo{
+			UnitTime:                "h",
+			UnitWattTime:            "Wh",
+			UnitCarbonEmissionsTime: "gCO2eq/h",
+		},
+		Resources:            []estimation.EstimationResource{estimationResource},
+		UnsupportedResources: []resources.Resource{unsupportedResource},
+		Errors: []resources.ResourceError{
+			{Address: "google_compute_instance.broken", Err: errors.New("Unknown unit for memory: xb")},
+		},
+		Unresolved: []estimation.UnresolvedAttribute{
+			{Address: "google_compute_instance.first", Attribute: "count", Reason: "depends on var.zones, not known offline, assuming a single instance"},
+		},
+		Total: estimation.EstimationTotal{
+			Power:           decimal.NewFromFloat(15.2),
+			CarbonEmissions: decimal.NewFromFloat(0.88),
+			ResourcesCount:  decimal.NewFromInt(2),
+		},
+	}
+
+	got := GenerateReportMarkdown(report, false)
+	assert.Contains(t, got, "### Carbonifer: estimation of CO2 emissions\n\n")
+	assert.Contains(t, got, "**Total:** 0.8800 gCO2eq/h for 2 resources (15.2000 Wh), 1 unsupported, 1 not estimated\n")
+	assert.Contains(t, got, "<details><summary>Emissions per resource (1)</summary>\n\n| Resource | Count | Replicas | Power per instance | Emissions per instance |\n")
+	assert.Contains(t, got, "| `google_compute_instance.first` | 2 | 1 | 7.6000 Wh | 0.4400 gCO2eq/h |\n")
+	assert.Contains(t, got, "| **Total** | 2 | | 15.2000 Wh | **0.8800 gCO2eq/h** |\n\n</details>\n")
+	assert.Contains(t, got, "#### Unsupported resources (1)\n\n- `google_compute_network.vpc`\n")
+	assert.Contains(t, got, "#### Resources not estimated (1)\n\n- `google_compute_instance.broken`: Unknown unit for memory: xb\n")
+	assert.Contains(t, got, "#### Attributes not resolved offline (1)\n\n- `google_compute_instance.first.count`: depends on var.zones, not known offline, assuming a single instance\n")
+	assert.NotContains(t, got, "Estimated change")
+
+	// Delta section
+	report.Delta = &estimation.EstimationDelta{
+		Resources: []estimation.EstimationResourceDelta{
+			{
+				Address:         "google_compute_instance.first",
+				Action:          "create",
+				After:           &estimationResource,
+				CarbonEmissions: decimal.NewFromFloat(0.88),
+			},
+		},
+		After: report.Total,
+		Total: report.Total,
+	}
+	got = GenerateReportMarkdown(report, true)
+	assert.Contains(t, got, "### Carbonifer: forecast of CO2 emissions\n\n")
+	assert.Contains(t, got, "#### Estimated change of CO2 emissions\n\n**Delta:** +0.8800 gCO2eq/h, 1 added, 0 removed, 0 changed\n\n")
+	assert.Contains(t, got, "| `google_compute_instance.first` | create |  | 0.8800 gCO2eq/h | +0.8800 gCO2eq/h |\n")
+}
@@ -0,0 +1,115 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/recommend"
+	log "github.com/sirupsen/logrus"
+)
+
+// GenerateRegionsJSON generates a JSON report from region recommendations
+func GenerateRegionsJSON(report recommend.RegionsReport) string {
+	log.Debug("Generating JSON regions report")
+
+	reportTextBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(reportTextBytes)
+}
+
+// GenerateRegionsText generates a text report from region recommendations
+func GenerateRegionsText(report recommend.RegionsReport) string {
+	log.Debug("Generating text regions report")
+	builder := &strings.Builder{}
+	unit := report.Info.UnitCarbonEmissionsTime
+
+	builder.WriteString("\n  Lower carbon regions: \n\n")
+	table := newReportTable(builder, []string{"resource", "region", "carbon intensity", "emissions", "savings"})
+	for _, resource := range report.Resources {
+		table.Append([]string{
+			resource.Address,
+			fmt.Sprintf("%v (current)", resource.Region),
+			fmt.Sprintf(" %v gCO2eq/kWh", resource.GridCarbonIntensity),
+			fmt.Sprintf(" %v %v", resource.CarbonEmissions.StringFixed(4), unit),
+			"",
+		})
+		if len(resource.Recommendations) == 0 {
+			table.Append([]string{"", "no lower carbon region", "", "", ""})
+		}
+		for _, recommendation := range resource.Recommendations {
+			table.Append([]string{
+				"",
+				fmt.Sprintf("%v (%v)", recommendation.Region, recommendation.Location),
+				fmt.Sprintf(" %v gCO2eq/kWh", recommendation.GridCarbonIntensity),
+				fmt.Sprintf(" %v %v", recommendation.CarbonEmissions.StringFixed(4), unit),
+				fmt.Sprintf(" -%v %v (-%v%%)", recommendation.Savings.StringFixed(4), unit, recommendation.SavingsPercent.StringFixed(1)),
+			})
+		}
+	}
+	table.Render()
+	return builder.String()
+}
+
+// GenerateInstancesJSON generates a JSON report from instance recommendations
+func GenerateInstancesJSON(report recommend.InstancesReport) string {
+	log.Debug("Generating JSON instances report")
+
+	reportTextBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(reportTextBytes)
+}
+
+// GenerateInstancesText generates a text report from instance recommendations
+func GenerateInstancesText(report recommend.InstancesReport) string {
+	log.Debug("Generating text instances report")
+	builder := &strings.Builder{}
+	unit := report.Info.UnitCarbonEmissionsTime
+
+	builder.WriteString("\n  Lower power machine types: \n\n")
+	table := newReportTable(builder, []string{"resource", "machine type", "vCPUs", "memory", "power", "emissions", "savings", "why"})
+	for _, resource := range report.Resources {
+		why := ""
+		if resource.UnusedLocalStorageGB.IsPositive() {
+			why = fmt.Sprintf("%v GB unused local storage", resource.UnusedLocalStorageGB)
+		}
+		table.Append([]string{
+			resource.Address,
+			fmt.Sprintf("%v (current)", machineTypeName(resource.MachineType, resource.CPUType)),
+			fmt.Sprintf(" %v", resource.VCPUs),
+			fmt.Sprintf(" %v MB", resource.MemoryMb),
+			fmt.Sprintf(" %v Wh", resource.Power.StringFixed(4)),
+			fmt.Sprintf(" %v %v", resource.CarbonEmissions.StringFixed(4), unit),
+			"",
+			why,
+		})
+		if len(resource.Alternatives) == 0 {
+			table.Append([]string{"", "no lower power machine type", "", "", "", "", "", ""})
+		}
+		for _, alternative := range resource.Alternatives {
+			table.Append([]string{
+				"",
+				machineTypeName(alternative.MachineType, alternative.CPUType),
+				fmt.Sprintf(" %v", alternative.VCPUs),
+				fmt.Sprintf(" %v MB", alternative.MemoryMb),
+				fmt.Sprintf(" %v Wh", alternative.Power.StringFixed(4)),
+				fmt.Sprintf(" %v %v", alternative.CarbonEmissions.StringFixed(4), unit),
+				fmt.Sprintf(" -%v %v (-%v%%)", alternative.Savings.StringFixed(4), unit, alternative.SavingsPercent.StringFixed(1)),
+				strings.Join(alternative.Reasons, ", "),
+			})
+		}
+	}
+	table.Render()
+	return builder.String()
+}
+
+func machineTypeName(machineType string, cpuType string) string {
+	if cpuType == "" {
+		return machineType
+	}
+	return fmt.Sprintf("%v [%v]", machineType, cpuType)
+}
@@ -0,0 +1,314 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// Levels of SARIF results, "none" disables a rule
+const (
+	sarifLevelNone    = "none"
+	sarifLevelNote    = "note"
+	sarifLevelWarning = "warning"
+	sarifLevelError   = "error"
+)
+
+// Rules of SARIF reports
+const (
+	sarifRuleHighEmissions       = "high-emissions"
+	sarifRuleHighCarbonRegion    = "high-carbon-region"
+	sarifRuleGPUInstance         = "gpu-instance"
+	sarifRuleUnsupportedResource = "unsupported-resource"
+	sarifRuleNotEstimated        = "not-estimated"
+)
+
+// SARIFSettings is the configuration of SARIF reports (key "sarif")
+type SARIFSettings struct {
+	HighEmissions       SARIFThresholds `mapstructure:"high_emissions"`     // emissions of a resource, in the report unit
+	HighCarbonRegion    SARIFThresholds `mapstructure:"high_carbon_region"` // grid carbon intensity, in gCO2eq/kWh
+	GPUInstance         string          `mapstructure:"gpu_instance"`
+	UnsupportedResource string          `mapstructure:"unsupported_resource"`
+	NotEstimated        string          `mapstructure:"not_estimated"`
+}
+
+// SARIFThresholds are the values from which a result is a warning or an error, 0 to disable a level
+type SARIFThresholds struct {
+	Warning float64 `mapstructure:"warning"`
+	Error   float64 `mapstructure:"error"`
+}
+
+// level returns the level of a value, "none" if it is under the thresholds
+func (t SARIFThresholds) level(value decimal.Decimal) (string, float64) {
+	if t.Error > 0 && value.GreaterThanOrEqual(decimal.NewFromFloat(t.Error)) {
+		return sarifLevelError, t.Error
+	}
+	if t.Warning > 0 && value.GreaterThanOrEqual(decimal.NewFromFloat(t.Warning)) {
+		return sarifLevelWarning, t.Warning
+	}
+	return sarifLevelNone, 0
+}
+
+// GetSARIFSettings returns the configuration of SARIF reports
+func GetSARIFSettings() (*SARIFSettings, error) {
+	var settings SARIFSettings
+	if err := viper.UnmarshalKey("sarif", &settings); err != nil {
+		return nil, errors.Wrap(err, "Cannot read sarif configuration")
+	}
+	levels := []struct{ key, level string }{
+		{"gpu_instance", settings.GPUInstance},
+		{"unsupported_resource", settings.UnsupportedResource},
+		{"not_estimated", settings.NotEstimated},
+	}
+	for _, level := range levels {
+		switch level.level {
+		case sarifLevelNone, sarifLevelNote, sarifLevelWarning, sarifLevelError:
+		default:
+			return nil, errors.Errorf("Invalid level of sarif.%v: '%v' (none, note, warning or error)", level.key, level.level)
+		}
+	}
+	return &settings, nil
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	Name                 string             `json:"name"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifRules are the rules of SARIF reports, results refer to them by index
+var sarifRules = []sarifRule{
+	{ID: sarifRuleHighEmissions, Name: "HighEmissions", ShortDescription: sarifMessage{"Resource emits more CO2 than the configured thresholds"}},
+	{ID: sarifRuleHighCarbonRegion, Name: "HighCarbonRegion", ShortDescription: sarifMessage{"Resource runs in a region with a high grid carbon intensity"}},
+	{ID: sarifRuleGPUInstance, Name: "GPUInstance", ShortDescription: sarifMessage{"Resource has GPUs"}},
+	{ID: sarifRuleUnsupportedResource, Name: "UnsupportedResource", ShortDescription: sarifMessage{"Emissions of the resource type are not estimated"}},
+	{ID: sarifRuleNotEstimated, Name: "NotEstimated", ShortDescription: sarifMessage{"Resource could not be read or estimated"}},
+}
+
+// sarifReport collects the results of a SARIF report
+type sarifReport struct {
+	locations  terraform.SourceLocations
+	sourceRoot string
+	results    []sarifResult
+}
+
+// GenerateReportSARIF generates a SARIF report from an estimation report, to annotate the terraform files in code
+// scanning. Results are located at the block of their resource, with paths relative to sourceRoot.
+func GenerateReportSARIF(report estimation.EstimationReport, settings SARIFSettings, locations terraform.SourceLocations, sourceRoot string) string {
+	log.Debug("Generating SARIF report")
+	unit := report.Info.UnitCarbonEmissionsTime
+	sarif := &sarifReport{locations: locations, sourceRoot: sourceRoot, results: []sarifResult{}}
+
+	// Default sort
+	estimations := report.Resources
+	estimate.SortEstimations(&estimations)
+	for _, estimationResource := range estimations {
+		address := estimationResource.Resource.GetAddress()
+		emissions := estimationResource.CarbonEmissions.Mul(estimationResource.TotalCount)
+		if level, threshold := settings.HighEmissions.level(emissions); level != sarifLevelNone {
+			sarif.addResult(sarifRuleHighEmissions, level, address, fmt.Sprintf(
+				"%v emits %v %v (%v instances), over the %v threshold of %v %v",
+				address, emissions.StringFixed(4), unit, estimationResource.TotalCount, level, threshold, unit,
+			))
+		}
+		if level, threshold := settings.HighCarbonRegion.level(estimationResource.GridCarbonIntensity); level != sarifLevelNone {
+			sarif.addResult(sarifRuleHighCarbonRegion, level, address, fmt.Sprintf(
+				"%v runs in %v, where the grid emits %v gCO2eq/kWh, over the %v threshold of %v gCO2eq/kWh",
+				address, estimationResource.Resource.GetIdentification().Region, estimationResource.GridCarbonIntensity, level, threshold,
+			))
+		}
+		if gpuTypes := resourceGPUTypes(estimationResource.Resource); len(gpuTypes) > 0 && settings.GPUInstance != sarifLevelNone {
+			sarif.addResult(sarifRuleGPUInstance, settings.GPUInstance, address, fmt.Sprintf(
+				"%v has %v GPUs (%v)", address, len(gpuTypes), strings.Join(gpuTypes, ", "),
+			))
+		}
+	}
+
+	if settings.UnsupportedResource != sarifLevelNone {
+		for _, resource := range report.UnsupportedResources {
+			sarif.addResult(sarifRuleUnsupportedResource, settings.UnsupportedResource, resource.GetAddress(), fmt.Sprintf(
+				"Emissions of %v are not estimated, resource type %v is not supported",
+				resource.GetAddress(), resource.GetIdentification().ResourceType,
+			))
+		}
+	}
+
+	if settings.NotEstimated != sarifLevelNone {
+		for _, resourceError := range report.Errors {
+			sarif.addResult(sarifRuleNotEstimated, settings.NotEstimated, resourceError.Address, fmt.Sprintf(
+				"%v could not be estimated: %v", resourceError.Address, resourceError.Err,
+			))
+		}
+	}
+
+	rules := make([]sarifRule, len(sarifRules))
+	for i, rule := range sarifRules {
+		rules[i] = rule
+		rules[i].DefaultConfiguration.Level = settings.ruleLevel(rule.ID)
+	}
+
+	sarifJSON, err := json.MarshalIndent(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "carbonifer",
+				InformationURI: "https://github.com/carboniferio/carbonifer",
+				Rules:          rules,
+			}},
+			Results: sarif.results,
+		}},
+	}, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	return string(sarifJSON)
+}
+
+// ruleLevel returns the level of a rule when it reports a result, the highest level for thresholds
+func (s SARIFSettings) ruleLevel(ruleID string) string {
+	switch ruleID {
+	case sarifRuleHighEmissions:
+		return s.HighEmissions.highestLevel()
+	case sarifRuleHighCarbonRegion:
+		return s.HighCarbonRegion.highestLevel()
+	case sarifRuleGPUInstance:
+		return s.GPUInstance
+	case sarifRuleUnsupportedResource:
+		return s.UnsupportedResource
+	default:
+		return s.NotEstimated
+	}
+}
+
+func (t SARIFThresholds) highestLevel() string {
+	switch {
+	case t.Error > 0:
+		return sarifLevelError
+	case t.Warning > 0:
+		return sarifLevelWarning
+	default:
+		return sarifLevelNone
+	}
+}
+
+// addResult adds a result of a resource, located at its block if it is found in the terraform files
+func (r *sarifReport) addResult(ruleID string, level string, address string, message string) {
+	ruleIndex := 0
+	for i, rule := range sarifRules {
+		if rule.ID == ruleID {
+			ruleIndex = i
+		}
+	}
+	location := sarifLocation{
+		LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: address, Kind: "resource"}},
+	}
+	if source, ok := r.locations.Get(address); ok {
+		location.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: r.artifactLocation(source.File),
+			Region:           sarifRegion{StartLine: source.Line},
+		}
+	}
+	r.results = append(r.results, sarifResult{
+		RuleID:    ruleID,
+		RuleIndex: ruleIndex,
+		Level:     level,
+		Message:   sarifMessage{Text: message},
+		Locations: []sarifLocation{location},
+	})
+}
+
+// artifactLocation returns the location of a file, relative to the source root if it is in it
+func (r *sarifReport) artifactLocation(file string) sarifArtifactLocation {
+	if relative, err := filepath.Rel(r.sourceRoot, file); err == nil && !strings.HasPrefix(relative, "..") {
+		return sarifArtifactLocation{URI: filepath.ToSlash(relative), URIBaseID: "%SRCROOT%"}
+	}
+	return sarifArtifactLocation{URI: "file://" + filepath.ToSlash(file)}
+}
+
+// resourceGPUTypes returns the GPUs of a compute resource
+func resourceGPUTypes(resource resources.Resource) []string {
+	switch computeResource := resource.(type) {
+	case resources.ComputeResource:
+		if computeResource.Specs != nil {
+			return computeResource.Specs.GpuTypes
+		}
+	case *resources.ComputeResource:
+		if computeResource.Specs != nil {
+			return computeResource.Specs.GpuTypes
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package output
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+)
+
+func TestGenerateReportSARIF(t *testing.T) {
+	gpuInstance := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "module.ml.google_compute_instance.gpu[0]",
+			ResourceType: "google_compute_instance",
+			Provider:     providers.GCP,
+			Region:       "asia-south1",
+			Count:        1,
+		},
+		Specs: &resources.ComputeResourceSpecs{GpuTypes: []string{"nvidia-tesla-t4"}},
+	}
+	smallInstance := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "google_compute_instance.small",
+			ResourceType: "google_compute_instance",
+			Provider:     providers.GCP,
+			Region:       "europe-west9",
+			Count:        1,
+		},
+	}
+	report := estimation.EstimationReport{
+		Info: estimation.EstimationInfo{UnitCarbonEmissionsTime: "gCO2eq/h"},
+		Resources: []estimation.EstimationResource{
+			{
+				Resource:            &gpuInstance,
+				CarbonEmissions:     decimal.NewFromInt(60),
+				TotalCount:          decimal.NewFromInt(2),
+				GridCarbonIntensity: decimal.NewFromInt(450),
+			},
+			{
+				Resource:            &smallInstance,
+				CarbonEmissions:     decimal.NewFromFloat(0.5),
+				TotalCount:          decimal.NewFromInt(1),
+				GridCarbonIntensity: decimal.NewFromInt(59),
+			},
+		},
+		UnsupportedResources: []resources.Resource{
+			resources.UnsupportedResource{
+				Identification: &resources.ResourceIdentification{
+					Address:      "google_compute_network.vpc",
+					ResourceType: "google_compute_network",
+					Provider:     providers.GCP,
+				},
+			},
+		},
+		Errors: []resources.ResourceError{
+			{Address: "google_compute_instance.broken", Err: errors.New("Unknown unit for memory: xb")},
+		},
+	}
+	settings := SARIFSettings{
+		HighEmissions:       SARIFThresholds{Warning: 10, Error: 100},
+		HighCarbonRegion:    SARIFThresholds{Warning: 300, Error: 600},
+		GPUInstance:         "note",
+		UnsupportedResource: "warning",
+		NotEstimated:        "none",
+	}
+	root := filepath.Join(string(filepath.Separator), "repo")
+	locations := terraform.SourceLocations{
+		"module.ml.google_compute_instance.gpu": {File: filepath.Join(root, "infra", "modules", "ml", "main.tf"), Line: 12},
+		"google_compute_network.vpc":            {File: filepath.Join(string(filepath.Separator), "elsewhere", "network.tf"), Line: 3},
+	}
+
+	var sarif sarifLog
+	err := json.Unmarshal([]byte(GenerateReportSARIF(report, settings, locations, root)), &sarif)
+	assert.NoError(t, err)
+	assert.Equal(t, "2.1.0", sarif.Version)
+	run := sarif.Runs[0]
+	assert.Equal(t, "carbonifer", run.Tool.Driver.Name)
+	assert.Len(t, run.Tool.Driver.Rules, 5)
+	assert.Equal(t, "error", run.Tool.Driver.Rules[0].DefaultConfiguration.Level)
+	assert.Equal(t, "none", run.Tool.Driver.Rules[4].DefaultConfiguration.Level)
+
+	gpuLocation := []sarifLocation{{
+		PhysicalLocation: &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "infra/modules/ml/main.tf", URIBaseID: "%SRCROOT%"},
+			Region:           sarifRegion{StartLine: 12},
+		},
+		LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "module.ml.google_compute_instance.gpu[0]", Kind: "resource"}},
+	}}
+	assert.Equal(t, []sarifResult{
+		{
+			RuleID:    "high-emissions",
+			RuleIndex: 0,
+			Level:     "error",
+			Message:   sarifMessage{"module.ml.google_compute_instance.gpu[0] emits 120.0000 gCO2eq/h (2 instances), over the error threshold of 100 gCO2eq/h"},
+			Locations: gpuLocation,
+		},
+		{
+			RuleID:    "high-carbon-region",
+			RuleIndex: 1,
+			Level:     "warning",
+			Message:   sarifMessage{"module.ml.google_compute_instance.gpu[0] runs in asia-south1, where the grid emits 450 gCO2eq/kWh, over the warning threshold of 300 gCO2eq/kWh"},
+			Locations: gpuLocation,
+		},
+		{
+			RuleID:    "gpu-instance",
+			RuleIndex: 2,
+			Level:     "note",
+			Message:   sarifMessage{"module.ml.google_compute_instance.gpu[0] has 1 GPUs (nvidia-tesla-t4)"},
+			Locations: gpuLocation,
+		},
+		{
+			RuleID:    "unsupported-resource",
+			RuleIndex: 3,
+			Level:     "warning",
+			Message:   sarifMessage{"Emissions of google_compute_network.vpc are not estimated, resource type google_compute_network is not supported"},
+			Locations: []sarifLocation{{
+				PhysicalLocation: &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: "file:///elsewhere/network.tf"},
+					Region:           sarifRegion{StartLine: 3},
+				},
+				LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: "google_compute_network.vpc", Kind: "resource"}},
+			}},
+		},
+	}, run.Results)
+}
+
+func TestGetSARIFSettings(t *testing.T) {
+	viper.Set("sarif.high_emissions.warning", 5)
+	viper.Set("sarif.gpu_instance", "warning")
+	viper.Set("sarif.unsupported_resource", "none")
+	viper.Set("sarif.not_estimated", "error")
+	defer viper.Set("sarif", nil)
+
+	settings, err := GetSARIFSettings()
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, settings.HighEmissions.Warning)
+	assert.Equal(t, "warning", settings.GPUInstance)
+
+	viper.Set("sarif.gpu_instance", "critical")
+	_, err = GetSARIFSettings()
+	assert.EqualError(t, err, "Invalid level of sarif.gpu_instance: 'critical' (none, note, warning or error)")
+}
+
+func TestGenerateReportSARIF_OfflineModule(t *testing.T) {
+	dir := filepath.Join(testutils.RootDir, "test/terraform/offline")
+	tfPlan, _, err := terraform.OfflinePlan(dir, terraform.PlanSettings{})
+	assert.NoError(t, err)
+	locations, err := terraform.GetSourceLocations(tfPlan, dir)
+	assert.NoError(t, err)
+
+	disk := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:      "module.storage.google_compute_disk.data",
+			ResourceType: "google_compute_disk",
+			Provider:     providers.GCP,
+			Region:       "europe-west9",
+			Count:        1,
+		},
+	}
+	report := estimation.EstimationReport{
+		Info: estimation.EstimationInfo{UnitCarbonEmissionsTime: "gCO2eq/h"},
+		Resources: []estimation.EstimationResource{
+			{
+				Resource:            &disk,
+				CarbonEmissions:     decimal.NewFromInt(20),
+				TotalCount:          decimal.NewFromInt(1),
+				GridCarbonIntensity: decimal.NewFromInt(59),
+			},
+		},
+	}
+	settings := SARIFSettings{
+		HighEmissions:    SARIFThresholds{Warning: 10, Error: 100},
+		HighCarbonRegion: SARIFThresholds{Warning: 300, Error: 600},
+	}
+
+	var sarif sarifLog
+	err = json.Unmarshal([]byte(GenerateReportSARIF(report, settings, locations, dir)), &sarif)
+	assert.NoError(t, err)
+	results := sarif.Runs[0].Results
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: "storage/main.tf", URIBaseID: "%SRCROOT%"},
+			Region:           sarifRegion{StartLine: 10},
+		}, results[0].Locations[0].PhysicalLocation)
+	}
+}
@@ -2,11 +2,15 @@ package output
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/carboniferio/carbonifer/internal/estimate"
 	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
 	"github.com/olekukonko/tablewriter"
+	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -57,5 +61,159 @@ func GenerateReportText(report estimation.EstimationReport, isForecast bool) str
 	table.SetCenterSeparator(" ")
 
 	table.Render()
+
+	if len(report.Errors) > 0 {
+		generateErrorsText(tableString, report.Errors)
+	}
+	if len(report.Unresolved) > 0 {
+		generateUnresolvedText(tableString, report.Unresolved)
+	}
+	if report.Info.Terraform != nil {
+		generateTerraformInfoText(tableString, report.Info.Terraform)
+	}
+	if report.Delta != nil {
+		generateDeltaText(tableString, report)
+	}
 	return tableString.String()
 }
+
+func generateErrorsText(tableString *strings.Builder, resourceErrors []resources.ResourceError) {
+	tableString.WriteString(fmt.Sprintf("\n  Resources not estimated (%v):\n", len(resourceErrors)))
+	for _, resourceError := range resourceErrors {
+		tableString.WriteString(fmt.Sprintf("  - %v\n", resourceError.Error()))
+	}
+}
+
+func generateUnresolvedText(tableString *strings.Builder, unresolved []estimation.UnresolvedAttribute) {
+	tableString.WriteString(fmt.Sprintf("\n  Attributes not resolved offline (%v):\n", len(unresolved)))
+	for _, attribute := range unresolved {
+		tableString.WriteString(fmt.Sprintf("  - %v.%v: %v\n", attribute.Address, attribute.Attribute, attribute.Reason))
+	}
+}
+
+func generateTerraformInfoText(tableString *strings.Builder, info *estimation.TerraformInfo) {
+	tableString.WriteString(fmt.Sprintf("\n  Terraform workspace: %v\n", info.Workspace))
+	if len(info.VarFiles) > 0 {
+		tableString.WriteString(fmt.Sprintf("  Var files: %v\n", strings.Join(info.VarFiles, ", ")))
+	}
+	if len(info.Variables) > 0 {
+		names := make([]string, 0, len(info.Variables))
+		for name := range info.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		variables := make([]string, 0, len(names))
+		for _, name := range names {
+			variables = append(variables, fmt.Sprintf("%v=%v", name, info.Variables[name]))
+		}
+		tableString.WriteString(fmt.Sprintf("  Variables: %v\n", strings.Join(variables, ", ")))
+	}
+	if len(info.Targets) > 0 {
+		tableString.WriteString(fmt.Sprintf("  Targets: %v\n", strings.Join(info.Targets, ", ")))
+	}
+}
+
+func generateDeltaText(tableString *strings.Builder, report estimation.EstimationReport) {
+	tableString.WriteString("\n  Estimated change of CO2 emissions: \n\n")
+	renderDeltaTable(tableString, report.Delta, report.Info.UnitCarbonEmissionsTime)
+}
+
+// GenerateDiffText generates a text report from the comparison of two estimations
+func GenerateDiffText(diff estimation.EstimationDiffReport) string {
+	log.Debug("Generating text diff report")
+	tableString := &strings.Builder{}
+	unit := diff.Info.UnitCarbonEmissionsTime
+
+	added, removed, changed := countDeltaActions(diff.Delta)
+	tableString.WriteString(fmt.Sprintf("\n  Comparison of CO2 emissions: %v added, %v removed, %v changed\n\n", added, removed, changed))
+
+	renderDeltaTable(tableString, &diff.Delta, unit)
+
+	tableString.WriteString(fmt.Sprintf("\n  Total delta: %v (%v)\n", strings.TrimSpace(formatSignedEmissions(diff.Delta.Total.CarbonEmissions, unit)), formatPercentChange(diff.PercentChange)))
+	return tableString.String()
+}
+
+// renderDeltaTable renders the resources of a delta, without the unchanged ones
+func renderDeltaTable(tableString *strings.Builder, delta *estimation.EstimationDelta, unit string) {
+	table := tablewriter.NewWriter(tableString)
+	table.SetHeader([]string{"resource", "action", "before", "after", "delta"})
+
+	for _, resourceDelta := range delta.Resources {
+		if resourceDelta.Action == plan.ActionNoOp && resourceDelta.CarbonEmissions.IsZero() {
+			continue
+		}
+		table.Append([]string{
+			resourceDelta.Address,
+			resourceDelta.Action,
+			formatDeltaSide(resourceDelta.Before, unit),
+			formatDeltaSide(resourceDelta.After, unit),
+			formatSignedEmissions(resourceDelta.CarbonEmissions, unit),
+		})
+	}
+
+	table.SetFooter([]string{
+		"Total",
+		"",
+		fmt.Sprintf(" %v %v", delta.Before.CarbonEmissions.StringFixed(4), unit),
+		fmt.Sprintf(" %v %v", delta.After.CarbonEmissions.StringFixed(4), unit),
+		formatSignedEmissions(delta.Total.CarbonEmissions, unit),
+	})
+
+	// Format
+	table.SetAutoFormatHeaders(false)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetFooterAlignment(tablewriter.ALIGN_LEFT)
+	table.SetBorder(true)
+	table.SetColumnSeparator(" ")
+	table.SetCenterSeparator(" ")
+
+	table.Render()
+}
+
+// countDeltaActions counts added, removed and changed (updated or replaced) resources of a delta
+func countDeltaActions(delta estimation.EstimationDelta) (int, int, int) {
+	added, removed, changed := 0, 0, 0
+	for _, resourceDelta := range delta.Resources {
+		switch resourceDelta.Action {
+		case plan.ActionCreate:
+			added++
+		case plan.ActionDelete:
+			removed++
+		case plan.ActionUpdate, plan.ActionReplace:
+			changed++
+		}
+	}
+	return added, removed, changed
+}
+
+func formatPercentChange(percentChange *decimal.Decimal) string {
+	if percentChange == nil {
+		return "n/a"
+	}
+	sign := ""
+	if percentChange.IsPositive() {
+		sign = "+"
+	}
+	return fmt.Sprintf("%v%v%%", sign, percentChange.StringFixed(2))
+}
+
+// formatDeltaSide prints the total emissions of one side (before or after) of a resource change
+func formatDeltaSide(estimationResource *estimation.EstimationResource, unit string) string {
+	if estimationResource == nil {
+		return ""
+	}
+	if !estimationResource.Resource.IsSupported() {
+		return "unsupported"
+	}
+	total := estimationResource.CarbonEmissions.Mul(estimationResource.TotalCount)
+	return fmt.Sprintf(" %v %v", total.StringFixed(4), unit)
+}
+
+func formatSignedEmissions(emissions decimal.Decimal, unit string) string {
+	sign := ""
+	if emissions.IsPositive() {
+		sign = "+"
+	}
+	return fmt.Sprintf(" %v%v %v", sign, emissions.StringFixed(4), unit)
+}
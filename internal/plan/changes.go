@@ -0,0 +1,191 @@
+package plan
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/utils"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// Actions of a resource change, as computed from the terraform plan "actions" list
+const (
+	ActionNoOp    = "no-op"
+	ActionCreate  = "create"
+	ActionRead    = "read"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionReplace = "replace"
+)
+
+// ResourcesChanges contains the resources of a Terraform plan before and after the change
+type ResourcesChanges struct {
+	Before  map[string]resources.Resource
+	After   map[string]resources.Resource
+	Actions map[string]string // Action by resource address
+}
+
+// GetResourcesChanges returns the resources of the Terraform plan before and after applying it.
+// Both sides are read from the resource_changes block and go through the same mappings and config as GetResources.
+func GetResourcesChanges(config *viper.Viper, mappings *Mappings, tfplan *map[string]interface{}) (*ResourcesChanges, error) {
+	resourceChanges, err := utils.GetJSON(".resource_changes[]?", *tfplan)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read resource changes")
+	}
+
+	// Data resources are lookups (images, snapshots...) used by mappings, keep them on both sides
+	dataResources, err := utils.GetJSON(".planned_values | .. | objects | select(has(\"resources\")) | .resources[] | select(.mode == \"data\")", *tfplan)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read data resources")
+	}
+
+	actions := map[string]string{}
+	beforeResources := []map[string]interface{}{}
+	afterResources := []map[string]interface{}{}
+	for _, resourceChangeI := range resourceChanges {
+		resourceChange, ok := resourceChangeI.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("Cannot parse resource change %v", resourceChangeI)
+		}
+		address, ok := resourceChange["address"].(string)
+		if !ok {
+			return nil, errors.Errorf("Cannot find address of resource change %v", resourceChangeI)
+		}
+		change, ok := resourceChange["change"].(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("Cannot find change of resource %v", address)
+		}
+		action := parseActions(change["actions"])
+		if resourceChange["mode"] == "data" {
+			continue
+		}
+		actions[address] = action
+
+		if before, ok := change["before"].(map[string]interface{}); ok {
+			beforeResources = append(beforeResources, changeToPlannedResource(resourceChange, before))
+		}
+		if after, ok := change["after"].(map[string]interface{}); ok {
+			afterResources = append(afterResources, changeToPlannedResource(resourceChange, after))
+		}
+	}
+	for _, dataResourceI := range dataResources {
+		dataResource, ok := dataResourceI.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		beforeResources = append(beforeResources, dataResource)
+		afterResources = append(afterResources, dataResource)
+	}
+
+	// Resources that cannot be read are left out of the changes, they have already been logged
+	beforeResourcesMap, err := GetResources(config, mappings, withPlannedResources(tfplan, beforeResources))
+	if _, err := resources.SplitResourceErrors(err); err != nil {
+		return nil, errors.Wrap(err, "Cannot get resources before change")
+	}
+	afterResourcesMap, err := GetResources(config, mappings, withPlannedResources(tfplan, afterResources))
+	if _, err := resources.SplitResourceErrors(err); err != nil {
+		return nil, errors.Wrap(err, "Cannot get resources after change")
+	}
+
+	return &ResourcesChanges{
+		Before:  beforeResourcesMap,
+		After:   afterResourcesMap,
+		Actions: actions,
+	}, nil
+}
+
+// parseActions converts terraform plan actions (ex: ["delete", "create"]) to a single action
+func parseActions(actionsI interface{}) string {
+	actionsList, ok := actionsI.([]interface{})
+	if !ok || len(actionsList) == 0 {
+		return ActionNoOp
+	}
+	if len(actionsList) > 1 {
+		return ActionReplace
+	}
+	action, ok := actionsList[0].(string)
+	if !ok {
+		return ActionNoOp
+	}
+	return action
+}
+
+// changeToPlannedResource converts a resource change into a resource as found in .planned_values
+func changeToPlannedResource(resourceChange map[string]interface{}, values map[string]interface{}) map[string]interface{} {
+	plannedResource := map[string]interface{}{
+		"values": values,
+	}
+	for _, key := range []string{"address", "mode", "type", "name", "index", "provider_name", "module_address"} {
+		if value, ok := resourceChange[key]; ok {
+			plannedResource[key] = value
+		}
+	}
+	return plannedResource
+}
+
+// withPlannedResources returns a copy of the plan where .planned_values only contains the given resources
+func withPlannedResources(tfplan *map[string]interface{}, plannedResources []map[string]interface{}) *map[string]interface{} {
+	plan := map[string]interface{}{}
+	for k, v := range *tfplan {
+		plan[k] = v
+	}
+	plan["planned_values"] = map[string]interface{}{
+		"root_module": BuildRootModule(plannedResources),
+	}
+	return &plan
+}
+
+// BuildRootModule nests resources into a module tree (root_module with child_modules) by their module address
+func BuildRootModule(plannedResources []map[string]interface{}) map[string]interface{} {
+	resourcesByModule := map[string][]interface{}{}
+	for _, resource := range plannedResources {
+		moduleAddress, _ := resource["module_address"].(string)
+		resourcesByModule[moduleAddress] = append(resourcesByModule[moduleAddress], resource)
+		// Make sure parent modules exist
+		for parent := parentModuleAddress(moduleAddress); parent != ""; parent = parentModuleAddress(parent) {
+			if _, ok := resourcesByModule[parent]; !ok {
+				resourcesByModule[parent] = []interface{}{}
+			}
+		}
+	}
+	return buildModule("", resourcesByModule)
+}
+
+func buildModule(address string, resourcesByModule map[string][]interface{}) map[string]interface{} {
+	module := map[string]interface{}{
+		"resources": resourcesByModule[address],
+	}
+	if module["resources"] == nil {
+		module["resources"] = []interface{}{}
+	}
+	if address != "" {
+		module["address"] = address
+	}
+
+	childAddresses := []string{}
+	for moduleAddress := range resourcesByModule {
+		if moduleAddress != "" && parentModuleAddress(moduleAddress) == address {
+			childAddresses = append(childAddresses, moduleAddress)
+		}
+	}
+	sort.Strings(childAddresses)
+	if len(childAddresses) > 0 {
+		childModules := []interface{}{}
+		for _, childAddress := range childAddresses {
+			childModules = append(childModules, buildModule(childAddress, resourcesByModule))
+		}
+		module["child_modules"] = childModules
+	}
+	return module
+}
+
+// parentModuleAddress returns the address of the parent module (ex: module.a for module.a.module.b["x"])
+func parentModuleAddress(moduleAddress string) string {
+	index := strings.LastIndex(moduleAddress, ".module.")
+	if index < 0 {
+		return ""
+	}
+	return moduleAddress[:index]
+}
@@ -0,0 +1,162 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/polkeli/yaml/v3"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+)
+
+// LoadMappingFile adds the mappings of a file to the embedded ones (overriding resource types with the same name)
+// and returns the resource types it defines
+func LoadMappingFile(filename string) ([]string, error) {
+	mappings, err := GetMapping()
+	if err != nil {
+		return nil, err
+	}
+	return addMappingFile(mappings, filename)
+}
+
+// addMappingFile adds the mappings of a file to mappings and returns the resource types it defines
+func addMappingFile(target *Mappings, filename string) ([]string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot read mapping file %v", filename)
+	}
+	var mappings Mappings
+	if err := yaml.Unmarshal(content, &mappings); err != nil {
+		return nil, errors.Wrapf(err, "Cannot parse mapping file %v", filename)
+	}
+	if mappings.General != nil {
+		for provider, generalConfig := range *mappings.General {
+			merged := (*target.General)[provider]
+			mergeGeneralConfig(&merged, generalConfig)
+			(*target.General)[provider] = merged
+		}
+	}
+	resourceTypes := []string{}
+	if mappings.ComputeResource != nil {
+		for resourceType, mapping := range *mappings.ComputeResource {
+			(*target.ComputeResource)[resourceType] = mapping
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+	}
+	sort.Strings(resourceTypes)
+	return resourceTypes, nil
+}
+
+// CheckMappings reads the resources of the given types from a terraform plan and compares them with the expected ones.
+// Expected resources are a map of addresses to a subset of the compute resource (ex: {Specs: {VCPUs: 2}}).
+// It returns the differences found.
+func CheckMappings(config *viper.Viper, tfplan *map[string]interface{}, resourceTypes []string, expected map[string]interface{}) ([]string, error) {
+	planData, err := newTfPlanData(config, nil, tfplan)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get mapping")
+	}
+	mappings := planData.mappings
+
+	actual := map[string]interface{}{}
+	failed := map[string]error{}
+	for _, resourceType := range resourceTypes {
+		if _, ok := (*mappings.ComputeResource)[resourceType]; !ok {
+			return nil, errors.Errorf("No mapping for resource type %v", resourceType)
+		}
+	}
+	resourcesOfTypes, resourceErrors, err := getResourcesOfTypes(planData, resourceTypes)
+	if err != nil {
+		return nil, err
+	}
+	for _, resourceError := range resourceErrors {
+		failed[resourceError.Address] = resourceError.Err
+	}
+	for _, resource := range resourcesOfTypes {
+		// Compare JSON values, as written in the expected file
+		resourceJSON, err := json.Marshal(resource)
+		if err != nil {
+			return nil, err
+		}
+		var resourceMap interface{}
+		if err := json.Unmarshal(resourceJSON, &resourceMap); err != nil {
+			return nil, err
+		}
+		actual[resource.GetAddress()] = resourceMap
+	}
+
+	differences := []string{}
+	addresses := make([]string, 0, len(expected))
+	for address := range expected {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+	for _, address := range addresses {
+		if err, ok := failed[address]; ok {
+			differences = append(differences, fmt.Sprintf("%v: %v", address, err))
+			continue
+		}
+		actualResource, ok := actual[address]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("%v: resource not found", address))
+			continue
+		}
+		differences = append(differences, compareExpected(address, expected[address], actualResource)...)
+	}
+	return differences, nil
+}
+
+// compareExpected compares the values set in expected with the actual ones, other actual values are ignored
+func compareExpected(path string, expected interface{}, actual interface{}) []string {
+	switch expectedTyped := expected.(type) {
+	case map[string]interface{}:
+		actualMap, ok := actual.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%v: expected an object, got %v", path, actual)}
+		}
+		keys := make([]string, 0, len(expectedTyped))
+		for key := range expectedTyped {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		differences := []string{}
+		for _, key := range keys {
+			differences = append(differences, compareExpected(path+"."+key, expectedTyped[key], actualMap[key])...)
+		}
+		return differences
+	case []interface{}:
+		actualSlice, ok := actual.([]interface{})
+		if !ok && !(actual == nil && len(expectedTyped) == 0) {
+			return []string{fmt.Sprintf("%v: expected %v, got %v", path, expected, actual)}
+		}
+		if len(actualSlice) != len(expectedTyped) {
+			return []string{fmt.Sprintf("%v: expected %v items, got %v", path, len(expectedTyped), len(actualSlice))}
+		}
+		differences := []string{}
+		for i := range expectedTyped {
+			differences = append(differences, compareExpected(fmt.Sprintf("%v[%v]", path, i), expectedTyped[i], actualSlice[i])...)
+		}
+		return differences
+	default:
+		if !sameValue(expected, actual) {
+			return []string{fmt.Sprintf("%v: expected %v, got %v", path, expected, actual)}
+		}
+		return nil
+	}
+}
+
+// sameValue compares scalars, numbers are compared whatever their type (decimals are strings in JSON)
+func sameValue(expected interface{}, actual interface{}) bool {
+	if expected == nil || actual == nil {
+		return expected == actual
+	}
+	expectedString, actualString := fmt.Sprintf("%v", expected), fmt.Sprintf("%v", actual)
+	expectedDecimal, errExpected := decimal.NewFromString(expectedString)
+	actualDecimal, errActual := decimal.NewFromString(actualString)
+	if errExpected == nil && errActual == nil {
+		return expectedDecimal.Equal(actualDecimal)
+	}
+	return expectedString == actualString
+}
@@ -3,7 +3,7 @@ package plan
 import (
 	"os"
 
-	log "github.com/sirupsen/logrus"
+	"github.com/pkg/errors"
 
 	"github.com/aws/aws-sdk-go/aws/ec2metadata"
 	"github.com/aws/aws-sdk-go/aws/session"
@@ -11,9 +11,9 @@ import (
 
 var defaultRegion *string
 
-func getDefaultRegion() *string {
+func getDefaultRegion() (*string, error) {
 	if defaultRegion != nil {
-		return defaultRegion
+		return defaultRegion, nil
 	}
 	var region interface{}
 	if region == nil {
@@ -31,7 +31,7 @@ func getDefaultRegion() *string {
 	if region == nil {
 		sess, err := session.NewSession()
 		if err != nil {
-			log.Fatalf("Error getting region from AWS config file %v", err)
+			return nil, errors.Wrap(err, "Error getting region from AWS config file")
 		}
 		if *sess.Config.Region != "" {
 			region = *sess.Config.Region
@@ -48,11 +48,11 @@ func getDefaultRegion() *string {
 	}
 	regionPtr, ok := region.(*string)
 	if ok {
-		return regionPtr
+		return regionPtr, nil
 	}
 	regionString, ok := region.(string)
 	if !ok {
-		return nil
+		return nil, nil
 	}
-	return &regionString
+	return &regionString, nil
 }
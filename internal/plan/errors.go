@@ -0,0 +1,19 @@
+package plan
+
+import "fmt"
+
+// MappingError is an error that occurs when a resource cannot be read with the mapping of its type
+// (invalid query, unknown unit, unparsable value...)
+type MappingError struct {
+	ResourceType string
+	ParentError  error
+}
+
+func (e *MappingError) Error() string {
+	return fmt.Sprintf("Cannot read %v with its mapping: %v", e.ResourceType, e.ParentError)
+}
+
+// Unwrap returns the error of the mapping
+func (e *MappingError) Unwrap() error {
+	return e.ParentError
+}
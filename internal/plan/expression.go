@@ -1,10 +1,31 @@
 package plan
 
 import (
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/carboniferio/carbonifer/internal/terraform"
 	"github.com/pkg/errors"
 )
 
+// consoleReferences are the references of the expressions of a plan configuration. They are evaluated together by
+// terraform console the first time an expression is, instead of running terraform console for each resource.
+type consoleReferences struct {
+	references []string
+	once       sync.Once
+}
+
+// evaluate evaluates the references of the plan configuration once, results are cached by the console session
+func (c *consoleReferences) evaluate(session *terraform.ConsoleSession) {
+	if c == nil {
+		return
+	}
+	c.once.Do(func() {
+		session.EvaluateAll(c.references...)
+	})
+}
+
 func getValueOfExpression(expression map[string]interface{}, context *tfContext) (interface{}, error) {
 
 	if expression["constant_value"] != nil {
@@ -20,20 +41,81 @@ func getValueOfExpression(expression map[string]interface{}, context *tfContext)
 		return nil, errors.Errorf("References is not an array: %v : %T", expression["references"], expression["references"])
 	}
 
+	referenceStrings := []string{}
 	for _, reference := range references {
 		reference, ok := reference.(string)
 		if !ok {
 			return nil, errors.Errorf("Reference is not a string: %v : %T", reference, reference)
 		}
+		referenceStrings = append(referenceStrings, reference)
+	}
 
-		valueFromConsole, err := terraform.RunTerraformConsole(reference)
-		if err != nil {
-			continue
+	// All references of the plan are evaluated in as few terraform console runs as possible, with the first expression
+	if context.RootContext.Plan.config.GetBool("offline") {
+		return nil, errors.New("cannot run terraform console offline")
+	}
+	session, err := terraform.GetConsoleSession()
+	if err != nil {
+		return nil, err
+	}
+	context.RootContext.Plan.console.evaluate(session)
+	values := session.EvaluateAll(referenceStrings...)
+	for _, reference := range referenceStrings {
+		if value := values[reference]; value != "" {
+			return value, nil
 		}
-		if valueFromConsole != nil && *valueFromConsole != "" {
-			return *valueFromConsole, nil
+	}
+	return nil, errors.New("no value found for expression")
+}
+
+// collectConsoleReferences returns the references of the expressions of the providers and resources of the root
+// module, the ones terraform console can evaluate. References only valid in a resource (count, each, self) are left out.
+func collectConsoleReferences(tfPlan map[string]interface{}) []string {
+	configuration, _ := tfPlan["configuration"].(map[string]interface{})
+	collected := map[string]bool{}
+	providerConfigs, _ := configuration["provider_config"].(map[string]interface{})
+	for _, providerConfigI := range providerConfigs {
+		providerConfig, _ := providerConfigI.(map[string]interface{})
+		if _, ok := providerConfig["module_address"]; !ok {
+			collectExpressionReferences(providerConfig["expressions"], collected)
 		}
+	}
+	rootModule, _ := configuration["root_module"].(map[string]interface{})
+	rootResources, _ := rootModule["resources"].([]interface{})
+	for _, resourceI := range rootResources {
+		resource, _ := resourceI.(map[string]interface{})
+		collectExpressionReferences(resource["expressions"], collected)
+	}
 
+	references := []string{}
+	for reference := range collected {
+		references = append(references, reference)
+	}
+	sort.Strings(references)
+	return references
+}
+
+// collectExpressionReferences adds the references of expressions, and of the expressions of their nested blocks
+func collectExpressionReferences(expressions interface{}, collected map[string]bool) {
+	switch value := expressions.(type) {
+	case []interface{}:
+		for _, item := range value {
+			collectExpressionReferences(item, collected)
+		}
+	case map[string]interface{}:
+		if references, ok := value["references"].([]interface{}); ok {
+			for _, referenceI := range references {
+				reference, ok := referenceI.(string)
+				if ok && !strings.HasPrefix(reference, "count.") && !strings.HasPrefix(reference, "each.") && !strings.HasPrefix(reference, "self.") {
+					collected[reference] = true
+				}
+			}
+			return
+		}
+		for key, item := range value {
+			if key != "constant_value" {
+				collectExpressionReferences(item, collected)
+			}
+		}
 	}
-	return nil, errors.New("no value found for expression")
 }
@@ -0,0 +1,98 @@
+package plan
+
+import (
+	"regexp"
+
+	"github.com/carboniferio/carbonifer/internal/utils"
+	"github.com/spf13/viper"
+)
+
+// allSelectRegexp matches the queries selecting planned resources by a literal type or address,
+// with an optional query applied to each resource
+var allSelectRegexp = regexp.MustCompile(`^\s*cbf::all_select\(\s*"(type|address)"\s*;\s*"([^"\\]*)"\s*\)\s*(?:\|(.*))?$`)
+
+// plannedIndex indexes the planned resources of a plan by type and address, so that cbf::all_select doesn't scan the plan
+type plannedIndex map[string]map[string][]interface{}
+
+// tfPlanData is a plan read by mappings, with its index of planned resources, the mappings and the config it is read with.
+// gojq writes into the data it queries, so a tfPlanData must only be read by one goroutine at a time.
+type tfPlanData struct {
+	plan     *map[string]interface{}
+	index    plannedIndex
+	console  *consoleReferences
+	mappings *Mappings
+	config   *viper.Viper
+}
+
+// newTfPlanData returns the data of a plan read with mappings (nil for the mappings in use, see GetMapping) and config
+func newTfPlanData(config *viper.Viper, mappings *Mappings, tfplan *map[string]interface{}) (*tfPlanData, error) {
+	if mappings == nil {
+		var err error
+		mappings, err = GetMapping()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &tfPlanData{
+		plan:     tfplan,
+		console:  &consoleReferences{references: collectConsoleReferences(*tfplan)},
+		mappings: mappings,
+		config:   config,
+	}, nil
+}
+
+// copy returns a deep copy of the plan data, to be read by another goroutine
+func (p *tfPlanData) copy() *tfPlanData {
+	planCopy := utils.CopyJSON(*p.plan).(map[string]interface{})
+	return &tfPlanData{plan: &planCopy, console: p.console, mappings: p.mappings, config: p.config}
+}
+
+func (p *tfPlanData) getPlannedIndex() (plannedIndex, error) {
+	if p.index != nil {
+		return p.index, nil
+	}
+	plannedResources, err := utils.GetJSON(`.planned_values | .. | objects | select(has("resources")) | .resources[]`, *p.plan)
+	if err != nil {
+		return nil, err
+	}
+	index := plannedIndex{"type": {}, "address": {}}
+	for _, resourceI := range plannedResources {
+		resource, ok := resourceI.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for field, values := range index {
+			if value, ok := resource[field].(string); ok {
+				values[value] = append(values[value], resource)
+			}
+		}
+	}
+	p.index = index
+	return index, nil
+}
+
+// selectPlannedResources answers a cbf::all_select query from the index of planned resources.
+// It returns false if the query cannot be answered from the index.
+func (p *tfPlanData) selectPlannedResources(query string) ([]interface{}, bool, error) {
+	match := allSelectRegexp.FindStringSubmatch(query)
+	if match == nil {
+		return nil, false, nil
+	}
+	index, err := p.getPlannedIndex()
+	if err != nil {
+		return nil, true, err
+	}
+	selected := index[match[1]][match[2]]
+	if match[3] == "" {
+		return selected, true, nil
+	}
+	results := []interface{}{}
+	for _, resource := range selected {
+		resourceResults, err := utils.GetJSON(match[3], resource)
+		if err != nil {
+			return nil, true, err
+		}
+		results = append(results, resourceResults...)
+	}
+	return results, true, nil
+}
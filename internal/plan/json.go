@@ -6,10 +6,14 @@ import (
 	"github.com/carboniferio/carbonifer/internal/utils"
 )
 
-func getJSON(query string, json interface{}) ([]interface{}, error) {
+// getJSON runs a jq query on json, or on the plan for the queries selecting resources of the plan
+func (p *tfPlanData) getJSON(query string, json interface{}) ([]interface{}, error) {
 
 	if strings.Contains(query, "all_select(") {
-		results, err := utils.GetJSON(query, *TfPlan)
+		results, indexed, err := p.selectPlannedResources(query)
+		if !indexed {
+			results, err = utils.GetJSON(query, *p.plan)
+		}
 		if len(results) > 0 && err == nil {
 			return results, nil
 		}
@@ -17,7 +21,7 @@ func getJSON(query string, json interface{}) ([]interface{}, error) {
 	}
 
 	if strings.HasPrefix(query, ".configuration") || strings.HasPrefix(query, ".prior_state") || strings.HasPrefix(query, ".planned_values") {
-		results, err := utils.GetJSON(query, *TfPlan)
+		results, err := utils.GetJSON(query, *p.plan)
 		if len(results) > 0 && err == nil {
 			return results, nil
 		}
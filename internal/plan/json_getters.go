@@ -6,10 +6,10 @@ import (
 	"strings"
 
 	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
 	"github.com/carboniferio/carbonifer/internal/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
 )
 
 // tfContext is the context of a terraform resource
@@ -19,6 +19,46 @@ type tfContext struct {
 	ResourceAddress string                 // Address of the resource in tf plan
 	RootContext     *tfContext             // Root context
 	Provider        providers.Provider
+	Trace           *[]resources.PropertySource // If set on root context, records the path of each value found
+	Plan            *tfPlanData                 // Plan of the resource, set on root context
+}
+
+// getJSON runs a jq query on json, or on the plan of the resource (cf tfPlanData.getJSON)
+func (context *tfContext) getJSON(query string, json interface{}) ([]interface{}, error) {
+	return context.RootContext.Plan.getJSON(query, json)
+}
+
+// recordSource records the mapping path that produced a value, if tracing is enabled
+func recordSource(key string, path string, propertyMapping *PropertyDefinition, value interface{}, context *tfContext) {
+	if context.RootContext == nil || context.RootContext.Trace == nil {
+		return
+	}
+	property := strings.TrimPrefix(context.ResourceAddress, context.RootContext.ResourceAddress)
+	property = strings.TrimPrefix(property+"."+key, ".")
+	reference := ""
+	if propertyMapping != nil && propertyMapping.Reference != nil {
+		switch {
+		case propertyMapping.Reference.JSONFile != "":
+			reference = propertyMapping.Reference.JSONFile
+		case propertyMapping.Reference.General != "":
+			reference = "general." + propertyMapping.Reference.General
+		default:
+			reference = strings.Join(propertyMapping.Reference.Paths, " , ")
+		}
+	}
+	source := resources.PropertySource{
+		Property:  property,
+		Path:      path,
+		Reference: reference,
+		Value:     fmt.Sprintf("%v", value),
+	}
+	// Variables are read each time they are used
+	for _, existing := range *context.RootContext.Trace {
+		if existing == source {
+			return
+		}
+	}
+	*context.RootContext.Trace = append(*context.RootContext.Trace, source)
 }
 
 func getString(key string, context *tfContext) (*string, error) {
@@ -90,7 +130,7 @@ func getSliceItems(context tfContext) ([]interface{}, error) {
 				return nil, err
 			}
 		}
-		jsonResults, err := getJSON(path, context.Resource)
+		jsonResults, err := context.getJSON(path, context.Resource)
 		if err != nil {
 			return nil, errors.Wrapf(err, "Cannot get item: %v", path)
 		}
@@ -200,6 +240,7 @@ func getValue(key string, context *tfContext) (*valueWithUnit, error) {
 			return nil, errors.Wrapf(err, "Cannot get paths for %v", context.ResourceAddress)
 		}
 		unit := propertyMapping.Unit
+		foundPath := ""
 
 		for _, pathRaw := range paths {
 			if valueFound != nil && valueFound != ".not_found" {
@@ -212,7 +253,7 @@ func getValue(key string, context *tfContext) (*valueWithUnit, error) {
 					return nil, errors.Wrapf(err, "Cannot resolve placeholders for %v", path)
 				}
 			}
-			valueFounds, err := getJSON(path, context.Resource)
+			valueFounds, err := context.getJSON(path, context.Resource)
 			if err != nil {
 				return nil, errors.Wrapf(err, "Cannot get value for %v", path)
 			}
@@ -224,6 +265,7 @@ func getValue(key string, context *tfContext) (*valueWithUnit, error) {
 					continue
 				}
 				valueFound = valueFounds[0]
+				foundPath = path
 			}
 		}
 
@@ -259,6 +301,7 @@ func getValue(key string, context *tfContext) (*valueWithUnit, error) {
 		}
 
 		if valueFound != nil {
+			recordSource(key, foundPath, &propertyMapping, valueFound, context)
 			return &valueWithUnit{
 				Value: valueFound,
 				Unit:  unit,
@@ -341,7 +384,7 @@ func resolvePlaceholder(expression string, context *tfContext) (*string, error)
 		return &valueStr, err
 	} else if strings.HasPrefix(expression, "config.") {
 		configProperty := strings.TrimPrefix(expression, "config.")
-		value := viper.GetFloat64(configProperty)
+		value := context.RootContext.Plan.config.GetFloat64(configProperty)
 		valueStr := fmt.Sprintf("%v", value)
 		return &valueStr, nil
 	}
@@ -389,6 +432,7 @@ func getDefaultValue(key string, context *tfContext) (*valueWithUnit, error) {
 			}
 
 			if valueFound != nil {
+				recordSource(key, "default", &propertyMapping, valueFound, context)
 				return &valueWithUnit{
 					Value: valueFound,
 					Unit:  unit,
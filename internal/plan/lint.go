@@ -0,0 +1,353 @@
+package plan
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/data"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/utils"
+	"github.com/pkg/errors"
+	"github.com/polkeli/yaml/v3"
+	"github.com/spf13/viper"
+)
+
+// MappingIssue is an issue found in a mapping file
+type MappingIssue struct {
+	File     string
+	Resource string `json:",omitempty"`
+	Property string `json:",omitempty"`
+	Message  string
+}
+
+func (i MappingIssue) String() string {
+	location := i.File
+	if i.Resource != "" {
+		location += ": " + i.Resource
+	}
+	if i.Property != "" {
+		location += "." + i.Property
+	}
+	return fmt.Sprintf("%v: %v", location, i.Message)
+}
+
+var placeholderRegex = regexp.MustCompile(`\${([^}]+)}`)
+
+// knownUnits are the units handled when reading memory and storage sizes
+var knownUnits = map[string]bool{"b": true, "kb": true, "mb": true, "gb": true, "tb": true, "pb": true}
+
+// LintMappings checks the mappings embedded in carbonifer
+func LintMappings() ([]MappingIssue, error) {
+	mappingsFS, err := fs.Sub(mappingFS, "mappings")
+	if err != nil {
+		return nil, err
+	}
+	return LintMappingsFS(mappingsFS)
+}
+
+// LintMappingsFS checks the mappings of a directory with a folder per provider: unknown keys,
+// jq paths that do not compile, unknown ${placeholders}, references to unknown data and invalid regexes
+func LintMappingsFS(mappingsFS fs.FS) ([]MappingIssue, error) {
+	folders, err := fs.ReadDir(mappingsFS, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read mappings")
+	}
+	issues := []MappingIssue{}
+	for _, folder := range folders {
+		if !folder.IsDir() {
+			continue
+		}
+		folderIssues, err := lintProviderMappings(mappingsFS, folder.Name())
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, folderIssues...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].File < issues[j].File
+	})
+	return issues, nil
+}
+
+type mappingLinter struct {
+	file     string
+	provider providers.Provider
+	general  GeneralConfig
+	issues   []MappingIssue
+}
+
+func lintProviderMappings(mappingsFS fs.FS, folder string) ([]MappingIssue, error) {
+	issues := []MappingIssue{}
+	provider, err := providers.ParseProvider(folder)
+	if err != nil {
+		return append(issues, MappingIssue{File: folder, Message: "folder is not a provider"}), nil
+	}
+	files, err := fs.ReadDir(mappingsFS, folder)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot read mappings of %v", folder)
+	}
+
+	// Read all files first, general config can be in any of them
+	mappingsByFile := map[string]Mappings{}
+	general := GeneralConfig{}
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		filename := path.Join(folder, file.Name())
+		content, err := fs.ReadFile(mappingsFS, filename)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot read mapping %v", filename)
+		}
+		var mappings Mappings
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&mappings); err != nil {
+			issues = append(issues, MappingIssue{File: filename, Message: err.Error()})
+			continue
+		}
+		mappingsByFile[filename] = mappings
+		if mappings.General != nil {
+			for generalProvider, generalConfig := range *mappings.General {
+				if generalProvider != provider {
+					issues = append(issues, MappingIssue{File: filename, Message: fmt.Sprintf("general config of %v in folder of %v", generalProvider, provider)})
+					continue
+				}
+				mergeGeneralConfig(&general, generalConfig)
+			}
+		}
+	}
+
+	filenames := make([]string, 0, len(mappingsByFile))
+	for filename := range mappingsByFile {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		linter := mappingLinter{
+			file:     filename,
+			provider: provider,
+			general:  general,
+		}
+		linter.lintGeneral(mappingsByFile[filename])
+		linter.lintComputeResources(mappingsByFile[filename])
+		issues = append(issues, linter.issues...)
+	}
+	return issues, nil
+}
+
+func mergeGeneralConfig(general *GeneralConfig, other GeneralConfig) {
+	if other.JSONData != nil {
+		general.JSONData = other.JSONData
+	}
+	if other.DiskTypes != nil {
+		general.DiskTypes = other.DiskTypes
+	}
+	if other.IgnoredResources != nil {
+		general.IgnoredResources = other.IgnoredResources
+	}
+}
+
+func (l *mappingLinter) addIssue(resource string, property string, format string, args ...interface{}) {
+	l.issues = append(l.issues, MappingIssue{
+		File:     l.file,
+		Resource: resource,
+		Property: property,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *mappingLinter) lintGeneral(mappings Mappings) {
+	if mappings.General == nil {
+		return
+	}
+	for _, generalConfig := range *mappings.General {
+		if generalConfig.JSONData != nil {
+			for key, filename := range *generalConfig.JSONData {
+				filenameStr, ok := filename.(string)
+				if !ok || !data.IsDataFile(filenameStr) {
+					l.addIssue("general", "json_data."+key, "unknown data file %v", filename)
+				}
+			}
+		}
+		if generalConfig.IgnoredResources != nil {
+			for _, ignoredResource := range *generalConfig.IgnoredResources {
+				if _, err := regexp.Compile(ignoredResource); err != nil {
+					l.addIssue("general", "ignored_resources", "invalid regex %v: %v", ignoredResource, err)
+				}
+			}
+		}
+	}
+}
+
+func (l *mappingLinter) lintComputeResources(mappings Mappings) {
+	if mappings.ComputeResource == nil {
+		return
+	}
+	resourceTypes := make([]string, 0, len(*mappings.ComputeResource))
+	for resourceType := range *mappings.ComputeResource {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+	for _, resourceType := range resourceTypes {
+		mapping := (*mappings.ComputeResource)[resourceType]
+		if len(mapping.Paths) == 0 {
+			l.addIssue(resourceType, "", "no paths")
+		}
+		variables := variableNames(&mapping, nil)
+		l.lintMapping(resourceType, "", &mapping, variables)
+	}
+}
+
+// variableNames returns the variables a mapping can use: its own and the ones of the root mapping
+func variableNames(mapping *ResourceMapping, rootVariables map[string]bool) map[string]bool {
+	variables := map[string]bool{}
+	for name := range rootVariables {
+		variables[name] = true
+	}
+	if mapping.Variables != nil && mapping.Variables.Properties != nil {
+		for name := range *mapping.Variables.Properties {
+			variables[name] = true
+		}
+	}
+	return variables
+}
+
+func (l *mappingLinter) lintMapping(resourceType string, prefix string, mapping *ResourceMapping, variables map[string]bool) {
+	l.lintPaths(resourceType, prefix+"paths", mapping.Paths, variables, false)
+	if mapping.Variables != nil {
+		l.lintMapping(resourceType, prefix+"variables.", mapping.Variables, variables)
+	}
+	if mapping.Properties == nil {
+		return
+	}
+	keys := make([]string, 0, len(*mapping.Properties))
+	for key := range *mapping.Properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for i, propertyDefinition := range (*mapping.Properties)[key] {
+			property := fmt.Sprintf("%v%v[%v]", prefix, key, i)
+			l.lintProperty(resourceType, property, &propertyDefinition, variables)
+		}
+	}
+}
+
+func (l *mappingLinter) lintProperty(resourceType string, property string, definition *PropertyDefinition, variables map[string]bool) {
+	l.lintPaths(resourceType, property+".paths", definition.Paths, variables, false)
+
+	if definition.Unit != nil && !knownUnits[strings.ToLower(*definition.Unit)] {
+		l.addIssue(resourceType, property, "unknown unit %v", *definition.Unit)
+	}
+	if definition.Validator != nil {
+		l.lintPaths(resourceType, property+".validator", []string{*definition.Validator}, variables, false)
+	}
+	if definition.Regex != nil {
+		regex, err := regexp.Compile(definition.Regex.Pattern)
+		if err != nil {
+			l.addIssue(resourceType, property, "invalid regex %v: %v", definition.Regex.Pattern, err)
+		} else if definition.Regex.Group > regex.NumSubexp() {
+			l.addIssue(resourceType, property, "regex %v has no group %v", definition.Regex.Pattern, definition.Regex.Group)
+		}
+	}
+	if definition.Reference != nil {
+		l.lintReference(resourceType, property+".reference", definition.Reference, variables)
+	}
+	if definition.ValueType != nil && *definition.ValueType == "list" && definition.Item == nil {
+		l.addIssue(resourceType, property, "list without item")
+	}
+	if definition.Item != nil {
+		for i, item := range *definition.Item {
+			l.lintMapping(resourceType, fmt.Sprintf("%v.item[%v].", property, i), &item, variableNames(&item, variables))
+		}
+	}
+}
+
+func (l *mappingLinter) lintReference(resourceType string, property string, reference *Reference, variables map[string]bool) {
+	if reference.JSONFile != "" {
+		if l.general.JSONData == nil {
+			l.addIssue(resourceType, property, "json_file %v but no general.json_data", reference.JSONFile)
+		} else if _, ok := (*l.general.JSONData)[reference.JSONFile]; !ok {
+			l.addIssue(resourceType, property, "json_file %v is not in general.json_data", reference.JSONFile)
+		}
+	}
+	if reference.General != "" {
+		if reference.General != "disk_types" {
+			l.addIssue(resourceType, property, "unknown general reference %v", reference.General)
+		} else if l.general.DiskTypes == nil || l.general.DiskTypes.Types == nil {
+			l.addIssue(resourceType, property, "general.disk_types is not defined")
+		}
+	}
+	if reference.Property != "" {
+		l.lintPaths(resourceType, property+".property", []string{reference.Property}, variables, false)
+	}
+	l.lintPaths(resourceType, property+".paths", reference.Paths, variables, true)
+}
+
+// lintPaths checks placeholders of jq paths, then compiles them with placeholders replaced
+func (l *mappingLinter) lintPaths(resourceType string, property string, paths []string, variables map[string]bool, keyAllowed bool) {
+	for _, jqPath := range paths {
+		for _, match := range placeholderRegex.FindAllStringSubmatch(jqPath, -1) {
+			expression := match[1]
+			switch {
+			case strings.HasPrefix(expression, "this."):
+			case strings.HasPrefix(expression, "config."):
+				configKey := strings.TrimPrefix(expression, "config.")
+				if !viper.IsSet(configKey) {
+					l.addIssue(resourceType, property, "unknown config %v", configKey)
+				}
+			case expression == "key":
+				if !keyAllowed {
+					l.addIssue(resourceType, property, "${key} is only available in reference paths")
+				}
+			default:
+				if !variables[expression] {
+					l.addIssue(resourceType, property, "unknown variable ${%v}", expression)
+				}
+			}
+		}
+		if err := utils.CompileJSONQuery(replacePlaceholders(jqPath)); err != nil {
+			l.addIssue(resourceType, property, "invalid jq path '%v': %v", jqPath, err)
+		}
+	}
+}
+
+// replacePlaceholders replaces placeholders by a value valid for jq: a string inside quotes, a path otherwise
+func replacePlaceholders(jqPath string) string {
+	builder := strings.Builder{}
+	last := 0
+	for _, indexes := range placeholderRegex.FindAllStringIndex(jqPath, -1) {
+		builder.WriteString(jqPath[last:indexes[0]])
+		if isInString(jqPath[:indexes[0]]) {
+			builder.WriteString("placeholder")
+		} else {
+			builder.WriteString(".placeholder")
+		}
+		last = indexes[1]
+	}
+	builder.WriteString(jqPath[last:])
+	return builder.String()
+}
+
+// isInString returns true if the end of a jq expression is inside a string literal
+func isInString(expression string) bool {
+	inString := false
+	escaped := false
+	for _, c := range expression {
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = inString
+		case c == '"':
+			inString = !inString
+		}
+	}
+	return inString
+}
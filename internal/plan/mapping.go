@@ -4,6 +4,7 @@ import (
 	"embed"
 	"io/fs"
 	"path/filepath"
+	"sync"
 
 	"github.com/carboniferio/carbonifer/internal/providers"
 	"github.com/polkeli/yaml/v3" // TODO use go-yaml https://github.com/go-yaml/yaml/issues/100#issuecomment-1632853107
@@ -12,31 +13,49 @@ import (
 
 // Mapping is the mapping of the terraform resources
 var globalMappings *Mappings
+var globalMappingsMutex sync.Mutex
 
 // GetMapping returns the mapping of the terraform resources
 func GetMapping() (*Mappings, error) {
+	globalMappingsMutex.Lock()
+	defer globalMappingsMutex.Unlock()
 	if globalMappings != nil {
 		return globalMappings, nil
 	}
-	err := loadMappings()
+	mappings, err := loadMappings()
 	if err != nil {
 		return nil, err
 	}
+	globalMappings = mappings
 	return globalMappings, nil
 }
 
+// LoadMappings returns the embedded mappings with the mappings of the files added, without changing the mappings in use
+func LoadMappings(files ...string) (*Mappings, error) {
+	mappings, err := loadMappings()
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if _, err := addMappingFile(mappings, file); err != nil {
+			return nil, err
+		}
+	}
+	return mappings, nil
+}
+
 //go:embed mappings/*
 var mappingFS embed.FS
 
-func loadMappings() error {
-	globalMappings = &Mappings{
+func loadMappings() (*Mappings, error) {
+	mappings := &Mappings{
 		General:         &map[providers.Provider]GeneralConfig{},
 		ComputeResource: &map[string]ResourceMapping{},
 	}
 	mappingsPath := "mappings"
 	files, err := fs.ReadDir(mappingFS, mappingsPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Iterate over each entry
@@ -47,16 +66,16 @@ func loadMappings() error {
 			relativePath := filepath.Join(mappingsPath, file.Name())
 
 			// Process the subfolder
-			err := loadMapping(relativePath)
+			err := loadMapping(mappings, relativePath)
 			if err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
-	return nil
+	return mappings, nil
 }
 
-func loadMapping(providerMappingFolder string) error {
+func loadMapping(mappings *Mappings, providerMappingFolder string) error {
 	files, err := fs.ReadDir(mappingFS, providerMappingFolder)
 	if err != nil {
 		return err
@@ -95,8 +114,8 @@ func loadMapping(providerMappingFolder string) error {
 
 	}
 
-	maps.Copy(*globalMappings.General, *mergedMappings.General)
-	maps.Copy(*globalMappings.ComputeResource, *mergedMappings.ComputeResource)
+	maps.Copy(*mappings.General, *mergedMappings.General)
+	maps.Copy(*mappings.ComputeResource, *mergedMappings.ComputeResource)
 
 	return nil
 }
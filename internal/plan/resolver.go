@@ -1,7 +1,6 @@
 package plan
 
 import (
-	"encoding/json"
 	"fmt"
 	"regexp"
 
@@ -29,17 +28,15 @@ func applyReference(valueFound string, propertyMapping *PropertyDefinition, cont
 }
 
 func resolveReference(key string, reference *Reference, context *tfContext) (interface{}, error) {
-	generalMappings := (*globalMappings.General)[context.Provider]
+	generalMappings := (*context.RootContext.Plan.mappings.General)[context.Provider]
 	if reference.JSONFile != "" {
 		filename, ok := (*generalMappings.JSONData)[reference.JSONFile]
 		if !ok {
-			log.Fatalf("Cannot find file %v in general.json_data", reference.JSONFile)
+			return nil, errors.Errorf("Cannot find file %v in general.json_data", reference.JSONFile)
 		}
-		byteValue := data.ReadDataFile(filename.(string))
-		var fileMap map[string]interface{}
-		err := json.Unmarshal([]byte(byteValue), &fileMap)
+		fileMap, err := data.LoadJSON[map[string]interface{}](context.RootContext.Plan.config.GetString("data.path"), filename.(string))
 		if err != nil {
-			log.Fatal(err)
+			return nil, err
 		}
 		item, ok := fileMap[key]
 		if !ok {
@@ -51,7 +48,8 @@ func resolveReference(key string, reference *Reference, context *tfContext) (int
 		var value interface{}
 		property := reference.Property
 		if property != "" {
-			valueArray, err := utils.GetJSON(reference.Property, item)
+			// Data files are shared by the resources read in parallel, the item is copied before being queried
+			valueArray, err := utils.GetJSON(reference.Property, utils.CopyJSON(item))
 			if err != nil {
 				return nil, errors.Wrapf(err, "Cannot find property %v in file %v", reference.Property, reference.JSONFile)
 			}
@@ -87,7 +85,7 @@ func resolveReference(key string, reference *Reference, context *tfContext) (int
 			return nil, err
 		}
 		for _, path := range paths {
-			referencedItems, err := getJSON(path, *TfPlan)
+			referencedItems, err := context.getJSON(path, *context.RootContext.Plan.plan)
 			if err != nil {
 				errW := errors.Wrapf(err, "Cannot find referenced path in terraform plan: '%v'", path)
 				return nil, errW
@@ -150,6 +148,6 @@ func applyValidator(valueFound interface{}, propertyMapping *PropertyDefinition,
 }
 
 func resolveValidator(value interface{}, validator *string, context *tfContext) error {
-	_, err := getJSON(*validator, value)
+	_, err := context.getJSON(*validator, value)
 	return errors.Wrapf(err, "Cannot validate '%v' value of %v", value, context.ResourceAddress)
 }
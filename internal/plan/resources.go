@@ -11,22 +11,28 @@ import (
 	"github.com/carboniferio/carbonifer/internal/utils"
 	"github.com/pkg/errors"
 	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
 
 	log "github.com/sirupsen/logrus"
 )
 
-// TfPlan is the Terraform plan
-var TfPlan *map[string]interface{}
-
-// GetResources returns the resources of the Terraform plan
-func GetResources(tfplan *map[string]interface{}) (map[string]resources.Resource, error) {
-	TfPlan = tfplan
+// GetResources returns the resources of the Terraform plan, read with the mappings (nil for the mappings in use, see
+// GetMapping) and config. Resources that cannot be read are left out and returned as resources.ResourceErrors, along
+// with the other resources.
+func GetResources(config *viper.Viper, mappings *Mappings, tfplan *map[string]interface{}) (map[string]resources.Resource, error) {
+	if tfplan == nil {
+		return nil, errors.New("No terraform plan")
+	}
+	planData, err := newTfPlanData(config, mappings, tfplan)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get mapping")
+	}
 
 	plannedResources := []interface{}{}
 
 	// Get resources from Terraform plan
 	jqPath := ".planned_values | .. | objects | select(has(\"resources\")) | .resources[]"
-	plannedResourcesResult, err := utils.GetJSON(jqPath, *TfPlan)
+	plannedResourcesResult, err := utils.GetJSON(jqPath, *planData.plan)
 
 	if err != nil {
 		return nil, err
@@ -43,21 +49,23 @@ func GetResources(tfplan *map[string]interface{}) (map[string]resources.Resource
 
 	// Get compute resources
 	resourcesMap := map[string]resources.Resource{}
-	mapping, err := GetMapping()
+	resourceErrors := resources.ResourceErrors{}
+	resourceTypes := []string{}
+	for resourceType := range *planData.mappings.ComputeResource {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+	computeResources, computeErrors, err := getResourcesOfTypes(planData, resourceTypes)
 	if err != nil {
-		errW := errors.Wrap(err, "Cannot get mapping")
-		return nil, errW
+		return nil, err
 	}
-	for resourceType, mapping := range *mapping.ComputeResource {
-		resources, err := getResourcesOfType(resourceType, &mapping)
-		if err != nil {
-			errW := errors.Wrapf(err, "Cannot get resources of type %v", resourceType)
-			return nil, errW
-		}
-
-		for _, resource := range resources {
-			resourcesMap[resource.GetAddress()] = resource
-		}
+	for _, resource := range computeResources {
+		resourcesMap[resource.GetAddress()] = resource
+	}
+	resourceErrors = append(resourceErrors, computeErrors...)
+	failed := map[string]bool{}
+	for _, resourceError := range resourceErrors {
+		failed[resourceError.Address] = true
 	}
 
 	// Get resource not in mapping
@@ -70,10 +78,10 @@ func GetResources(tfplan *map[string]interface{}) (map[string]resources.Resource
 		if err != nil {
 			continue
 		}
-		if resourceMap == nil {
+		if resourceMap == nil && !failed[resourceAddress] {
 			// That is an unsupported resource
 			resourceType := resource["type"].(string)
-			if checkIgnoredResource(resourceType, provider) {
+			if checkIgnoredResource(planData.mappings, resourceType, provider) {
 				continue
 			}
 			unsupportedResource := resources.UnsupportedResource{
@@ -89,11 +97,70 @@ func GetResources(tfplan *map[string]interface{}) (map[string]resources.Resource
 		}
 	}
 
+	if len(resourceErrors) > 0 {
+		resources.SortResourceErrors(resourceErrors)
+		return resourcesMap, resourceErrors
+	}
 	return resourcesMap, nil
 }
 
-func checkIgnoredResource(resourceType string, provider providers.Provider) bool {
-	ignoredResourceNames := (*globalMappings.General)[provider].IgnoredResources
+// ExplainResource returns the resource of the Terraform plan at the given address,
+// with the mapping paths that produced each of its properties (see GetResources for mappings and config)
+func ExplainResource(config *viper.Viper, mappings *Mappings, tfplan *map[string]interface{}, address string) (resources.Resource, []resources.PropertySource, error) {
+	allResources, err := GetResources(config, mappings, tfplan)
+	resourceErrors, err := resources.SplitResourceErrors(err)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, resourceError := range resourceErrors {
+		if resourceError.Address == address {
+			return nil, nil, resourceError
+		}
+	}
+	resource, ok := allResources[address]
+	if !ok {
+		return nil, nil, errors.Errorf("Resource not found in terraform plan: %v", address)
+	}
+	if !resource.IsSupported() {
+		return resource, nil, nil
+	}
+
+	// Read the resource again, tracing the mapping paths
+	planData, err := newTfPlanData(config, mappings, tfplan)
+	if err != nil {
+		return nil, nil, err
+	}
+	for resourceType, mapping := range *planData.mappings.ComputeResource {
+		paths, err := readPaths(mapping.Paths)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "Cannot read paths of resource type %v", resourceType)
+		}
+		for _, path := range paths {
+			resourcesFound, err := planData.getJSON(path, *planData.plan)
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "Cannot find resource for path %v", path)
+			}
+			for _, resourceI := range resourcesFound {
+				resourceFound, ok := resourceI.(map[string]interface{})
+				if !ok || resourceFound["address"] != address {
+					continue
+				}
+				trace := []resources.PropertySource{}
+				resourcesResult, err := getComputeResource(planData, resourceI, &mapping, []resources.Resource{}, &trace)
+				if err != nil {
+					return nil, nil, errors.Wrapf(err, "Cannot get compute resource %v", address)
+				}
+				if len(resourcesResult) > 0 {
+					return resourcesResult[0], trace, nil
+				}
+			}
+		}
+	}
+	return resource, nil, nil
+}
+
+func checkIgnoredResource(mappings *Mappings, resourceType string, provider providers.Provider) bool {
+	ignoredResourceNames := (*mappings.General)[provider].IgnoredResources
 	if ignoredResourceNames != nil {
 		for _, ignoredResource := range *ignoredResourceNames {
 			if ignoredResource == resourceType {
@@ -110,40 +177,120 @@ func checkIgnoredResource(resourceType string, provider providers.Provider) bool
 	}
 	return false
 }
-func getResourcesOfType(resourceType string, mapping *ResourceMapping) ([]resources.Resource, error) {
-	pathsProperty := mapping.Paths
-	paths, err := readPaths(pathsProperty)
-	if err != nil {
-		errW := errors.Wrapf(err, "Cannot read paths of resource type %v", resourceType)
-		return nil, errW
-	}
 
-	resourcesResult := []resources.Resource{}
-	for _, path := range paths {
-		log.Debugf("  Reading resources of type '%s' from path '%s'", resourceType, path)
-		resourcesFound, err := getJSON(path, *TfPlan)
+// getResourcesOfType returns the resources of a type, and the errors of the resources of this type that cannot be read
+// resourceJob is the resource at index of the resources found for a type
+type resourceJob struct {
+	resourceType string
+	typeIndex    int
+	index        int
+}
+
+type resourceJobResult struct {
+	resources []resources.Resource
+	err       *resources.ResourceError
+}
+
+// resourcesWorker reads resources from its own copy of the plan, as gojq writes into the data it queries
+type resourcesWorker struct {
+	plan           *tfPlanData
+	resourcesFound map[int][]interface{}
+}
+
+// getResourcesOfTypes reads the resources of the given types, at most utils.Parallelism() at once. Resources and
+// errors are returned in the order of the types, then of the resources in the plan.
+func getResourcesOfTypes(planData *tfPlanData, resourceTypes []string) ([]resources.Resource, []resources.ResourceError, error) {
+	typeMappings := make([]ResourceMapping, len(resourceTypes))
+	jobs := []resourceJob{}
+	for typeIndex, resourceType := range resourceTypes {
+		typeMappings[typeIndex] = (*planData.mappings.ComputeResource)[resourceType]
+		resourcesFound, err := planData.findResourcesOfType(resourceType, &typeMappings[typeIndex])
 		if err != nil {
-			errW := errors.Wrapf(err, "Cannot find resource for path %v", path)
-			return nil, errW
+			return nil, nil, &MappingError{ResourceType: resourceType, ParentError: err}
+		}
+		for index := range resourcesFound {
+			jobs = append(jobs, resourceJob{resourceType: resourceType, typeIndex: typeIndex, index: index})
+		}
+	}
+
+	workers := 0
+	newWorker := func() *resourcesWorker {
+		// The first worker reads the plan itself, the others a copy of it
+		workerPlan := planData
+		if workers > 0 {
+			workerPlan = planData.copy()
 		}
-		log.Debugf("  Found %d resources of type '%s'", len(resourcesFound), resourceType)
-		for _, resourceI := range resourcesFound {
-			resourcesResultGot, err := GetComputeResource(resourceI, mapping, resourcesResult)
+		workers++
+		return &resourcesWorker{plan: workerPlan, resourcesFound: map[int][]interface{}{}}
+	}
+	results := utils.ParallelMapWorkers(jobs, newWorker, func(worker *resourcesWorker, job resourceJob) resourceJobResult {
+		mapping := &typeMappings[job.typeIndex]
+		resourcesFound, ok := worker.resourcesFound[job.typeIndex]
+		if !ok {
+			var err error
+			resourcesFound, err = worker.plan.findResourcesOfType(job.resourceType, mapping)
 			if err != nil {
-				errW := errors.Wrapf(err, "Cannot get compute resource for path %v", path)
-				return nil, errW
-			}
-			if resourcesResultGot != nil {
-				resourcesResult = resourcesResultGot
+				return resourceJobResult{err: &resources.ResourceError{Err: &MappingError{ResourceType: job.resourceType, ParentError: err}}}
 			}
+			worker.resourcesFound[job.typeIndex] = resourcesFound
+		}
+		resourceI := resourcesFound[job.index]
+		resourcesResult, err := getComputeResource(worker.plan, resourceI, mapping, []resources.Resource{}, nil)
+		if err != nil {
+			resource, _ := resourceI.(map[string]interface{})
+			address, _ := resource["address"].(string)
+			log.Warnf("Cannot read resource %v: %v", address, err)
+			return resourceJobResult{err: &resources.ResourceError{
+				Address: address,
+				Err:     &MappingError{ResourceType: job.resourceType, ParentError: err},
+			}}
+		}
+		return resourceJobResult{resources: resourcesResult}
+	})
 
+	resourcesResult := []resources.Resource{}
+	resourceErrors := []resources.ResourceError{}
+	for _, result := range results {
+		if result.err != nil {
+			resourceErrors = append(resourceErrors, *result.err)
+			continue
 		}
+		resourcesResult = append(resourcesResult, result.resources...)
+	}
+	return resourcesResult, resourceErrors, nil
+}
+
+// findResourcesOfType returns the resources of the plan matching the paths of the mapping of a resource type
+func (p *tfPlanData) findResourcesOfType(resourceType string, mapping *ResourceMapping) ([]interface{}, error) {
+	paths, err := readPaths(mapping.Paths)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot read paths of resource type %v", resourceType)
 	}
-	return resourcesResult, nil
 
+	resourcesFound := []interface{}{}
+	for _, path := range paths {
+		log.Debugf("  Reading resources of type '%s' from path '%s'", resourceType, path)
+		resourcesOfPath, err := p.getJSON(path, *p.plan)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot find resource for path %v", path)
+		}
+		log.Debugf("  Found %d resources of type '%s'", len(resourcesOfPath), resourceType)
+		resourcesFound = append(resourcesFound, resourcesOfPath...)
+	}
+	return resourcesFound, nil
 }
 
-func GetComputeResource(resourceI interface{}, resourceMapping *ResourceMapping, resourcesResult []resources.Resource) ([]resources.Resource, error) {
+// GetComputeResource reads a compute resource from a resource of a terraform plan with its mapping and appends it to
+// resourcesResult (see GetResources for mappings and config)
+func GetComputeResource(config *viper.Viper, mappings *Mappings, tfplan *map[string]interface{}, resourceI interface{}, resourceMapping *ResourceMapping, resourcesResult []resources.Resource) ([]resources.Resource, error) {
+	planData, err := newTfPlanData(config, mappings, tfplan)
+	if err != nil {
+		return nil, err
+	}
+	return getComputeResource(planData, resourceI, resourceMapping, resourcesResult, nil)
+}
+
+func getComputeResource(planData *tfPlanData, resourceI interface{}, resourceMapping *ResourceMapping, resourcesResult []resources.Resource, trace *[]resources.PropertySource) ([]resources.Resource, error) {
 	resource := resourceI.(map[string]interface{})
 	resourceAddress := resource["address"].(string)
 	providerName, ok := resource["provider_name"].(string)
@@ -159,6 +306,8 @@ func GetComputeResource(resourceI interface{}, resourceMapping *ResourceMapping,
 		Mapping:         resourceMapping,
 		Resource:        resource,
 		Provider:        provider,
+		Trace:           trace,
+		Plan:            planData,
 	}
 	contextObject.RootContext = &contextObject
 	context := &contextObject
@@ -171,7 +320,10 @@ func GetComputeResource(resourceI interface{}, resourceMapping *ResourceMapping,
 		return nil, errors.Wrapf(err, "Cannot get region for resource %v", resourceAddress)
 	}
 	if region == nil {
-		region = getDefaultRegion()
+		region, err = getDefaultRegion()
+		if err != nil {
+			return nil, errors.Wrapf(err, "Cannot get default region for resource %v", resourceAddress)
+		}
 		if region == nil {
 			return nil, errors.Errorf("Cannot find default region for resource %v", resourceAddress)
 		}
@@ -182,9 +334,13 @@ func GetComputeResource(resourceI interface{}, resourceMapping *ResourceMapping,
 		return nil, errors.Wrapf(err, "Cannot get type for resource %v", resourceAddress)
 	}
 
-	index := resource["index"]
-	if index != nil {
-		nameStr := fmt.Sprintf("%s[%d]", *name, int(index.(float64)))
+	switch index := resource["index"].(type) {
+	case float64:
+		nameStr := fmt.Sprintf("%s[%d]", *name, int(index))
+		name = &nameStr
+	case string:
+		// for_each key
+		nameStr := fmt.Sprintf("%s[%q]", *name, index)
 		name = &nameStr
 	}
 
@@ -243,7 +399,7 @@ func GetComputeResource(resourceI interface{}, resourceMapping *ResourceMapping,
 		case "b":
 			computeResource.Specs.MemoryMb /= 1024 * 1024
 		default:
-			log.Fatalf("Unknown unit for memory: %v", unit)
+			return nil, errors.Errorf("Unknown unit for memory of %v: %v", resourceAddress, unit)
 		}
 	}
 
@@ -270,6 +426,15 @@ func GetComputeResource(resourceI interface{}, resourceMapping *ResourceMapping,
 		computeResource.Specs.CPUType = *cpuType
 	}
 
+	// Add machine type
+	machineType, err := getString("machine_type", context)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Cannot get machine type for %v", resourceAddress)
+	}
+	if machineType != nil {
+		computeResource.Specs.MachineType = *machineType
+	}
+
 	// Add replication factor
 	replicationFactor, err := getValue("replication_factor", context)
 	if err != nil {
@@ -414,9 +579,9 @@ func getStorage(storageMap map[string]interface{}) (*storage, error) {
 	}
 	storageSizeGb, err := decimal.NewFromString(fmt.Sprintf("%v", storageSize.Value))
 	if err != nil {
-		log.Fatal(err)
+		return nil, errors.Wrapf(err, "Cannot parse storage size '%v'", storageSize.Value)
 	}
-	storageType := storageMap["type"].(*valueWithUnit)
+	storageType, _ := storageMap["type"].(*valueWithUnit)
 	// TODO get storage size unit correctly
 	unit := storageSize.Unit
 	if unit != nil {
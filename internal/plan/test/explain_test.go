@@ -0,0 +1,42 @@
+package plan_test
+
+import (
+	"github.com/spf13/viper"
+	"path"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+	_ "github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainResource(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+
+	tfPlan, err := terraform.CarboniferPlan(path.Join(testutils.RootDir, "test/terraform/planDelta/plan.json"))
+	assert.NoError(t, err)
+
+	resource, sources, err := plan.ExplainResource(viper.GetViper(), nil, tfPlan, "google_compute_instance.resized")
+	assert.NoError(t, err)
+	computeResource := resource.(resources.ComputeResource)
+	assert.Equal(t, int32(4), computeResource.Specs.VCPUs)
+
+	sourcesByProperty := map[string]resources.PropertySource{}
+	for _, source := range sources {
+		sourcesByProperty[source.Property] = source
+	}
+	assert.Equal(t, resources.PropertySource{
+		Property:  "vCPUs",
+		Path:      ".values.machine_type",
+		Reference: "gcp_machines_types",
+		Value:     "4",
+	}, sourcesByProperty["vCPUs"])
+	assert.Equal(t, "default", sourcesByProperty["replication_factor"].Path)
+
+	_, _, err = plan.ExplainResource(viper.GetViper(), nil, tfPlan, "google_compute_instance.unknown")
+	assert.Error(t, err)
+}
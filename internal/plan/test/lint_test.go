@@ -0,0 +1,127 @@
+package plan_test
+
+import (
+	"github.com/spf13/viper"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintMappings(t *testing.T) {
+	issues, err := plan.LintMappings()
+	assert.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestLintMappingsFS(t *testing.T) {
+	mappingsFS := fstest.MapFS{
+		"gcp/general.yaml": {Data: []byte(`
+general:
+  gcp:
+    json_data:
+      gcp_machines_types: "gcp_instances.json"
+      unknown_data: "unknown.json"
+`)},
+		"gcp/typo.yaml": {Data: []byte(`
+compute_resource:
+  google_compute_instance:
+    paths: 'cbf::all_select("type";  "google_compute_instance")'
+    properties:
+      name:
+        - pahts: ".name"
+`)},
+		"gcp/compute.yaml": {Data: []byte(`
+compute_resource:
+  google_compute_instance:
+    paths: 'cbf::all_select("type";  "google_compute_instance")'
+    variables:
+      properties:
+        template:
+          - paths: '.configuration.resources[] | select(.address == "${this.address}")'
+    properties:
+      vCPUs:
+        - paths: "${tempalte}.values.machine_type"
+          reference:
+            json_file: gcp_machine_types
+            property: ".vcpus"
+      memory:
+        - paths: "${template}.values.machine_type | ("
+          unit: mib
+      count:
+        - paths: ".values | ${config.provider.gcp.unknown}"
+      region:
+        - paths: ".values.zone"
+          regex:
+            pattern: "^(.+)-[a-z]+$"
+            group: 2
+      storage:
+        - value_type: list
+          item:
+            - paths: "${template}.values.disk"
+              properties:
+                type:
+                  - paths: ".type"
+                    reference:
+                      general: disk_types
+`)},
+	}
+	issues, err := plan.LintMappingsFS(mappingsFS)
+	assert.NoError(t, err)
+
+	messages := []string{}
+	for _, issue := range issues {
+		messages = append(messages, issue.String())
+	}
+	assert.ElementsMatch(t, []string{
+		"gcp/compute.yaml: google_compute_instance.count[0].paths: unknown config provider.gcp.unknown",
+		"gcp/compute.yaml: google_compute_instance.memory[0].paths: invalid jq path '${template}.values.machine_type | (': unexpected EOF",
+		"gcp/compute.yaml: google_compute_instance.memory[0]: unknown unit mib",
+		"gcp/compute.yaml: google_compute_instance.region[0]: regex ^(.+)-[a-z]+$ has no group 2",
+		"gcp/compute.yaml: google_compute_instance.storage[0].item[0].type[0].reference: general.disk_types is not defined",
+		"gcp/compute.yaml: google_compute_instance.vCPUs[0].paths: unknown variable ${tempalte}",
+		"gcp/compute.yaml: google_compute_instance.vCPUs[0].reference: json_file gcp_machine_types is not in general.json_data",
+		"gcp/general.yaml: general.json_data.unknown_data: unknown data file unknown.json",
+		"gcp/typo.yaml: yaml: unmarshal errors:\n  line 7: field pahts not found in type plan.PropertyDefinition",
+	}, messages)
+}
+
+func TestCheckMappings(t *testing.T) {
+	terraform.ResetTerraformExec()
+	tfPlan, err := terraform.CarboniferPlan(path.Join(testutils.RootDir, "test/terraform/planDelta/plan.json"))
+	assert.NoError(t, err)
+
+	expected := map[string]interface{}{
+		"google_compute_instance.unchanged": map[string]interface{}{
+			"Identification": map[string]interface{}{
+				"Region": "europe-west9",
+			},
+			"Specs": map[string]interface{}{
+				"MachineType": "e2-standard-2",
+				"VCPUs":       2,
+				"MemoryMb":    8192,
+			},
+		},
+		"google_compute_instance.resized": map[string]interface{}{
+			"Specs": map[string]interface{}{
+				"VCPUs":    2,
+				"GpuTypes": []interface{}{"nvidia-tesla-k80"},
+			},
+		},
+		"google_compute_instance.missing": map[string]interface{}{},
+	}
+	differences, err := plan.CheckMappings(viper.GetViper(), tfPlan, []string{"google_compute_instance"}, expected)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"google_compute_instance.missing: resource not found",
+		"google_compute_instance.resized.Specs.GpuTypes: expected [nvidia-tesla-k80], got <nil>",
+		"google_compute_instance.resized.Specs.VCPUs: expected 2, got 4",
+	}, differences)
+
+	_, err = plan.CheckMappings(viper.GetViper(), tfPlan, []string{"google_unknown"}, expected)
+	assert.Error(t, err)
+}
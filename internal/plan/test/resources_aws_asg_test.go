@@ -36,8 +36,9 @@ func TestGetResource_AWSASG(t *testing.T) {
 				ReplicationFactor: 1,
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(4),
-				MemoryMb: int32(16384),
+				VCPUs:       int32(4),
+				MachineType: "m5d.xlarge",
+				MemoryMb:    int32(16384),
 
 				HddStorage: decimal.Zero,
 				SsdStorage: decimal.NewFromInt(180),
@@ -54,8 +55,9 @@ func TestGetResource_AWSASG(t *testing.T) {
 				ReplicationFactor: 1,
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(4),
-				MemoryMb: int32(16384),
+				VCPUs:       int32(4),
+				MachineType: "m5d.xlarge",
+				MemoryMb:    int32(16384),
 
 				HddStorage: decimal.NewFromInt(300),
 				SsdStorage: decimal.NewFromInt(150),
@@ -64,7 +66,7 @@ func TestGetResource_AWSASG(t *testing.T) {
 	}
 	tfPlan, err := terraform.TerraformPlan()
 	assert.NoError(t, err)
-	gotResources, err := plan.GetResources(tfPlan)
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
 	assert.NoError(t, err)
 	for _, got := range gotResources {
 		if got.GetIdentification().ResourceType == "aws_launch_configuration" {
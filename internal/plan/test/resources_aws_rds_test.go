@@ -79,7 +79,7 @@ func TestGetResource_RDS(t *testing.T) {
 	}
 	tfPlan, err := terraform.TerraformPlan()
 	assert.NoError(t, err)
-	gotResources, err := plan.GetResources(tfPlan)
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
 	assert.NoError(t, err)
 	for _, got := range gotResources {
 		assert.Equal(t, wantResources[got.GetAddress()], got)
@@ -0,0 +1,92 @@
+package plan_test
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// awsPlanWithPriorState returns a plan of resources whose references are data sources, only found in the prior state
+func awsPlanWithPriorState(plannedResources []interface{}, configResources []interface{}, priorResources []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"planned_values": map[string]interface{}{"root_module": map[string]interface{}{"resources": plannedResources}},
+		"prior_state": map[string]interface{}{
+			"values": map[string]interface{}{"root_module": map[string]interface{}{"resources": priorResources}},
+		},
+		"configuration": map[string]interface{}{
+			"provider_config": map[string]interface{}{
+				"aws": map[string]interface{}{"name": "aws", "expressions": map[string]interface{}{"region": map[string]interface{}{"constant_value": "eu-west-3"}}},
+			},
+			"root_module": map[string]interface{}{"resources": configResources},
+		},
+	}
+}
+
+func TestGetResource_AWSLaunchTemplateFromPriorState(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+
+	launchTemplateReference := []interface{}{
+		map[string]interface{}{"id": map[string]interface{}{"references": []interface{}{"data.aws_launch_template.lt.id", "data.aws_launch_template.lt"}}},
+	}
+	tfPlan := awsPlanWithPriorState(
+		[]interface{}{
+			map[string]interface{}{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web", "provider_name": "registry.terraform.io/hashicorp/aws", "values": map[string]interface{}{"availability_zone": "eu-west-3a"}},
+			map[string]interface{}{"address": "aws_autoscaling_group.workers", "mode": "managed", "type": "aws_autoscaling_group", "name": "workers", "provider_name": "registry.terraform.io/hashicorp/aws", "values": map[string]interface{}{"min_size": 2, "max_size": 2}},
+		},
+		[]interface{}{
+			map[string]interface{}{"address": "aws_instance.web", "expressions": map[string]interface{}{"launch_template": launchTemplateReference}},
+			map[string]interface{}{"address": "aws_autoscaling_group.workers", "expressions": map[string]interface{}{"launch_template": launchTemplateReference}},
+		},
+		[]interface{}{
+			map[string]interface{}{"address": "data.aws_launch_template.lt", "mode": "data", "type": "aws_launch_template", "name": "lt", "values": map[string]interface{}{"instance_type": "m5.large", "image_id": "ami-0123456789"}},
+		},
+	)
+
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, &tfPlan)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, address := range []string{"aws_instance.web", "aws_autoscaling_group.workers"} {
+		specs := gotResources[address].(resources.ComputeResource).Specs
+		assert.Equal(t, "m5.large", specs.MachineType, address)
+		assert.Equal(t, int32(2), specs.VCPUs, address)
+		assert.Equal(t, int32(8192), specs.MemoryMb, address)
+	}
+	assert.Equal(t, "eu-west-3", gotResources["aws_autoscaling_group.workers"].GetIdentification().Region)
+	assert.Equal(t, int64(2), gotResources["aws_autoscaling_group.workers"].GetIdentification().Count)
+}
+
+func TestGetResource_AWSRDSReplicaOfPriorState(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+
+	tfPlan := awsPlanWithPriorState(
+		[]interface{}{
+			map[string]interface{}{"address": "aws_db_instance.replica", "mode": "managed", "type": "aws_db_instance", "name": "replica", "provider_name": "registry.terraform.io/hashicorp/aws", "values": map[string]interface{}{"availability_zone": "eu-west-3b", "instance_class": "db.m5.large"}},
+		},
+		[]interface{}{
+			map[string]interface{}{"address": "aws_db_instance.replica", "expressions": map[string]interface{}{
+				"replicate_source_db": map[string]interface{}{"references": []interface{}{"data.aws_db_instance.primary.id", "data.aws_db_instance.primary"}},
+			}},
+		},
+		[]interface{}{
+			map[string]interface{}{"address": "data.aws_db_instance.primary", "mode": "data", "type": "aws_db_instance", "name": "primary", "values": map[string]interface{}{"allocated_storage": 50, "storage_type": "gp2"}},
+		},
+	)
+
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, &tfPlan)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// Storage of the replica is the one of its source database
+	specs := gotResources["aws_db_instance.replica"].(resources.ComputeResource).Specs
+	assert.Equal(t, int32(2), specs.VCPUs)
+	assert.True(t, decimal.NewFromInt(50).Equal(specs.SsdStorage), specs.SsdStorage.String())
+	assert.True(t, specs.HddStorage.IsZero(), specs.HddStorage.String())
+}
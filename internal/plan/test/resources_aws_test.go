@@ -36,8 +36,9 @@ func TestGetResource_EC2(t *testing.T) {
 				ReplicationFactor: 1,
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(4),
-				MemoryMb: int32(16384),
+				VCPUs:       int32(4),
+				MachineType: "m5d.xlarge",
+				MemoryMb:    int32(16384),
 
 				HddStorage: decimal.NewFromInt(80),
 				SsdStorage: decimal.NewFromInt(330),
@@ -87,8 +88,9 @@ func TestGetResource_EC2(t *testing.T) {
 				ReplicationFactor: 1,
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(4),
-				MemoryMb: int32(16384),
+				VCPUs:       int32(4),
+				MachineType: "m5d.xlarge",
+				MemoryMb:    int32(16384),
 
 				HddStorage: decimal.Zero,
 				SsdStorage: decimal.NewFromInt(180),
@@ -105,8 +107,9 @@ func TestGetResource_EC2(t *testing.T) {
 				ReplicationFactor: 1,
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(4),
-				MemoryMb: int32(16384),
+				VCPUs:       int32(4),
+				MachineType: "m5d.xlarge",
+				MemoryMb:    int32(16384),
 
 				HddStorage: decimal.NewFromInt(300),
 				SsdStorage: decimal.NewFromInt(150),
@@ -115,7 +118,7 @@ func TestGetResource_EC2(t *testing.T) {
 	}
 	tfPlan, err := terraform.TerraformPlan()
 	assert.NoError(t, err)
-	gotResources, err := plan.GetResources(tfPlan)
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
 	assert.NoError(t, err)
 	for _, res := range gotResources {
 		assert.Equal(t, wantResources[res.GetAddress()], res)
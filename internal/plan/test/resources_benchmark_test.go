@@ -0,0 +1,34 @@
+package plan_test
+
+import (
+	"path"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// BenchmarkGetResources_GCPLarge measures the mapping of a large plan, read offline so that terraform is not needed
+func BenchmarkGetResources_GCPLarge(b *testing.B) {
+	terraform.ResetTerraformExec()
+	viper.Set("offline", true)
+	defer viper.Set("offline", false)
+	logLevel := log.GetLevel()
+	log.SetLevel(log.ErrorLevel)
+	defer log.SetLevel(logLevel)
+
+	tfPlan, err := terraform.CarboniferPlan(path.Join(testutils.RootDir, "test/terraform/gcp_large"))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := plan.GetResources(viper.GetViper(), nil, tfPlan); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
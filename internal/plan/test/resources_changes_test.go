@@ -0,0 +1,47 @@
+package plan_test
+
+import (
+	"github.com/spf13/viper"
+	"path"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+	_ "github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResourcesChanges(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+
+	tfPlan, err := terraform.CarboniferPlan(path.Join(testutils.RootDir, "test/terraform/planDelta/plan.json"))
+	assert.NoError(t, err)
+
+	changes, err := plan.GetResourcesChanges(viper.GetViper(), nil, tfPlan)
+	assert.NoError(t, err)
+
+	wantActions := map[string]string{
+		"google_compute_instance.resized":             plan.ActionUpdate,
+		"google_compute_instance.removed":             plan.ActionDelete,
+		"google_compute_instance.added":               plan.ActionCreate,
+		"google_compute_instance.unchanged":           plan.ActionNoOp,
+		"module.app.google_compute_instance.replaced": plan.ActionReplace,
+	}
+	assert.Equal(t, wantActions, changes.Actions)
+
+	assert.Len(t, changes.Before, 4)
+	assert.NotContains(t, changes.Before, "google_compute_instance.added")
+	assert.Len(t, changes.After, 4)
+	assert.NotContains(t, changes.After, "google_compute_instance.removed")
+
+	resizedBefore := changes.Before["google_compute_instance.resized"].(resources.ComputeResource)
+	resizedAfter := changes.After["google_compute_instance.resized"].(resources.ComputeResource)
+	assert.Equal(t, int32(2), resizedBefore.Specs.VCPUs)
+	assert.Equal(t, int32(4), resizedAfter.Specs.VCPUs)
+
+	replacedAfter := changes.After["module.app.google_compute_instance.replaced"].(resources.ComputeResource)
+	assert.Equal(t, "europe-west9", replacedAfter.Identification.Region)
+}
@@ -54,8 +54,9 @@ func TestGetResource_ChildModules(t *testing.T) {
 				Address:           "module.backend.module.middleware.module.api_ms.google_compute_instance.cbf-test-vm",
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(12),
-				MemoryMb: int32(87040),
+				VCPUs:       int32(12),
+				MachineType: "a2-highgpu-1g",
+				MemoryMb:    int32(87040),
 
 				HddStorage: decimal.NewFromInt(10),
 				SsdStorage: decimal.Zero,
@@ -72,8 +73,9 @@ func TestGetResource_ChildModules(t *testing.T) {
 				Address:           "module.backend.module.middleware.module.users_ms.google_compute_instance.cbf-test-vm",
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(2),
-				MemoryMb: int32(7680),
+				VCPUs:       int32(2),
+				MachineType: "n1-standard-2",
+				MemoryMb:    int32(7680),
 
 				HddStorage: decimal.NewFromInt(10),
 				SsdStorage: decimal.Zero,
@@ -100,7 +102,7 @@ func TestGetResource_ChildModules(t *testing.T) {
 	}
 	tfPlan, err := terraform.TerraformPlan()
 	assert.NoError(t, err)
-	gotResources, err := plan.GetResources(tfPlan)
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
 	assert.NoError(t, err)
 	for _, got := range gotResources {
 		assert.Equal(t, wantResources[got.GetAddress()], got)
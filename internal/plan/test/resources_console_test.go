@@ -0,0 +1,79 @@
+package plan_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeTerraform is a terraform exec whose console prints "eu-west-3" for var.region and "<expression>" for other
+// expressions, and logs the lines it reads
+const fakeTerraform = `#!/bin/sh
+if [ "$1" = "version" ]; then
+  echo '{"terraform_version": "1.5.7", "platform": "linux_amd64", "provider_selections": {}}'
+  exit 0
+fi
+read line
+echo "$line" >> "$(dirname "$0")/console.log"
+echo "["
+echo "$line" | sed -e 's/^\[//' -e 's/\]$//' -e 's/, jsonencode/\njsonencode/g' |
+  sed -e 's/^jsonencode(try(var.region, null))$/  "\\"eu-west-3\\"",/' \
+      -e 's/^jsonencode(try(\(.*\), null))$/  "\\"\1\\"",/'
+echo "]"
+`
+
+func TestGetResources_ConsoleReferences(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "terraform"), []byte(fakeTerraform), 0755)
+	assert.NoError(t, err)
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	workdir := viper.Get("workdir")
+	defer viper.Set("workdir", workdir)
+	viper.Set("workdir", dir)
+	terraform.ResetTerraformExec()
+	defer terraform.ResetTerraformExec()
+
+	// The region of the instances is only known from the provider config
+	tfPlan := map[string]interface{}{
+		"planned_values": map[string]interface{}{"root_module": map[string]interface{}{"resources": []interface{}{
+			map[string]interface{}{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web", "provider_name": "registry.terraform.io/hashicorp/aws", "values": map[string]interface{}{"instance_type": "t2.micro"}},
+			map[string]interface{}{"address": "aws_instance.api", "mode": "managed", "type": "aws_instance", "name": "api", "provider_name": "registry.terraform.io/hashicorp/aws", "values": map[string]interface{}{"instance_type": "t2.micro"}},
+		}}},
+		"configuration": map[string]interface{}{
+			"provider_config": map[string]interface{}{
+				"aws": map[string]interface{}{"name": "aws", "expressions": map[string]interface{}{"region": map[string]interface{}{"references": []interface{}{"var.region"}}}},
+			},
+			"root_module": map[string]interface{}{"resources": []interface{}{
+				map[string]interface{}{"address": "aws_instance.web", "expressions": map[string]interface{}{
+					"instance_type": map[string]interface{}{"references": []interface{}{"var.instance_type"}},
+					"tags":          map[string]interface{}{"references": []interface{}{"count.index"}},
+				}},
+				map[string]interface{}{"address": "aws_instance.api", "expressions": map[string]interface{}{
+					"ebs_block_device": []interface{}{map[string]interface{}{"volume_size": map[string]interface{}{"references": []interface{}{"local.volume_size"}}}},
+				}},
+			}},
+		},
+	}
+
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, &tfPlan)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, address := range []string{"aws_instance.web", "aws_instance.api"} {
+		assert.Equal(t, "eu-west-3", gotResources[address].(resources.ComputeResource).Identification.Region)
+	}
+
+	// All references of the configuration are evaluated in a single run, except the ones only valid in a resource
+	consoleLog, err := os.ReadFile(filepath.Join(dir, "console.log"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"[jsonencode(try(local.volume_size, null)), jsonencode(try(var.instance_type, null)), jsonencode(try(var.region, null))]",
+	}, strings.Split(strings.TrimSpace(string(consoleLog)), "\n"))
+}
@@ -0,0 +1,49 @@
+package plan_test
+
+import (
+	"errors"
+	"github.com/spf13/viper"
+	"path"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResources_ResourceErrors(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+
+	tfPlan, err := terraform.CarboniferPlan(path.Join(testutils.RootDir, "test/terraform/planJson/plan.json"))
+	assert.NoError(t, err)
+	for _, resource := range (*tfPlan)["planned_values"].(map[string]interface{})["root_module"].(map[string]interface{})["resources"].([]interface{}) {
+		resourceMap := resource.(map[string]interface{})
+		if resourceMap["address"] == "google_compute_instance.default[1]" {
+			resourceMap["values"].(map[string]interface{})["machine_type"] = "n1-unknown-2"
+		}
+	}
+
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
+	resourceErrors, err := resources.SplitResourceErrors(err)
+	assert.NoError(t, err)
+
+	// Other resources are still read
+	assert.Contains(t, gotResources, "google_compute_instance.default[0]")
+	assert.NotContains(t, gotResources, "google_compute_instance.default[1]")
+
+	var resourceErr *resources.ResourceError
+	for i := range resourceErrors {
+		if resourceErrors[i].Address == "google_compute_instance.default[1]" {
+			resourceErr = &resourceErrors[i]
+		}
+	}
+	if assert.NotNil(t, resourceErr) {
+		var mappingErr *plan.MappingError
+		assert.True(t, errors.As(resourceErr, &mappingErr))
+		assert.Equal(t, "google_compute_instance", mappingErr.ResourceType)
+		assert.Contains(t, resourceErr.Error(), "n1-unknown-2")
+	}
+}
@@ -37,8 +37,9 @@ func TestGetResource_GCP_GKE(t *testing.T) {
 				Address:           "google_container_cluster.my_cluster",
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(2),
-				MemoryMb: int32(7680),
+				VCPUs:       int32(2),
+				MachineType: "n1-standard-2",
+				MemoryMb:    int32(7680),
 
 				HddStorage: decimal.Zero,
 				SsdStorage: decimal.NewFromInt(2725),
@@ -55,8 +56,9 @@ func TestGetResource_GCP_GKE(t *testing.T) {
 				Address:           "google_container_cluster.my_cluster_no_pool",
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(2),
-				MemoryMb: int32(7680),
+				VCPUs:       int32(2),
+				MachineType: "n1-standard-2",
+				MemoryMb:    int32(7680),
 
 				HddStorage: decimal.Zero,
 				SsdStorage: decimal.NewFromInt(950),
@@ -92,8 +94,9 @@ func TestGetResource_GCP_GKE(t *testing.T) {
 				Address:           "google_container_cluster.my_cluster_sub_pool",
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(2),
-				MemoryMb: int32(7680),
+				VCPUs:       int32(2),
+				MachineType: "n1-standard-2",
+				MemoryMb:    int32(7680),
 
 				HddStorage: decimal.Zero,
 				SsdStorage: decimal.NewFromInt(950),
@@ -110,8 +113,9 @@ func TestGetResource_GCP_GKE(t *testing.T) {
 				Address:           "google_container_cluster.my_cluster_autoscaled",
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(2),
-				MemoryMb: int32(7680),
+				VCPUs:       int32(2),
+				MachineType: "n1-standard-2",
+				MemoryMb:    int32(7680),
 
 				HddStorage: decimal.Zero,
 				SsdStorage: decimal.NewFromInt(150),
@@ -128,8 +132,9 @@ func TestGetResource_GCP_GKE(t *testing.T) {
 				Address:           "google_container_cluster.my_cluster_autoscaled_monozone",
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(2),
-				MemoryMb: int32(7680),
+				VCPUs:       int32(2),
+				MachineType: "n1-standard-2",
+				MemoryMb:    int32(7680),
 
 				HddStorage: decimal.Zero,
 				SsdStorage: decimal.NewFromInt(150),
@@ -146,8 +151,9 @@ func TestGetResource_GCP_GKE(t *testing.T) {
 				Address:           "google_container_cluster.my_cluster_autoscaled_total",
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				VCPUs:    int32(2),
-				MemoryMb: int32(7680),
+				VCPUs:       int32(2),
+				MachineType: "n1-standard-2",
+				MemoryMb:    int32(7680),
 
 				HddStorage: decimal.Zero,
 				SsdStorage: decimal.NewFromInt(150),
@@ -156,7 +162,7 @@ func TestGetResource_GCP_GKE(t *testing.T) {
 	}
 	tfPlan, err := terraform.TerraformPlan()
 	assert.NoError(t, err)
-	gotResources, err := plan.GetResources(tfPlan)
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
 	assert.NoError(t, err)
 	for _, got := range gotResources {
 		if got.GetIdentification().ResourceType == "google_container_node_pool" {
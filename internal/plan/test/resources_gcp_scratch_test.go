@@ -0,0 +1,47 @@
+package plan_test
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResources_InstanceFromTemplateScratchDisks(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+
+	tfPlan := map[string]interface{}{
+		"planned_values": map[string]interface{}{"root_module": map[string]interface{}{"resources": []interface{}{
+			map[string]interface{}{"address": "google_compute_instance_template.tpl", "mode": "managed", "type": "google_compute_instance_template", "name": "tpl", "provider_name": "registry.terraform.io/hashicorp/google", "values": map[string]interface{}{
+				"machine_type": "n2-standard-2",
+				"disk":         []interface{}{map[string]interface{}{"disk_size_gb": 20, "disk_type": "pd-ssd"}},
+			}},
+			map[string]interface{}{"address": "google_compute_instance_from_template.vm", "mode": "managed", "type": "google_compute_instance_from_template", "name": "vm", "provider_name": "registry.terraform.io/hashicorp/google", "values": map[string]interface{}{
+				"zone": "europe-west9-a",
+				"scratch_disk": []interface{}{
+					map[string]interface{}{"interface": "NVME", "size": 750},
+					map[string]interface{}{"interface": "NVME"},
+				},
+			}},
+		}}},
+		"configuration": map[string]interface{}{"root_module": map[string]interface{}{"resources": []interface{}{
+			map[string]interface{}{"address": "google_compute_instance_from_template.vm", "expressions": map[string]interface{}{
+				"source_instance_template": map[string]interface{}{"references": []interface{}{"google_compute_instance_template.tpl.id", "google_compute_instance_template.tpl"}},
+			}},
+		}}},
+	}
+
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, &tfPlan)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// Boot disk, a scratch disk with its size and one with the default size (375 GB)
+	specs := gotResources["google_compute_instance_from_template.vm"].(resources.ComputeResource).Specs
+	assert.Equal(t, "n2-standard-2", specs.MachineType)
+	assert.True(t, decimal.NewFromInt(20+750+375).Equal(specs.SsdStorage), specs.SsdStorage.String())
+}
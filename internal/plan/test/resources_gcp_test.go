@@ -182,8 +182,9 @@ func TestGetResource(t *testing.T) {
 					ReplicationFactor: 1,
 				},
 				Specs: &resources.ComputeResourceSpecs{
-					VCPUs:    int32(2),
-					MemoryMb: int32(7680),
+					VCPUs:       int32(2),
+					MachineType: "n1-standard-2",
+					MemoryMb:    int32(7680),
 					GpuTypes: []string{
 						"nvidia-tesla-k80",
 						"nvidia-tesla-k80",
@@ -210,11 +211,12 @@ func TestGetResource(t *testing.T) {
 					ReplicationFactor: 1,
 				},
 				Specs: &resources.ComputeResourceSpecs{
-					GpuTypes:   nil,
-					VCPUs:      int32(12),
-					MemoryMb:   int32(87040),
-					HddStorage: decimal.Zero,
-					SsdStorage: decimal.Zero,
+					GpuTypes:    nil,
+					VCPUs:       int32(12),
+					MachineType: "a2-highgpu-1g",
+					MemoryMb:    int32(87040),
+					HddStorage:  decimal.Zero,
+					SsdStorage:  decimal.Zero,
 				},
 			},
 		},
@@ -222,7 +224,7 @@ func TestGetResource(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			resource, _ := testutils.TfResourceToJSON(&tt.args.tfResource)
-			got, err := plan.GetComputeResource(*resource, &tt.args.mapping, nil)
+			got, err := plan.GetComputeResource(viper.GetViper(), nil, &map[string]interface{}{}, *resource, &tt.args.mapping, nil)
 			assert.NoError(t, err)
 			assert.Len(t, got, 1)
 			assert.IsType(t, resources.ComputeResource{}, got[0])
@@ -266,7 +268,7 @@ func TestGetResources_DiskImage(t *testing.T) {
 	}
 
 	tfPlan, _ := terraform.TerraformPlan()
-	resourceList, err := plan.GetResources(tfPlan)
+	resourceList, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
 	if assert.NoError(t, err) {
 		assert.Equal(t, len(wantResources), len(resourceList))
 		for i, resource := range resourceList {
@@ -316,18 +318,19 @@ func TestGetResources_GroupInstance(t *testing.T) {
 				ReplicationFactor: 1,
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				GpuTypes:   nil,
-				HddStorage: decimal.New(20, 0),
-				SsdStorage: decimal.Zero,
-				MemoryMb:   8192,
-				VCPUs:      2,
-				CPUType:    "",
+				GpuTypes:    nil,
+				HddStorage:  decimal.New(20, 0),
+				SsdStorage:  decimal.Zero,
+				MemoryMb:    8192,
+				VCPUs:       2,
+				MachineType: "e2-standard-2",
+				CPUType:     "",
 			},
 		},
 	}
 
 	tfPlan, _ := terraform.TerraformPlan()
-	resources, err := plan.GetResources(tfPlan)
+	resources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
 	if assert.NoError(t, err) {
 		for i, resource := range resources {
 			wantResource := wantResources[i]
@@ -376,18 +379,19 @@ func TestGetResources_InstanceFromTemplate(t *testing.T) {
 				ReplicationFactor: 1,
 			},
 			Specs: &resources.ComputeResourceSpecs{
-				GpuTypes:   nil,
-				HddStorage: decimal.New(20, 0),
-				SsdStorage: decimal.Zero,
-				MemoryMb:   8192,
-				VCPUs:      2,
-				CPUType:    "",
+				GpuTypes:    nil,
+				HddStorage:  decimal.New(20, 0),
+				SsdStorage:  decimal.Zero,
+				MemoryMb:    8192,
+				VCPUs:       2,
+				MachineType: "e2-standard-2",
+				CPUType:     "",
 			},
 		},
 	}
 
 	tfPlan, _ := terraform.TerraformPlan()
-	resources, err := plan.GetResources(tfPlan)
+	resources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
 	if assert.NoError(t, err) {
 		for i, resource := range resources {
 			wantResource := wantResources[i]
@@ -0,0 +1,76 @@
+package plan_test
+
+import (
+	"path"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResources_Offline(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+	viper.Set("offline", true)
+	defer viper.Set("offline", false)
+
+	tfPlan, err := terraform.CarboniferPlan(path.Join(testutils.RootDir, "test/terraform/offline"))
+	assert.NoError(t, err)
+	gotResources, err := plan.GetResources(viper.GetViper(), nil, tfPlan)
+	assert.NoError(t, err)
+
+	addresses := []string{}
+	for address := range gotResources {
+		addresses = append(addresses, address)
+	}
+	assert.ElementsMatch(t, []string{
+		"google_compute_disk.backup",
+		`google_compute_instance.app["back"]`,
+		`google_compute_instance.app["front"]`,
+		"google_compute_instance.web[0]",
+		"google_compute_instance.web[1]",
+		"module.storage.google_compute_disk.data",
+	}, addresses)
+
+	// count and locals from terraform.tfvars, zone from provider
+	web := gotResources["google_compute_instance.web[1]"].(resources.ComputeResource)
+	assert.Equal(t, "n1-standard-2", web.Specs.MachineType)
+	assert.Equal(t, "us-central1", web.Identification.Region)
+	assert.True(t, decimal.NewFromInt(100).Equal(web.Specs.HddStorage))
+
+	// for_each from terraform.tfvars and dynamic scratch disks
+	back := gotResources[`google_compute_instance.app["back"]`].(resources.ComputeResource)
+	assert.Equal(t, "c2-standard-4", back.Specs.MachineType)
+	assert.Equal(t, "europe-west9", back.Identification.Region)
+	assert.True(t, decimal.NewFromInt(750).Equal(back.Specs.SsdStorage))
+
+	// Module variable default and output
+	moduleDisk := gotResources["module.storage.google_compute_disk.data"].(resources.ComputeResource)
+	assert.True(t, decimal.NewFromInt(100).Equal(moduleDisk.Specs.SsdStorage))
+	backup := gotResources["google_compute_disk.backup"].(resources.ComputeResource)
+	assert.True(t, decimal.NewFromInt(200).Equal(backup.Specs.HddStorage))
+}
+
+func TestGetResources_Parallelism(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+	parallelism := viper.Get("parallelism")
+	defer viper.Set("parallelism", parallelism)
+
+	tfPlan, err := terraform.CarboniferPlan(path.Join(testutils.RootDir, "test/terraform/planJson/plan.json"))
+	assert.NoError(t, err)
+
+	// Same resources and errors, read one at a time or in parallel
+	viper.Set("parallelism", 1)
+	sequentialResources, sequentialErr := plan.GetResources(viper.GetViper(), nil, tfPlan)
+	viper.Set("parallelism", 8)
+	parallelResources, parallelErr := plan.GetResources(viper.GetViper(), nil, tfPlan)
+
+	assert.Equal(t, sequentialResources, parallelResources)
+	assert.EqualError(t, parallelErr, sequentialErr.Error())
+}
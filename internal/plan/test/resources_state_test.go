@@ -0,0 +1,65 @@
+package plan_test
+
+import (
+	"github.com/spf13/viper"
+	"path"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResources_State(t *testing.T) {
+	// reset
+	terraform.ResetTerraformExec()
+
+	// The state is the one of planJson once applied, with an extra instance in a module
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"tfstate", "test/terraform/state/terraform.tfstate"},
+		{"show json", "test/terraform/state/state.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tfState, err := terraform.CarboniferPlan(path.Join(testutils.RootDir, tt.input))
+			assert.NoError(t, err)
+			gotResources, err := plan.GetResources(viper.GetViper(), nil, tfState)
+			assert.NoError(t, err)
+
+			addresses := []string{}
+			for address := range gotResources {
+				addresses = append(addresses, address)
+			}
+			assert.ElementsMatch(t, []string{
+				"google_compute_disk.first",
+				"google_compute_instance.default[0]",
+				"google_compute_instance.default[1]",
+				"google_compute_instance.foo[0]",
+				"google_compute_instance.foo[1]",
+				"google_compute_instance_from_template.ifromtpl",
+				"google_compute_network.vpc_network",
+				"google_compute_region_disk.second",
+				"google_compute_region_instance_group_manager.my-group-manager",
+				"google_compute_subnetwork.default",
+				"google_sql_database_instance.instance",
+				"module.app.google_compute_instance.app",
+			}, addresses)
+
+			// Template is found through the reference rebuilt from the state
+			group := gotResources["google_compute_region_instance_group_manager.my-group-manager"].(resources.ComputeResource)
+			assert.Equal(t, "e2-standard-2", group.Specs.MachineType)
+			assert.Equal(t, int64(3), group.Identification.Count)
+			fromTemplate := gotResources["google_compute_instance_from_template.ifromtpl"].(resources.ComputeResource)
+			assert.Equal(t, "e2-standard-2", fromTemplate.Specs.MachineType)
+
+			app := gotResources["module.app.google_compute_instance.app"].(resources.ComputeResource)
+			assert.Equal(t, gotResources["google_compute_instance.foo[0]"].(resources.ComputeResource).Specs, app.Specs)
+			assert.Equal(t, "europe-west9", app.Identification.Region)
+		})
+	}
+}
@@ -9,8 +9,6 @@ import (
 	"github.com/yunabe/easycsv"
 )
 
-var wattPerGPU map[string]GPUWatt
-
 // GPUWatt is the struct that contains the min and max watts of a GPU
 type GPUWatt struct {
 	Name     string
@@ -24,30 +22,34 @@ type gpuWattCSV struct {
 	MaxWatts float64 `name:"max watts"`
 }
 
-// GetGPUWatt returns the min and max watts of a GPU
-func GetGPUWatt(gpuName string) GPUWatt {
+// GetGPUWatt returns the min and max watts of a GPU, zero watts if unknown
+func GetGPUWatt(dataPath string, gpuName string) (GPUWatt, error) {
 	// Source: https://www.cloudcarbonfootprint.org/docs/methodology#appendix-iii-gpus-and-minmax-watts
 	log.Debugf("  Getting info for GPU type: %v", gpuName)
-	if wattPerGPU == nil {
-		// Read the CSV records
-		var records []gpuWattCSV
-		gpuPowerDataFile := data.ReadDataFile("gpu_watt.csv")
-		log.Debugf("  reading gpu power data from: %v", gpuPowerDataFile)
-		if err := easycsv.NewReader(strings.NewReader(string(gpuPowerDataFile))).ReadAll(&records); err != nil {
-			log.Fatal(err)
-		}
+	wattPerGPU, err := data.Load(dataPath, "gpu_watt.csv", loadGPUWatts)
+	if err != nil {
+		return GPUWatt{}, err
+	}
+	return wattPerGPU[strings.ToLower(gpuName)], nil
+}
+
+func loadGPUWatts(gpuPowerDataFile []byte) (map[string]GPUWatt, error) {
+	// Read the CSV records
+	var records []gpuWattCSV
+	if err := easycsv.NewReader(strings.NewReader(string(gpuPowerDataFile))).ReadAll(&records); err != nil {
+		return nil, err
+	}
 
-		// Create a map to store the data
-		wattPerGPU = make(map[string]GPUWatt)
+	// Create a map to store the data
+	wattPerGPU := make(map[string]GPUWatt)
 
-		// Iterate over the records and add them to the map
-		for _, record := range records {
-			wattPerGPU[strings.ToLower(record.Name)] = GPUWatt{
-				Name:     record.Name,
-				MinWatts: decimal.NewFromFloat(record.MinWatts),
-				MaxWatts: decimal.NewFromFloat(record.MaxWatts),
-			}
+	// Iterate over the records and add them to the map
+	for _, record := range records {
+		wattPerGPU[strings.ToLower(record.Name)] = GPUWatt{
+			Name:     record.Name,
+			MinWatts: decimal.NewFromFloat(record.MinWatts),
+			MaxWatts: decimal.NewFromFloat(record.MaxWatts),
 		}
 	}
-	return wattPerGPU[strings.ToLower(gpuName)]
+	return wattPerGPU, nil
 }
@@ -1,9 +1,8 @@
 package aws
 
 import (
-	"encoding/json"
-
 	"github.com/carboniferio/carbonifer/internal/data"
+	"github.com/carboniferio/carbonifer/internal/providers"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -12,6 +11,7 @@ type InstanceType struct {
 	InstanceType    string          `json:"InstanceType"`
 	VCPU            int32           `json:"VCPU"`
 	MemoryMb        int32           `json:"MemoryMb"`
+	GPUs            []string        `json:"GPUs"`
 	InstanceStorage InstanceStorage `json:"InstanceStorage"`
 }
 
@@ -22,18 +22,21 @@ type InstanceStorage struct {
 	Type          string
 }
 
-var awsInstanceTypes map[string]InstanceType
-
 // GetAWSInstanceType returns the information of an AWS instance type
-func GetAWSInstanceType(instanceTypeStr string) InstanceType {
+func GetAWSInstanceType(dataPath string, instanceTypeStr string) (InstanceType, error) {
 	log.Debugf("  Getting info for AWS machine type: %v", instanceTypeStr)
-	if awsInstanceTypes == nil {
-		byteValue := data.ReadDataFile("aws_instances.json")
-		err := json.Unmarshal([]byte(byteValue), &awsInstanceTypes)
-		if err != nil {
-			log.Fatal(err)
-		}
+	instanceTypes, err := GetAWSInstanceTypes(dataPath)
+	if err != nil {
+		return InstanceType{}, err
 	}
+	instanceType, ok := instanceTypes[instanceTypeStr]
+	if !ok {
+		return InstanceType{}, &providers.UnknownMachineTypeError{Provider: providers.AWS, MachineType: instanceTypeStr}
+	}
+	return instanceType, nil
+}
 
-	return awsInstanceTypes[instanceTypeStr]
+// GetAWSInstanceTypes returns the information of all AWS instance types, by name
+func GetAWSInstanceTypes(dataPath string) (map[string]InstanceType, error) {
+	return data.LoadJSON[map[string]InstanceType](dataPath, "aws_instances.json")
 }
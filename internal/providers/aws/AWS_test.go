@@ -1,9 +1,12 @@
 package aws
 
 import (
+	"errors"
+	"github.com/spf13/viper"
 	"reflect"
 	"testing"
 
+	"github.com/carboniferio/carbonifer/internal/providers"
 	_ "github.com/carboniferio/carbonifer/internal/testutils"
 )
 
@@ -23,6 +26,7 @@ func TestGetAWSInstanceType(t *testing.T) {
 				InstanceType: "c5d.12xlarge",
 				VCPU:         48,
 				MemoryMb:     96 * 1024,
+				GPUs:         []string{},
 				InstanceStorage: InstanceStorage{
 					SizePerDiskGB: 900,
 					Count:         2,
@@ -33,9 +37,21 @@ func TestGetAWSInstanceType(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := GetAWSInstanceType(tt.args.instanceTypeStr); !reflect.DeepEqual(got, tt.want) {
+			got, err := GetAWSInstanceType(viper.GetString("data.path"), tt.args.instanceTypeStr)
+			if err != nil {
+				t.Errorf("GetAWSInstanceType() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("GetAWSInstanceType() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestGetAWSInstanceType_Unknown(t *testing.T) {
+	_, err := GetAWSInstanceType(viper.GetString("data.path"), "c5d.unknown")
+	var unknownErr *providers.UnknownMachineTypeError
+	if !errors.As(err, &unknownErr) || unknownErr.MachineType != "c5d.unknown" {
+		t.Errorf("GetAWSInstanceType() error = %v, want UnknownMachineTypeError", err)
+	}
+}
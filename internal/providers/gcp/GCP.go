@@ -1,12 +1,12 @@
 package gcp
 
 import (
-	"encoding/json"
 	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/carboniferio/carbonifer/internal/data"
+	"github.com/carboniferio/carbonifer/internal/providers"
 	"github.com/shopspring/decimal"
 	log "github.com/sirupsen/logrus"
 
@@ -38,45 +38,39 @@ type CPUWatt struct {
 	GridCarbonIntensity decimal.Decimal
 }
 
-var gcpInstanceTypes map[string]MachineType
-var gcpWattPerCPU map[string]CPUWatt
-var gcpSQLTiers map[string]SQLTier
-
 // GetGCPMachineType returns the information of a GCP instance type
-func GetGCPMachineType(machineTypeStr string, zone string) MachineType {
+func GetGCPMachineType(dataPath string, machineTypeStr string, zone string) (MachineType, error) {
 	log.Debugf("  Getting info for GCP machine type: %v", machineTypeStr)
 	// Custom format is custom-<number_cpus>-<ram_mb>
 	customMachineRegex := regexp.MustCompile(`custom-(?P<vcpus>\d+)-(?P<mem>\d+)(-ext)?`)
 	if customMachineRegex.MatchString(machineTypeStr) {
 		log.Debugf("  custom machine: %v", machineTypeStr)
 		customValues := customMachineRegex.FindAllStringSubmatch(machineTypeStr, -1)[0]
-		if len(customValues) < 3 {
-			log.Fatalf("GCP Custom machine name malformed : %v", machineTypeStr)
-		}
-		vCPUs, err := strconv.Atoi(customValues[1])
-		if err != nil {
-			log.Fatalf(err.Error())
-		}
-		ram, err := strconv.Atoi(customValues[2])
-		if err != nil {
-			log.Fatalf(err.Error())
+		vCPUs, errCPUs := strconv.Atoi(customValues[1])
+		ram, errRAM := strconv.Atoi(customValues[2])
+		if errCPUs != nil || errRAM != nil {
+			return MachineType{}, &providers.UnknownMachineTypeError{Provider: providers.GCP, MachineType: machineTypeStr}
 		}
 		return MachineType{
 			Name:     machineTypeStr,
 			Vcpus:    int32(vCPUs),
 			MemoryMb: int32(ram),
-		}
+		}, nil
 	}
-	if gcpInstanceTypes == nil {
-		byteValue := data.ReadDataFile("gcp_instances.json")
-		err := json.Unmarshal([]byte(byteValue), &gcpInstanceTypes)
-		if err != nil {
-			log.Fatal(err)
-		}
+	machineTypes, err := GetGCPMachineTypes(dataPath)
+	if err != nil {
+		return MachineType{}, err
 	}
+	machineType, ok := machineTypes[machineTypeStr]
+	if !ok {
+		return MachineType{}, &providers.UnknownMachineTypeError{Provider: providers.GCP, MachineType: machineTypeStr}
+	}
+	return machineType, nil
+}
 
-	return gcpInstanceTypes[machineTypeStr]
-
+// GetGCPMachineTypes returns the information of all GCP machine types, by name
+func GetGCPMachineTypes(dataPath string) (map[string]MachineType, error) {
+	return data.LoadJSON[map[string]MachineType](dataPath, "gcp_instances.json")
 }
 
 type cpuWattCSV struct {
@@ -87,66 +81,64 @@ type cpuWattCSV struct {
 }
 
 // Source: https://github.com/cloud-carbon-footprint/cloud-carbon-coefficients/blob/5fcb96101c6f28dac5060f8794bca5d4da6c72d8/output/coefficients-gcp-use.csv
-// GetCPUWatt returns the min and max watts of a CPU
-func GetCPUWatt(cpu string) CPUWatt {
+// GetCPUWatt returns the min and max watts of a CPU, zero watts if unknown
+func GetCPUWatt(dataPath string, cpu string) (CPUWatt, error) {
 	log.Debugf("  Getting info for GCP CPU type: %v", cpu)
-	if gcpWattPerCPU == nil {
-		// Read the CSV records
-		var records []cpuWattCSV
-		fileContents := data.ReadDataFile("gcp_watt_cpu.csv")
-		if err := easycsv.NewReader(strings.NewReader(string(fileContents))).ReadAll(&records); err != nil {
-			log.Fatal(err)
-		}
+	gcpWattPerCPU, err := data.Load(dataPath, "gcp_watt_cpu.csv", loadCPUWatts)
+	if err != nil {
+		return CPUWatt{}, err
+	}
+	return gcpWattPerCPU[strings.ToLower(cpu)], nil
+}
+
+func loadCPUWatts(fileContents []byte) (map[string]CPUWatt, error) {
+	// Read the CSV records
+	var records []cpuWattCSV
+	if err := easycsv.NewReader(strings.NewReader(string(fileContents))).ReadAll(&records); err != nil {
+		return nil, err
+	}
 
-		// Create a map to store the data
-		gcpWattPerCPU = make(map[string]CPUWatt)
+	// Create a map to store the data
+	gcpWattPerCPU := make(map[string]CPUWatt)
 
-		// Iterate over the records and add them to the map
-		for _, record := range records {
-			gcpWattPerCPU[strings.ToLower(record.Architecture)] = CPUWatt{
-				Architecture:        record.Architecture,
-				MinWatts:            decimal.NewFromFloat(record.MinWatts),
-				MaxWatts:            decimal.NewFromFloat(record.MaxWatts),
-				GridCarbonIntensity: decimal.NewFromFloat(record.GridCarbonIntensity),
-			}
+	// Iterate over the records and add them to the map
+	for _, record := range records {
+		gcpWattPerCPU[strings.ToLower(record.Architecture)] = CPUWatt{
+			Architecture:        record.Architecture,
+			MinWatts:            decimal.NewFromFloat(record.MinWatts),
+			MaxWatts:            decimal.NewFromFloat(record.MaxWatts),
+			GridCarbonIntensity: decimal.NewFromFloat(record.GridCarbonIntensity),
 		}
 	}
-	return gcpWattPerCPU[strings.ToLower(cpu)]
+	return gcpWattPerCPU, nil
 }
 
 // GetGCPSQLTier returns the information of a GCP SQL tier
-func GetGCPSQLTier(tierName string) SQLTier {
+func GetGCPSQLTier(dataPath string, tierName string) (SQLTier, error) {
 	log.Debugf("  Getting info for GCP SQL tier: %v", tierName)
 	// Custom format db-custom-<number_cpus>-<ram_mb>
 	customTierRegex := regexp.MustCompile(`db-custom-(?P<vcpus>\d+)-(?P<mem>\d+)`)
 	if customTierRegex.MatchString(tierName) {
 		log.Debugf("  custom SQL Tier: %v", tierName)
 		customValues := customTierRegex.FindAllStringSubmatch(tierName, -1)[0]
-		if len(customValues) < 3 {
-			log.Fatalf("GCP Custom tier name malformed : %v", tierName)
-		}
-		vCPUs, err := strconv.Atoi(customValues[1])
-		if err != nil {
-			log.Fatalf(err.Error())
-		}
-		ram, err := strconv.Atoi(customValues[2])
-		if err != nil {
-			log.Fatalf(err.Error())
+		vCPUs, errCPUs := strconv.Atoi(customValues[1])
+		ram, errRAM := strconv.Atoi(customValues[2])
+		if errCPUs != nil || errRAM != nil {
+			return SQLTier{}, &providers.UnknownMachineTypeError{Provider: providers.GCP, MachineType: tierName}
 		}
 		return SQLTier{
 			Name:     tierName,
 			Vcpus:    int64(vCPUs),
 			MemoryMb: int64(ram),
-		}
+		}, nil
 	}
-	if gcpSQLTiers == nil {
-		byteValue := data.ReadDataFile("gcp_sql_tiers.json")
-		err := json.Unmarshal([]byte(byteValue), &gcpSQLTiers)
-		if err != nil {
-			log.Fatal(err)
-		}
+	gcpSQLTiers, err := data.LoadJSON[map[string]SQLTier](dataPath, "gcp_sql_tiers.json")
+	if err != nil {
+		return SQLTier{}, err
 	}
-
-	return gcpSQLTiers[tierName]
-
+	tier, ok := gcpSQLTiers[tierName]
+	if !ok {
+		return SQLTier{}, &providers.UnknownMachineTypeError{Provider: providers.GCP, MachineType: tierName}
+	}
+	return tier, nil
 }
@@ -1,8 +1,10 @@
 package gcp
 
 import (
+	"github.com/spf13/viper"
 	"testing"
 
+	"github.com/carboniferio/carbonifer/internal/providers"
 	_ "github.com/carboniferio/carbonifer/internal/testutils"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
@@ -44,14 +46,27 @@ func TestGetGCPMachineType(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := GetGCPMachineType(tt.args.machineTypeStr, tt.args.zone)
+			got, err := GetGCPMachineType(viper.GetString("data.path"), tt.args.machineTypeStr, tt.args.zone)
+			assert.NoError(t, err)
 			assert.Equal(t, got, tt.want)
 		})
 	}
 }
 
+func TestGetGCPMachineType_Unknown(t *testing.T) {
+	_, err := GetGCPMachineType(viper.GetString("data.path"), "e2-unknown-2", "europe-west9-a")
+	var unknownErr *providers.UnknownMachineTypeError
+	assert.ErrorAs(t, err, &unknownErr)
+	assert.Equal(t, "e2-unknown-2", unknownErr.MachineType)
+
+	// Custom machine types with too many vCPUs to parse
+	_, err = GetGCPMachineType(viper.GetString("data.path"), "custom-99999999999999999999-2048", "europe-west9-a")
+	assert.ErrorAs(t, err, &unknownErr)
+}
+
 func TestGetCPUWatt(t *testing.T) {
-	got := GetCPUWatt("Skylake")
+	got, err := GetCPUWatt(viper.GetString("data.path"), "Skylake")
+	assert.NoError(t, err)
 	want := CPUWatt{
 		Architecture:        "Skylake",
 		MinWatts:            decimal.NewFromFloat(0.6446044454253452),
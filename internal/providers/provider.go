@@ -15,3 +15,13 @@ type UnsupportedProviderError struct {
 func (upe *UnsupportedProviderError) Error() string {
 	return fmt.Sprintf("Unsupported Provider: %v", upe.Provider)
 }
+
+// UnknownMachineTypeError is an error that occurs when a machine type is not in the data files of its provider
+type UnknownMachineTypeError struct {
+	Provider    Provider
+	MachineType string
+}
+
+func (e *UnknownMachineTypeError) Error() string {
+	return fmt.Sprintf("Unknown %v machine type: %v", e.Provider, e.MachineType)
+}
@@ -0,0 +1,374 @@
+package recommend
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/providers/aws"
+	"github.com/carboniferio/carbonifer/internal/providers/gcp"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// InstancesOptions is the struct that contains the options of instance recommendations
+type InstancesOptions struct {
+	Count int
+}
+
+// InstancesReport is the struct that contains the instance recommendations of all resources
+type InstancesReport struct {
+	Info      estimation.EstimationInfo
+	Resources []ResourceInstances
+}
+
+// ResourceInstances is the struct that contains the instance recommendations of a resource
+type ResourceInstances struct {
+	Address     string
+	Provider    providers.Provider
+	Region      string
+	MachineType string
+	VCPUs       int32
+	MemoryMb    int32
+	CPUType     string
+	// UnusedLocalStorageGB is the local storage of the machine type not declared by the resource, counted in Power
+	UnusedLocalStorageGB decimal.Decimal
+	Power                decimal.Decimal // per instance
+	CarbonEmissions      decimal.Decimal // all instances of the resource
+	Alternatives         []InstanceAlternative
+}
+
+// InstanceAlternative is the struct that contains the emissions of a resource with another machine type
+type InstanceAlternative struct {
+	MachineType     string
+	VCPUs           int32
+	MemoryMb        int32
+	CPUType         string
+	Reasons         []string
+	Power           decimal.Decimal // per instance
+	CarbonEmissions decimal.Decimal // all instances of the resource
+	Savings         decimal.Decimal
+	SavingsPercent  decimal.Decimal
+}
+
+// awsARMFamily matches AWS Graviton instance types (ex: a1.large, m6g.large, c7gd.xlarge)
+var awsARMFamily = regexp.MustCompile(`^(a1|[a-z]+\d+g[a-z]*)\.`)
+
+// GetInstancesOptions reads the instance recommendation options from config
+func GetInstancesOptions() (InstancesOptions, error) {
+	options := InstancesOptions{
+		Count: viper.GetInt("recommend.instances.count"),
+	}
+	if options.Count <= 0 {
+		return options, errors.Errorf("recommend.instances.count must be positive: %v", options.Count)
+	}
+	return options, nil
+}
+
+// RecommendInstances lists, for each resource of the report with a known machine type, the machine types
+// with equal or greater vCPUs and memory and a lower estimated power.
+// Alternatives are estimated the same way as plan (estimate.EstimateSupportedResource). On AWS, the local storage
+// of instance types is counted even if not declared, so that dropping unused local storage shows as a saving.
+// Alternatives are read from the data of config and estimated with its units and usage.
+func RecommendInstances(config *viper.Viper, report estimation.EstimationReport, options InstancesOptions) *InstancesReport {
+	instancesReport := InstancesReport{
+		Info:      report.Info,
+		Resources: []ResourceInstances{},
+	}
+	for _, estimationResource := range report.Resources {
+		computeResource, ok := toComputeResource(estimationResource.Resource)
+		if !ok || computeResource.Specs == nil || computeResource.Specs.MachineType == "" {
+			log.Debugf("Skipping instance recommendations of %v: no machine type", estimationResource.Resource.GetAddress())
+			continue
+		}
+		alternatives, unusedLocalStorage, err := getAlternatives(config.GetString("data.path"), computeResource)
+		if err != nil {
+			log.Warnf("Skipping instance recommendations of %v: %v", computeResource.GetAddress(), err)
+			continue
+		}
+
+		// The current machine type is compared with its unused local storage, as alternatives are
+		currentPower, currentEmissions := estimationResource.Power, estimationResource.CarbonEmissions
+		if unusedLocalStorage.total().IsPositive() {
+			specs := computeResource.Specs
+			current := withSpecs(computeResource, specs.MachineType, specs.VCPUs, specs.MemoryMb, specs.CPUType, specs.HddStorage.Add(unusedLocalStorage.hdd), specs.SsdStorage.Add(unusedLocalStorage.ssd))
+			currentEstimation, err := estimate.EstimateSupportedResource(config, current, nil, "")
+			if err != nil {
+				log.Warnf("Skipping instance recommendations of %v: %v", computeResource.GetAddress(), err)
+				continue
+			}
+			currentPower, currentEmissions = currentEstimation.Power, currentEstimation.CarbonEmissions
+		}
+
+		resourceInstances := ResourceInstances{
+			Address:              computeResource.GetAddress(),
+			Provider:             computeResource.Identification.Provider,
+			Region:               computeResource.Identification.Region,
+			MachineType:          computeResource.Specs.MachineType,
+			VCPUs:                computeResource.Specs.VCPUs,
+			MemoryMb:             computeResource.Specs.MemoryMb,
+			CPUType:              computeResource.Specs.CPUType,
+			UnusedLocalStorageGB: unusedLocalStorage.total(),
+			Power:                currentPower,
+			CarbonEmissions:      currentEmissions.Mul(estimationResource.TotalCount),
+			Alternatives:         []InstanceAlternative{},
+		}
+		for _, alternative := range alternatives {
+			alternativeEstimation, err := estimate.EstimateSupportedResource(config, alternative.resource, nil, "")
+			if err != nil {
+				log.Warnf("Skipping alternative %v of %v: %v", alternative.resource.Specs.MachineType, computeResource.GetAddress(), err)
+				continue
+			}
+			if !alternativeEstimation.Power.LessThan(resourceInstances.Power) {
+				continue
+			}
+			emissions := alternativeEstimation.CarbonEmissions.Mul(estimationResource.TotalCount)
+			savings := resourceInstances.CarbonEmissions.Sub(emissions)
+			savingsPercent := decimal.Zero
+			if !resourceInstances.CarbonEmissions.IsZero() {
+				savingsPercent = savings.Div(resourceInstances.CarbonEmissions).Mul(decimal.NewFromInt(100))
+			}
+			resourceInstances.Alternatives = append(resourceInstances.Alternatives, InstanceAlternative{
+				MachineType:     alternative.resource.Specs.MachineType,
+				VCPUs:           alternative.resource.Specs.VCPUs,
+				MemoryMb:        alternative.resource.Specs.MemoryMb,
+				CPUType:         alternative.resource.Specs.CPUType,
+				Reasons:         alternative.reasons,
+				Power:           alternativeEstimation.Power,
+				CarbonEmissions: emissions,
+				Savings:         savings,
+				SavingsPercent:  savingsPercent,
+			})
+		}
+		resourceInstances.Alternatives = bestAlternatives(resourceInstances.Alternatives, options.Count)
+		instancesReport.Resources = append(instancesReport.Resources, resourceInstances)
+	}
+	sort.Slice(instancesReport.Resources, func(i, j int) bool {
+		return instancesReport.Resources[i].Address < instancesReport.Resources[j].Address
+	})
+	return &instancesReport
+}
+
+// bestAlternatives keeps the lowest power alternative of each machine type, then the count lowest ones
+func bestAlternatives(alternatives []InstanceAlternative, count int) []InstanceAlternative {
+	sort.SliceStable(alternatives, func(i, j int) bool {
+		if !alternatives[i].Power.Equal(alternatives[j].Power) {
+			return alternatives[i].Power.LessThan(alternatives[j].Power)
+		}
+		return alternatives[i].MachineType < alternatives[j].MachineType
+	})
+	best := []InstanceAlternative{}
+	seen := map[string]bool{}
+	for _, alternative := range alternatives {
+		if seen[alternative.MachineType] {
+			continue
+		}
+		seen[alternative.MachineType] = true
+		best = append(best, alternative)
+		if len(best) == count {
+			break
+		}
+	}
+	return best
+}
+
+// toComputeResource handles compute resources of estimations, stored as values or pointers
+func toComputeResource(resource resources.Resource) (resources.ComputeResource, bool) {
+	switch r := resource.(type) {
+	case resources.ComputeResource:
+		return r, true
+	case *resources.ComputeResource:
+		return *r, r != nil
+	default:
+		return resources.ComputeResource{}, false
+	}
+}
+
+type alternative struct {
+	resource resources.ComputeResource
+	reasons  []string
+}
+
+// localStorage is an amount of local storage, in GB
+type localStorage struct {
+	hdd decimal.Decimal
+	ssd decimal.Decimal
+}
+
+func (s localStorage) total() decimal.Decimal {
+	return s.hdd.Add(s.ssd)
+}
+
+// getAlternatives returns the alternatives of the resource, and the local storage of its machine type it does not declare
+func getAlternatives(dataPath string, resource resources.ComputeResource) ([]alternative, localStorage, error) {
+	switch resource.Identification.Provider {
+	case providers.GCP:
+		alternatives, err := getGCPAlternatives(dataPath, resource)
+		return alternatives, localStorage{}, err
+	case providers.AWS:
+		return getAWSAlternatives(dataPath, resource)
+	default:
+		return nil, localStorage{}, &providers.UnsupportedProviderError{Provider: resource.Identification.Provider.String()}
+	}
+}
+
+// getGCPAlternatives returns the GCP machine types alternatives of the resource. Local SSDs are out of scope: GCP
+// machine types data do not include bundled local SSDs, and scratch disks are declared, so they are kept as is.
+func getGCPAlternatives(dataPath string, resource resources.ComputeResource) ([]alternative, error) {
+	specs := resource.Specs
+	current, err := gcp.GetGCPMachineType(dataPath, specs.MachineType, resource.Identification.Region)
+	if err != nil {
+		return nil, err
+	}
+	machineTypes, err := gcp.GetGCPMachineTypes(dataPath)
+	if err != nil {
+		return nil, err
+	}
+
+	alternatives := []alternative{}
+	for name, machineType := range machineTypes {
+		if machineType.Vcpus < specs.VCPUs || machineType.MemoryMb < specs.MemoryMb || !sameStrings(machineType.GPUTypes, current.GPUTypes) {
+			continue
+		}
+		// Try the current CPU platform, and each known platform of the machine type
+		cpuTypes := []string{specs.CPUType}
+		for _, cpuType := range machineType.CPUTypes {
+			cpuWatt, err := gcp.GetCPUWatt(dataPath, cpuType)
+			if err != nil {
+				return nil, err
+			}
+			if !cpuWatt.MaxWatts.IsZero() && !strings.EqualFold(cpuType, specs.CPUType) {
+				cpuTypes = append(cpuTypes, cpuType)
+			}
+		}
+		for _, cpuType := range cpuTypes {
+			if name == specs.MachineType && cpuType == specs.CPUType {
+				continue
+			}
+			reasons := []string{}
+			if name != specs.MachineType {
+				reasons = append(reasons, sizeReason(machineType.Vcpus, machineType.MemoryMb, specs))
+			}
+			if cpuType != specs.CPUType {
+				reasons = append(reasons, fmt.Sprintf("CPU platform %v", cpuType))
+			}
+			alternatives = append(alternatives, alternative{
+				resource: withSpecs(resource, name, machineType.Vcpus, machineType.MemoryMb, cpuType, specs.HddStorage, specs.SsdStorage),
+				reasons:  reasons,
+			})
+		}
+	}
+	return alternatives, nil
+}
+
+// getAWSAlternatives returns the AWS instance types alternatives of the resource, with the local storage (instance
+// store) of each instance type, used or not. If the resource declares the local storage of its instance type, only
+// instance types with at least as much local storage of the same type are alternatives.
+func getAWSAlternatives(dataPath string, resource resources.ComputeResource) ([]alternative, localStorage, error) {
+	specs := resource.Specs
+	current, err := aws.GetAWSInstanceType(dataPath, specs.MachineType)
+	if err != nil {
+		return nil, localStorage{}, err
+	}
+	instanceTypes, err := aws.GetAWSInstanceTypes(dataPath)
+	if err != nil {
+		return nil, localStorage{}, err
+	}
+
+	// Local storage of the instance type is used if declared (ephemeral block devices), the rest is network storage
+	hddStorage, ssdStorage := specs.HddStorage, specs.SsdStorage
+	currentLocalStorage := instanceLocalStorage(current)
+	localStorageUsed := false
+	unused := localStorage{}
+	if currentLocalStorage.total().IsPositive() {
+		localStorageUsed = hddStorage.GreaterThanOrEqual(currentLocalStorage.hdd) && ssdStorage.GreaterThanOrEqual(currentLocalStorage.ssd)
+		if localStorageUsed {
+			hddStorage = hddStorage.Sub(currentLocalStorage.hdd)
+			ssdStorage = ssdStorage.Sub(currentLocalStorage.ssd)
+		} else {
+			unused = currentLocalStorage
+		}
+	}
+
+	alternatives := []alternative{}
+	for name, instanceType := range instanceTypes {
+		if name == specs.MachineType || instanceType.VCPU < specs.VCPUs || instanceType.MemoryMb < specs.MemoryMb || !sameStrings(instanceType.GPUs, current.GPUs) {
+			continue
+		}
+		candidateLocalStorage := instanceLocalStorage(instanceType)
+		if localStorageUsed && (candidateLocalStorage.hdd.LessThan(currentLocalStorage.hdd) || candidateLocalStorage.ssd.LessThan(currentLocalStorage.ssd)) {
+			continue
+		}
+		reasons := []string{sizeReason(instanceType.VCPU, instanceType.MemoryMb, specs)}
+		if awsARMFamily.MatchString(name) && !awsARMFamily.MatchString(specs.MachineType) {
+			reasons = append(reasons, "ARM")
+		}
+		if unused.total().IsPositive() && candidateLocalStorage.total().LessThan(unused.total()) {
+			reasons = append(reasons, "no unused local storage")
+		}
+		alternatives = append(alternatives, alternative{
+			resource: withSpecs(resource, name, instanceType.VCPU, instanceType.MemoryMb, "", hddStorage.Add(candidateLocalStorage.hdd), ssdStorage.Add(candidateLocalStorage.ssd)),
+			reasons:  reasons,
+		})
+	}
+	return alternatives, unused, nil
+}
+
+// instanceLocalStorage returns the local storage (instance store) of an AWS instance type
+func instanceLocalStorage(instanceType aws.InstanceType) localStorage {
+	size := decimal.NewFromInt(instanceType.InstanceStorage.SizePerDiskGB).Mul(decimal.NewFromInt32(instanceType.InstanceStorage.Count))
+	switch strings.ToLower(instanceType.InstanceStorage.Type) {
+	case "ssd":
+		return localStorage{hdd: decimal.Zero, ssd: size}
+	case "hdd":
+		return localStorage{hdd: size, ssd: decimal.Zero}
+	default:
+		return localStorage{hdd: decimal.Zero, ssd: decimal.Zero}
+	}
+}
+
+func sizeReason(vCPUs int32, memoryMb int32, specs *resources.ComputeResourceSpecs) string {
+	if vCPUs == specs.VCPUs && memoryMb == specs.MemoryMb {
+		return "same size"
+	}
+	return "larger size"
+}
+
+// withSpecs returns a copy of the resource with another machine type
+func withSpecs(resource resources.ComputeResource, machineType string, vCPUs int32, memoryMb int32, cpuType string, hddStorage decimal.Decimal, ssdStorage decimal.Decimal) resources.ComputeResource {
+	specs := *resource.Specs
+	specs.MachineType = machineType
+	specs.VCPUs = vCPUs
+	specs.MemoryMb = memoryMb
+	specs.CPUType = cpuType
+	specs.HddStorage = hddStorage
+	specs.SsdStorage = ssdStorage
+	return resources.ComputeResource{
+		Identification: resource.Identification,
+		Specs:          &specs,
+	}
+}
+
+// sameStrings returns true if both lists contain the same strings, whatever their order
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]string{}, a...)
+	sortedB := append([]string{}, b...)
+	sort.Strings(sortedA)
+	sort.Strings(sortedB)
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
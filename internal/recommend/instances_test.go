@@ -0,0 +1,157 @@
+package recommend
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	_ "github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func estimateReport(computeResources ...resources.ComputeResource) estimation.EstimationReport {
+	report := estimation.EstimationReport{}
+	for _, resource := range computeResources {
+		estimationResource, err := estimate.EstimateSupportedResource(viper.GetViper(), resource, nil, "")
+		if err != nil {
+			panic(err)
+		}
+		report.Resources = append(report.Resources, *estimationResource)
+	}
+	return report
+}
+
+func TestRecommendInstances_GCP(t *testing.T) {
+	current := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:           "google_compute_instance.foo",
+			Provider:          providers.GCP,
+			Region:            "europe-west9",
+			Count:             2,
+			ReplicationFactor: 1,
+		},
+		Specs: &resources.ComputeResourceSpecs{
+			MachineType: "n1-standard-2",
+			VCPUs:       2,
+			MemoryMb:    7680,
+			CPUType:     "Haswell",
+			HddStorage:  decimal.Zero,
+			SsdStorage:  decimal.NewFromInt(10),
+		},
+	}
+	got := RecommendInstances(viper.GetViper(), estimateReport(current), InstancesOptions{Count: 2})
+
+	assert.Len(t, got.Resources, 1)
+	resource := got.Resources[0]
+	assert.Equal(t, "n1-standard-2", resource.MachineType)
+	assert.Len(t, resource.Alternatives, 2)
+	for _, alternative := range resource.Alternatives {
+		assert.GreaterOrEqual(t, alternative.VCPUs, int32(2))
+		assert.GreaterOrEqual(t, alternative.MemoryMb, int32(7680))
+		assert.True(t, alternative.Power.LessThan(resource.Power))
+		assert.True(t, alternative.Savings.IsPositive())
+	}
+	assert.True(t, resource.Alternatives[0].Power.LessThanOrEqual(resource.Alternatives[1].Power))
+
+	// Numbers match the estimation of plan
+	best := resource.Alternatives[0]
+	candidate := withSpecs(current, best.MachineType, best.VCPUs, best.MemoryMb, best.CPUType, current.Specs.HddStorage, current.Specs.SsdStorage)
+	want, err := estimate.EstimateSupportedResource(viper.GetViper(), candidate, nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, want.Power, best.Power)
+	assert.Equal(t, want.CarbonEmissions.Mul(decimal.NewFromInt(2)), best.CarbonEmissions)
+}
+
+func awsInstance(machineType string, vCPUs int32, memoryMb int32, ssdStorage int64) resources.ComputeResource {
+	return resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:           "aws_instance.foo",
+			Provider:          providers.AWS,
+			Region:            "eu-west-3",
+			Count:             1,
+			ReplicationFactor: 1,
+		},
+		Specs: &resources.ComputeResourceSpecs{
+			MachineType: machineType,
+			VCPUs:       vCPUs,
+			MemoryMb:    memoryMb,
+			HddStorage:  decimal.Zero,
+			SsdStorage:  decimal.NewFromInt(ssdStorage),
+		},
+	}
+}
+
+func TestRecommendInstances_AWSUnusedLocalStorage(t *testing.T) {
+	// Only the EBS root volume is declared, the local SSD of the instance type is unused
+	current := awsInstance("m5d.xlarge", 4, 16384, 8)
+	got := RecommendInstances(viper.GetViper(), estimateReport(current), InstancesOptions{Count: 100})
+
+	assert.Len(t, got.Resources, 1)
+	resource := got.Resources[0]
+	assert.Equal(t, decimal.NewFromInt(150), resource.UnusedLocalStorageGB)
+	withLocalStorage, err := estimate.EstimateSupportedResource(viper.GetViper(), awsInstance("m5d.xlarge", 4, 16384, 8+150), nil, "")
+	assert.NoError(t, err)
+	assert.Equal(t, withLocalStorage.Power, resource.Power)
+
+	var m5 *InstanceAlternative
+	for i, alternative := range resource.Alternatives {
+		assert.True(t, alternative.Savings.IsPositive())
+		if alternative.MachineType == "m5.xlarge" {
+			m5 = &resource.Alternatives[i]
+		}
+	}
+	if assert.NotNil(t, m5) {
+		assert.Equal(t, []string{"same size", "no unused local storage"}, m5.Reasons)
+		want, err := estimate.EstimateSupportedResource(viper.GetViper(), awsInstance("m5.xlarge", 4, 16384, 8), nil, "")
+		assert.NoError(t, err)
+		assert.Equal(t, want.Power, m5.Power)
+	}
+}
+
+func TestRecommendInstances_AWSUsedLocalStorage(t *testing.T) {
+	// EBS root volume and the declared local SSD of the instance type
+	current := awsInstance("m5d.xlarge", 4, 16384, 8+150)
+	got := RecommendInstances(viper.GetViper(), estimateReport(current), InstancesOptions{Count: 100})
+
+	assert.Len(t, got.Resources, 1)
+	resource := got.Resources[0]
+	assert.True(t, resource.UnusedLocalStorageGB.IsZero())
+	for _, alternative := range resource.Alternatives {
+		assert.True(t, alternative.Savings.IsPositive())
+	}
+
+	// Used local storage is never dropped
+	alternatives, unused, err := getAWSAlternatives(viper.GetString("data.path"), current)
+	assert.NoError(t, err)
+	assert.True(t, unused.total().IsZero())
+	assert.NotEmpty(t, alternatives)
+	for _, alternative := range alternatives {
+		assert.NotEqual(t, "m5.xlarge", alternative.resource.Specs.MachineType)
+		assert.True(t, alternative.resource.Specs.SsdStorage.GreaterThanOrEqual(decimal.NewFromInt(8+150)))
+		assert.NotContains(t, alternative.reasons, "no unused local storage")
+	}
+}
+
+func TestRecommendInstances_NoMachineType(t *testing.T) {
+	current := resources.ComputeResource{
+		Identification: &resources.ResourceIdentification{
+			Address:           "google_compute_instance.custom",
+			Provider:          providers.GCP,
+			Region:            "europe-west9",
+			Count:             1,
+			ReplicationFactor: 1,
+		},
+		Specs: &resources.ComputeResourceSpecs{
+			VCPUs:      2,
+			MemoryMb:   4096,
+			HddStorage: decimal.Zero,
+			SsdStorage: decimal.Zero,
+		},
+	}
+	got := RecommendInstances(viper.GetViper(), estimateReport(current), InstancesOptions{Count: 3})
+	assert.Empty(t, got.Resources)
+}
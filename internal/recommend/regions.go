@@ -0,0 +1,210 @@
+package recommend
+
+import (
+	"sort"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/coefficients"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// RegionsOptions is the struct that contains the constraints of region recommendations
+type RegionsOptions struct {
+	Count          int
+	SameCountry    bool
+	SameContinent  bool
+	AllowedRegions []string
+}
+
+// RegionsReport is the struct that contains the region recommendations of all resources
+type RegionsReport struct {
+	Info      estimation.EstimationInfo
+	Resources []ResourceRegions
+}
+
+// ResourceRegions is the struct that contains the region recommendations of a resource
+type ResourceRegions struct {
+	Address             string
+	Provider            providers.Provider
+	Region              string
+	Country             string
+	Continent           string
+	GridCarbonIntensity decimal.Decimal // gCO2eq/kWh
+	CarbonEmissions     decimal.Decimal // all instances of the resource
+	Recommendations     []RegionRecommendation
+}
+
+// RegionRecommendation is the struct that contains the emissions of a resource if moved to another region
+type RegionRecommendation struct {
+	Region              string
+	Location            string
+	Country             string
+	Continent           string
+	GridCarbonIntensity decimal.Decimal // gCO2eq/kWh
+	CarbonEmissions     decimal.Decimal // all instances of the resource
+	Savings             decimal.Decimal
+	SavingsPercent      decimal.Decimal
+}
+
+// GetRegionsOptions reads the region recommendation constraints from config
+func GetRegionsOptions() (RegionsOptions, error) {
+	// Keys are read one by one, UnmarshalKey ignores values of bound flags
+	options := RegionsOptions{
+		Count:          viper.GetInt("recommend.regions.count"),
+		SameCountry:    viper.GetBool("recommend.regions.same_country"),
+		SameContinent:  viper.GetBool("recommend.regions.same_continent"),
+		AllowedRegions: viper.GetStringSlice("recommend.regions.allowed"),
+	}
+	if options.Count <= 0 {
+		return options, errors.Errorf("recommend.regions.count must be positive: %v", options.Count)
+	}
+	return options, nil
+}
+
+// RecommendRegions lists, for each resource of the report, the regions of the same provider with the lowest carbon intensity.
+// Emissions of a resource are proportional to the carbon intensity of its region (power does not depend on the region).
+// Regions are read from the data of config.
+func RecommendRegions(config *viper.Viper, report estimation.EstimationReport, options RegionsOptions) (*RegionsReport, error) {
+	regionsReport := RegionsReport{
+		Info:      report.Info,
+		Resources: []ResourceRegions{},
+	}
+	dataPath := config.GetString("data.path")
+	regionsByProvider := map[providers.Provider][]coefficients.Emissions{}
+	for _, estimationResource := range report.Resources {
+		identification := estimationResource.Resource.GetIdentification()
+		regions, ok := regionsByProvider[identification.Provider]
+		if !ok {
+			var err error
+			regions, err = coefficients.RegionsEmissions(dataPath, identification.Provider)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Cannot get regions of provider %v", identification.Provider)
+			}
+			regionsByProvider[identification.Provider] = regions
+		}
+		resourceRegions, err := recommendResourceRegions(dataPath, estimationResource, regions, options)
+		if err != nil {
+			log.Warnf("Skipping region recommendations of %v: %v", estimationResource.Resource.GetAddress(), err)
+			continue
+		}
+		regionsReport.Resources = append(regionsReport.Resources, *resourceRegions)
+	}
+	sort.Slice(regionsReport.Resources, func(i, j int) bool {
+		return regionsReport.Resources[i].Address < regionsReport.Resources[j].Address
+	})
+	return &regionsReport, nil
+}
+
+func recommendResourceRegions(dataPath string, estimationResource estimation.EstimationResource, regions []coefficients.Emissions, options RegionsOptions) (*ResourceRegions, error) {
+	identification := estimationResource.Resource.GetIdentification()
+	var current *coefficients.Emissions
+	for i := range regions {
+		if regions[i].Region == identification.Region {
+			current = &regions[i]
+		}
+	}
+	if current == nil {
+		return nil, errors.Errorf("Region does not exist: '%v'", identification.Region)
+	}
+	if current.GridCarbonIntensity.IsZero() {
+		return nil, errors.Errorf("Carbon intensity of region %v is zero", identification.Region)
+	}
+	currentLocation, _, err := coefficients.GetRegionLocation(dataPath, identification.Provider, identification.Region)
+	if err != nil {
+		return nil, err
+	}
+	emissions := estimationResource.CarbonEmissions.Mul(estimationResource.TotalCount)
+
+	resourceRegions := ResourceRegions{
+		Address:             estimationResource.Resource.GetAddress(),
+		Provider:            identification.Provider,
+		Region:              identification.Region,
+		Country:             currentLocation.Country,
+		Continent:           currentLocation.Continent,
+		GridCarbonIntensity: current.GridCarbonIntensity,
+		CarbonEmissions:     emissions,
+		Recommendations:     []RegionRecommendation{},
+	}
+	if (options.SameCountry && currentLocation.Country == "") || (options.SameContinent && currentLocation.Continent == "") {
+		return nil, errors.Errorf("Location of region %v is unknown", identification.Region)
+	}
+
+	candidates := []coefficients.Emissions{}
+	for _, region := range regions {
+		if region.Region == identification.Region || !region.GridCarbonIntensity.LessThan(current.GridCarbonIntensity) {
+			continue
+		}
+		allowed, err := isRegionAllowed(dataPath, identification.Provider, region.Region, currentLocation, options)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			continue
+		}
+		candidates = append(candidates, region)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].GridCarbonIntensity.LessThan(candidates[j].GridCarbonIntensity)
+	})
+	if len(candidates) > options.Count {
+		candidates = candidates[:options.Count]
+	}
+
+	for _, candidate := range candidates {
+		location, _, err := coefficients.GetRegionLocation(dataPath, identification.Provider, candidate.Region)
+		if err != nil {
+			return nil, err
+		}
+		candidateEmissions := emissions.Mul(candidate.GridCarbonIntensity).Div(current.GridCarbonIntensity)
+		savings := emissions.Sub(candidateEmissions)
+		savingsPercent := decimal.Zero
+		if !emissions.IsZero() {
+			savingsPercent = savings.Div(emissions).Mul(decimal.NewFromInt(100))
+		}
+		resourceRegions.Recommendations = append(resourceRegions.Recommendations, RegionRecommendation{
+			Region:              candidate.Region,
+			Location:            candidate.Location,
+			Country:             location.Country,
+			Continent:           location.Continent,
+			GridCarbonIntensity: candidate.GridCarbonIntensity,
+			CarbonEmissions:     candidateEmissions,
+			Savings:             savings,
+			SavingsPercent:      savingsPercent,
+		})
+	}
+	return &resourceRegions, nil
+}
+
+// isRegionAllowed checks the data-residency constraints of a candidate region
+func isRegionAllowed(dataPath string, provider providers.Provider, region string, currentLocation coefficients.RegionLocation, options RegionsOptions) (bool, error) {
+	if len(options.AllowedRegions) > 0 && !contains(options.AllowedRegions, region) {
+		return false, nil
+	}
+	if !options.SameCountry && !options.SameContinent {
+		return true, nil
+	}
+	location, ok, err := coefficients.GetRegionLocation(dataPath, provider, region)
+	if err != nil || !ok {
+		return false, err
+	}
+	if options.SameCountry && location.Country != currentLocation.Country {
+		return false, nil
+	}
+	if options.SameContinent && location.Continent != currentLocation.Continent {
+		return false, nil
+	}
+	return true, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package recommend
+
+import (
+	"github.com/spf13/viper"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/resources"
+	_ "github.com/carboniferio/carbonifer/internal/testutils"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+var reportUSEast = estimation.EstimationReport{
+	Resources: []estimation.EstimationResource{
+		{
+			Resource: resources.ComputeResource{
+				Identification: &resources.ResourceIdentification{
+					Address:  "google_compute_instance.us",
+					Provider: providers.GCP,
+					Region:   "us-east1",
+					Count:    2,
+				},
+			},
+			CarbonEmissions: decimal.NewFromInt(434),
+			TotalCount:      decimal.NewFromInt(2),
+		},
+	},
+}
+
+func TestRecommendRegions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options RegionsOptions
+		want    []string
+	}{
+		{
+			name:    "any",
+			options: RegionsOptions{Count: 2},
+			want:    []string{"northamerica-northeast1", "northamerica-northeast2"},
+		},
+		{
+			name:    "same_country",
+			options: RegionsOptions{Count: 2, SameCountry: true},
+			want:    []string{"us-west1", "us-west2"},
+		},
+		{
+			name:    "same_continent",
+			options: RegionsOptions{Count: 3, SameContinent: true},
+			want:    []string{"northamerica-northeast1", "northamerica-northeast2", "us-west1"},
+		},
+		{
+			name:    "allowed",
+			options: RegionsOptions{Count: 3, AllowedRegions: []string{"us-east4", "europe-west1", "us-west3"}},
+			want:    []string{"europe-west1", "us-east4"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RecommendRegions(viper.GetViper(), reportUSEast, tt.options)
+			assert.NoError(t, err)
+			assert.Len(t, got.Resources, 1)
+			regions := []string{}
+			for _, recommendation := range got.Resources[0].Recommendations {
+				regions = append(regions, recommendation.Region)
+			}
+			assert.Equal(t, tt.want, regions)
+		})
+	}
+}
+
+func TestRecommendRegionsSavings(t *testing.T) {
+	got, err := RecommendRegions(viper.GetViper(), reportUSEast, RegionsOptions{Count: 1, SameCountry: true})
+	assert.NoError(t, err)
+	resourceRegions := got.Resources[0]
+	assert.Equal(t, "United States", resourceRegions.Country)
+	assert.Equal(t, "868", resourceRegions.CarbonEmissions.String())
+
+	// us-west1: 60 gCO2eq/kWh instead of 434
+	recommendation := resourceRegions.Recommendations[0]
+	assert.Equal(t, "120", recommendation.CarbonEmissions.String())
+	assert.Equal(t, "748", recommendation.Savings.String())
+	assert.Equal(t, "86.18", recommendation.SavingsPercent.StringFixed(2))
+}
@@ -7,12 +7,13 @@ import (
 
 // ComputeResourceSpecs is the struct that contains the specs of a compute resource
 type ComputeResourceSpecs struct {
-	GpuTypes   []string
-	HddStorage decimal.Decimal
-	SsdStorage decimal.Decimal
-	MemoryMb   int32
-	VCPUs      int32
-	CPUType    string
+	GpuTypes    []string
+	HddStorage  decimal.Decimal
+	SsdStorage  decimal.Decimal
+	MemoryMb    int32
+	VCPUs       int32
+	CPUType     string
+	MachineType string `json:",omitempty"`
 }
 
 // ResourceIdentification is the struct that contains the identification of a resource
@@ -74,3 +75,11 @@ type Resource interface {
 	GetIdentification() *ResourceIdentification
 	GetAddress() string
 }
+
+// PropertySource is the mapping path that produced a property value of a resource
+type PropertySource struct {
+	Property  string // Property of the mapping (ex: vCPUs, storage.size)
+	Path      string // jq path of the mapping, or "default"
+	Reference string `json:",omitempty"` // Data file or general mapping used to resolve the value
+	Value     string
+}
@@ -0,0 +1,78 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ResourceError is the error that prevented reading or estimating a resource
+type ResourceError struct {
+	Address string
+	Err     error
+}
+
+func (e ResourceError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Address, e.Err)
+}
+
+// Unwrap returns the error of the resource
+func (e ResourceError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON writes the error of the resource as its message
+func (e ResourceError) MarshalJSON() ([]byte, error) {
+	message := ""
+	if e.Err != nil {
+		message = e.Err.Error()
+	}
+	return json.Marshal(struct {
+		Address string
+		Error   string
+	}{e.Address, message})
+}
+
+// UnmarshalJSON reads back a resource error, its error is only the message
+func (e *ResourceError) UnmarshalJSON(data []byte) error {
+	var resourceError struct {
+		Address string
+		Error   string
+	}
+	if err := json.Unmarshal(data, &resourceError); err != nil {
+		return err
+	}
+	e.Address = resourceError.Address
+	e.Err = errors.New(resourceError.Error)
+	return nil
+}
+
+// ResourceErrors are the errors of the resources that could not be read, the other resources are still usable
+type ResourceErrors []ResourceError
+
+func (e ResourceErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, resourceError := range e {
+		messages = append(messages, resourceError.Error())
+	}
+	return fmt.Sprintf("%v resources could not be read: %v", len(e), strings.Join(messages, "; "))
+}
+
+// SortResourceErrors sorts resource errors by resource address
+func SortResourceErrors(resourceErrors []ResourceError) {
+	sort.SliceStable(resourceErrors, func(i, j int) bool {
+		return resourceErrors[i].Address < resourceErrors[j].Address
+	})
+}
+
+// SplitResourceErrors returns the resource errors of err, or err itself if it is not made of resource errors only
+func SplitResourceErrors(err error) (ResourceErrors, error) {
+	var resourceErrors ResourceErrors
+	if errors.As(err, &resourceErrors) {
+		return resourceErrors, nil
+	}
+	return nil, err
+}
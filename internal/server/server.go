@@ -0,0 +1,282 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/utils"
+	pkgestimate "github.com/carboniferio/carbonifer/pkg/estimate"
+	"github.com/carboniferio/carbonifer/pkg/providers"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// maxBodySize is the maximum size of a request body (terraform plans of large projects can be big)
+const maxBodySize = 64 << 20
+
+// OverridableConfig lists the config keys that can be overridden per request
+var OverridableConfig = []string{
+	"unit.time",
+	"unit.carbon",
+	"provider.gcp.avg_cpu_use",
+	"provider.gcp.avg_gpu_use",
+	"provider.gcp.avg_autoscaler_size_percent",
+	"provider.aws.avg_cpu_use",
+	"provider.aws.avg_gpu_use",
+	"provider.aws.avg_autoscaler_size_percent",
+}
+
+// Server is the HTTP API estimating terraform plans and instance types
+type Server struct {
+	version string
+}
+
+// PlanRequest is the body of a plan estimation request, when config is overridden in the body
+type PlanRequest struct {
+	Plan   map[string]interface{} `json:"plan"`
+	Config map[string]interface{} `json:"config"`
+}
+
+// InstanceRequest is the body of an instance type estimation request
+type InstanceRequest struct {
+	InstanceType string                 `json:"instance_type"`
+	Zone         string                 `json:"zone"`
+	Provider     string                 `json:"provider"`
+	Config       map[string]interface{} `json:"config"`
+}
+
+// ErrorResponse is the body of a failed request
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// NewServer creates the HTTP API of carbonifer
+func NewServer(version string) *Server {
+	return &Server{version: version}
+}
+
+// Handler returns the routes of the HTTP API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/version", s.handleVersion)
+	mux.HandleFunc("/plan", s.handlePlan)
+	mux.HandleFunc("/instance", s.handleInstance)
+	return mux
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("Method not allowed: %v", r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("Method not allowed: %v", r.Method))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"version": s.version})
+}
+
+// handlePlan estimates a terraform plan (JSON) posted as body, or as "plan" of a PlanRequest
+func (s *Server) handlePlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("Method not allowed: %v", r.Method))
+		return
+	}
+	body, ok := readBody(w, r)
+	if !ok {
+		return
+	}
+	var tfPlan map[string]interface{}
+	if err := json.Unmarshal(body, &tfPlan); err != nil {
+		writeError(w, http.StatusBadRequest, errors.Wrap(err, "Body is not a terraform plan JSON"))
+		return
+	}
+	config := map[string]interface{}{}
+	if _, isPlan := tfPlan["planned_values"]; !isPlan {
+		var request PlanRequest
+		if err := json.Unmarshal(body, &request); err != nil || request.Plan == nil {
+			writeError(w, http.StatusBadRequest, errors.New("Body is not a terraform plan JSON: planned_values not found"))
+			return
+		}
+		tfPlan = request.Plan
+		config = request.Config
+	}
+	overrides, err := getOverrides(r, config)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// Plans of clients are read as is, terraform is never run on the server (only in the config of the request)
+	overrides["offline"] = true
+
+	s.estimate(w, overrides, func(config *viper.Viper) (interface{}, error) {
+		return estimate.EstimatePlan(config, nil, &tfPlan, nil, "")
+	})
+}
+
+// handleInstance estimates an instance type, from query parameters (GET) or an InstanceRequest (POST)
+func (s *Server) handleInstance(w http.ResponseWriter, r *http.Request) {
+	var request InstanceRequest
+	switch r.Method {
+	case http.MethodGet:
+		request.InstanceType = r.URL.Query().Get("instance_type")
+		request.Zone = r.URL.Query().Get("zone")
+		request.Provider = r.URL.Query().Get("provider")
+	case http.MethodPost:
+		body, ok := readBody(w, r)
+		if !ok {
+			return
+		}
+		if err := json.Unmarshal(body, &request); err != nil {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "Cannot parse instance request"))
+			return
+		}
+	default:
+		writeError(w, http.StatusMethodNotAllowed, errors.Errorf("Method not allowed: %v", r.Method))
+		return
+	}
+	if request.InstanceType == "" || request.Zone == "" || request.Provider == "" {
+		writeError(w, http.StatusBadRequest, errors.New("instance_type, zone and provider are required"))
+		return
+	}
+	provider, err := providers.ParseProvider(request.Provider)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	overrides, err := getOverrides(r, request.Config)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.estimate(w, overrides, func(config *viper.Viper) (interface{}, error) {
+		return pkgestimate.GetEstimationFromInstanceTypeWithConfig(config, request.InstanceType, request.Zone, provider)
+	})
+}
+
+// readBody reads the body of a request, up to maxBodySize. It writes the error response and returns false if the
+// body cannot be read.
+func readBody(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			writeError(w, http.StatusRequestEntityTooLarge, errors.Errorf("Body is larger than %v bytes", maxBytesError.Limit))
+		} else {
+			writeError(w, http.StatusBadRequest, errors.Wrap(err, "Cannot read body"))
+		}
+		return nil, false
+	}
+	return body, true
+}
+
+// estimate runs an estimation with a copy of the config with overrides, requests never change the config of the
+// server, and writes its result
+func (s *Server) estimate(w http.ResponseWriter, overrides map[string]interface{}, estimation func(config *viper.Viper) (interface{}, error)) {
+	result, err := estimation(utils.NewConfig(overrides))
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// getOverrides reads config overrides from query parameters (ex: ?unit.time=m), then from the request body
+func getOverrides(r *http.Request, bodyConfig map[string]interface{}) (map[string]interface{}, error) {
+	overrides := map[string]interface{}{}
+	for key, values := range r.URL.Query() {
+		if !isOverridable(key) {
+			continue
+		}
+		overrides[key] = values[len(values)-1]
+	}
+	for key, value := range bodyConfig {
+		if !isOverridable(key) {
+			return nil, errors.Errorf("Config cannot be overridden: %v (allowed: %v)", key, strings.Join(OverridableConfig, ", "))
+		}
+		overrides[key] = value
+	}
+
+	for key, value := range overrides {
+		parsed, err := parseOverride(key, value)
+		if err != nil {
+			return nil, err
+		}
+		overrides[key] = parsed
+	}
+	return overrides, nil
+}
+
+func isOverridable(key string) bool {
+	for _, overridable := range OverridableConfig {
+		if key == overridable {
+			return true
+		}
+	}
+	return false
+}
+
+// parseOverride validates the value of an overridden config
+func parseOverride(key string, value interface{}) (interface{}, error) {
+	valueStr := fmt.Sprintf("%v", value)
+	switch key {
+	case "unit.time":
+		if !isOneOf(valueStr, "h", "d", "m", "y") {
+			return nil, errors.Errorf("Invalid %v: %v (allowed: h, d, m, y)", key, valueStr)
+		}
+		return valueStr, nil
+	case "unit.carbon":
+		if !isOneOf(valueStr, "g", "kg") {
+			return nil, errors.Errorf("Invalid %v: %v (allowed: g, kg)", key, valueStr)
+		}
+		return valueStr, nil
+	default:
+		valueFloat, err := strconv.ParseFloat(valueStr, 64)
+		if err != nil || valueFloat < 0 || valueFloat > 1 {
+			return nil, errors.Errorf("Invalid %v: %v (must be between 0 and 1)", key, valueStr)
+		}
+		return valueFloat, nil
+	}
+}
+
+func isOneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	bodyBytes, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		log.Error(err)
+		status = http.StatusInternalServerError
+		bodyBytes, _ = json.Marshal(ErrorResponse{Error: err.Error()})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, err = io.Copy(w, bytes.NewReader(bodyBytes))
+	if err != nil {
+		log.Error(err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	log.Warn(err)
+	writeJSON(w, status, ErrorResponse{Error: err.Error()})
+}
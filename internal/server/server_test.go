@@ -0,0 +1,161 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/testutils"
+	_ "github.com/carboniferio/carbonifer/internal/testutils"
+	pkgestimate "github.com/carboniferio/carbonifer/pkg/estimate"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealthAndVersion(t *testing.T) {
+	handler := NewServer("1.2.3").Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"status": "ok"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/version", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"version": "1.2.3"}`, recorder.Body.String())
+}
+
+func TestPlan(t *testing.T) {
+	handler := NewServer("dev").Handler()
+	planBytes, err := os.ReadFile(path.Join(testutils.RootDir, "test/terraform/planDelta/plan.json"))
+	assert.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/plan?unit.time=m", bytes.NewReader(planBytes)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var report estimation.EstimationReport
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Equal(t, "gCO2eq/m", report.Info.UnitCarbonEmissionsTime)
+	assert.Len(t, report.Resources, 4)
+	assert.Equal(t, "h", viper.GetString("unit.time"))
+
+	// Config overridden in body
+	var tfPlan map[string]interface{}
+	assert.NoError(t, json.Unmarshal(planBytes, &tfPlan))
+	body, err := json.Marshal(PlanRequest{Plan: tfPlan, Config: map[string]interface{}{"unit.carbon": "kg"}})
+	assert.NoError(t, err)
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/plan", bytes.NewReader(body)))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	assert.Equal(t, "kgCO2eq/h", report.Info.UnitCarbonEmissionsTime)
+}
+
+func TestPlanOffline(t *testing.T) {
+	handler := NewServer("dev").Handler()
+	viper.Set("offline", false)
+	// The region is only known from a reference, which would be evaluated by terraform console
+	body := `{
+		"planned_values": {"root_module": {"resources": [
+			{"address": "aws_instance.web", "mode": "managed", "type": "aws_instance", "name": "web", "provider_name": "registry.terraform.io/hashicorp/aws", "values": {"instance_type": "t2.micro"}}
+		]}},
+		"configuration": {"provider_config": {"aws": {"name": "aws", "expressions": {"region": {"references": ["var.region"]}}}}}
+	}`
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/plan", bytes.NewReader([]byte(body))))
+	assert.Contains(t, recorder.Body.String(), "cannot run terraform console offline")
+	assert.False(t, viper.GetBool("offline"))
+}
+
+func TestPlanBadRequest(t *testing.T) {
+	handler := NewServer("dev").Handler()
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{"not_json", http.MethodPost, "/plan", "nope", http.StatusBadRequest},
+		{"not_plan", http.MethodPost, "/plan", `{"foo": "bar"}`, http.StatusBadRequest},
+		{"bad_unit", http.MethodPost, "/plan?unit.time=w", `{"planned_values": {}}`, http.StatusBadRequest},
+		{"not_overridable", http.MethodPost, "/plan", `{"plan": {"planned_values": {}}, "config": {"data.path": "/tmp"}}`, http.StatusBadRequest},
+		{"bad_method", http.MethodGet, "/plan", "", http.StatusMethodNotAllowed},
+		{"too_large", http.MethodPost, "/plan", `{"planned_values": "` + strings.Repeat("x", maxBodySize) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(tt.method, tt.target, bytes.NewReader([]byte(tt.body))))
+			assert.Equal(t, tt.status, recorder.Code)
+		})
+	}
+}
+
+func TestPlan_ConcurrentOverrides(t *testing.T) {
+	handler := NewServer("dev").Handler()
+	planBytes, err := os.ReadFile(path.Join(testutils.RootDir, "test/terraform/planDelta/plan.json"))
+	assert.NoError(t, err)
+
+	// Requests with different overrides run at the same time, each with its own config
+	units := []string{"m", "d", "y", "h"}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(unitTime string) {
+			defer wg.Done()
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/plan?unit.carbon=kg&unit.time="+unitTime, bytes.NewReader(planBytes)))
+			assert.Equal(t, http.StatusOK, recorder.Code)
+			var report estimation.EstimationReport
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+			assert.Equal(t, "kgCO2eq/"+unitTime, report.Info.UnitCarbonEmissionsTime)
+		}(units[i%len(units)])
+	}
+	wg.Wait()
+
+	// Overrides are not left in the config of the server
+	assert.Equal(t, "h", viper.GetString("unit.time"))
+	assert.Equal(t, "g", viper.GetString("unit.carbon"))
+	assert.False(t, viper.GetBool("offline"))
+}
+
+func TestInstance(t *testing.T) {
+	handler := NewServer("dev").Handler()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/instance?instance_type=e2-standard-2&zone=europe-west9&provider=gcp", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var got pkgestimate.EstimationReport
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	want, err := pkgestimate.GetEstimationFromInstanceType("e2-standard-2", "europe-west9", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, want.CarbonEmissions.String(), got.CarbonEmissions.String())
+
+	body := `{"instance_type": "e2-standard-2", "zone": "europe-west9", "provider": "GCP", "config": {"provider.gcp.avg_cpu_use": 1}}`
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/instance", bytes.NewReader([]byte(body))))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var gotFullCPU pkgestimate.EstimationReport
+	assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &gotFullCPU))
+	assert.True(t, gotFullCPU.CarbonEmissions.GreaterThan(got.CarbonEmissions))
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/instance?instance_type=e2-standard-2&provider=gcp", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	// Estimation failures are returned as errors
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/instance?instance_type=e2-standard-2&zone=unknown-region&provider=gcp", nil))
+	assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "unknown-region")
+}
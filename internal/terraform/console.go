@@ -0,0 +1,246 @@
+package terraform
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// consoleBatchMaxLength is the max length of the line sent to terraform console, which reads lines up to 64KB
+const consoleBatchMaxLength = 32 * 1024
+
+// consoleTimeoutRetries is the number of times expressions of a timed out terraform console run are evaluated again,
+// the next times they are requested
+const consoleTimeoutRetries = 1
+
+// ConsoleSession evaluates expressions with terraform console in a working directory and caches results per expression.
+// In piped mode, terraform console only prints the result of the last line and stops at the first error, so the
+// expressions not in cache are evaluated together, as a single tuple, in one terraform console run. For the same
+// reason, a process cannot be kept to evaluate expressions one after the other.
+type ConsoleSession struct {
+	dir      string
+	execPath string
+	timeout  time.Duration
+	mutex    sync.Mutex
+	results  map[string]consoleResult
+}
+
+type consoleResult struct {
+	value    string
+	err      error
+	timeouts int
+}
+
+// retry returns true if the evaluation timed out and can be tried again
+func (r consoleResult) retry() bool {
+	return r.timeouts > 0 && r.timeouts <= consoleTimeoutRetries
+}
+
+var consoleSessions = map[string]*ConsoleSession{}
+var consoleSessionsMutex sync.Mutex
+
+// GetConsoleSession returns the console session of the terraform working directory
+func GetConsoleSession() (*ConsoleSession, error) {
+	if viper.GetBool("offline") {
+		return nil, errors.New("cannot run terraform console offline")
+	}
+	tf, err := GetTerraformExec()
+	if err != nil {
+		return nil, err
+	}
+	consoleSessionsMutex.Lock()
+	defer consoleSessionsMutex.Unlock()
+	session, ok := consoleSessions[tf.WorkingDir()]
+	if !ok {
+		session = &ConsoleSession{
+			dir:      tf.WorkingDir(),
+			execPath: tf.ExecPath(),
+			timeout:  viper.GetDuration("terraform.console_timeout"),
+			results:  map[string]consoleResult{},
+		}
+		consoleSessions[tf.WorkingDir()] = session
+	}
+	return session, nil
+}
+
+func resetConsoleSessions() {
+	consoleSessionsMutex.Lock()
+	defer consoleSessionsMutex.Unlock()
+	consoleSessions = map[string]*ConsoleSession{}
+}
+
+// RunTerraformConsole returns the value of an expression evaluated by terraform console
+func RunTerraformConsole(command string) (*string, error) {
+	session, err := GetConsoleSession()
+	if err != nil {
+		return nil, err
+	}
+	return session.Evaluate(command)
+}
+
+// Evaluate returns the value of an expression, see EvaluateAll
+func (s *ConsoleSession) Evaluate(expression string) (*string, error) {
+	s.EvaluateAll(expression)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	result := s.results[expression]
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &result.value, nil
+}
+
+// EvaluateAll evaluates the expressions not in cache in as few terraform console runs as possible. Expressions of a
+// timed out run are cached as failed once they timed out more than consoleTimeoutRetries times.
+// It returns the values of the expressions that could be evaluated: strings as is, other values as JSON.
+func (s *ConsoleSession) EvaluateAll(expressions ...string) map[string]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pending := []string{}
+	for _, expression := range expressions {
+		if result, ok := s.results[expression]; (!ok || result.retry()) && !contains(pending, expression) {
+			pending = append(pending, expression)
+		}
+	}
+
+	batch := []string{}
+	batchLength := 0
+	for _, expression := range pending {
+		if len(batch) > 0 && batchLength+len(expression) > consoleBatchMaxLength {
+			s.evaluateBatch(batch)
+			batch = []string{}
+			batchLength = 0
+		}
+		batch = append(batch, expression)
+		batchLength += len(expression) + len("jsonencode(try(, null)), ")
+	}
+	if len(batch) > 0 {
+		s.evaluateBatch(batch)
+	}
+
+	values := map[string]string{}
+	for _, expression := range expressions {
+		if result := s.results[expression]; result.err == nil {
+			values[expression] = result.value
+		}
+	}
+	return values
+}
+
+// evaluateBatch evaluates expressions in one terraform console run, a batch failing because of one
+// invalid expression (reference to an undeclared resource...) is split until the invalid expression is found
+func (s *ConsoleSession) evaluateBatch(expressions []string) {
+	elements := make([]string, len(expressions))
+	for i, expression := range expressions {
+		elements[i] = fmt.Sprintf("jsonencode(try(%v, null))", expression)
+	}
+	output, err := s.run("[" + strings.Join(elements, ", ") + "]")
+	if err == nil {
+		var elementOutputs []string
+		elementOutputs, err = parseConsoleTuple(output, len(expressions))
+		if err == nil {
+			for i, expression := range expressions {
+				value, err := parseConsoleValue(elementOutputs[i])
+				s.results[expression] = consoleResult{value: value, err: err}
+			}
+			return
+		}
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		log.Warnf("%v, %v expressions not evaluated", err, len(expressions))
+		for _, expression := range expressions {
+			s.results[expression] = consoleResult{err: err, timeouts: s.results[expression].timeouts + 1}
+		}
+		return
+	}
+	if len(expressions) == 1 {
+		s.results[expressions[0]] = consoleResult{err: err}
+		return
+	}
+	s.evaluateBatch(expressions[:len(expressions)/2])
+	s.evaluateBatch(expressions[len(expressions)/2:])
+}
+
+func (s *ConsoleSession) run(line string) (string, error) {
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	cmd := exec.CommandContext(ctx, s.execPath, "console")
+	cmd.Dir = s.dir
+	cmd.Stdin = strings.NewReader(line + "\n")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Don't wait for processes started by terraform (providers...) once killed
+	cmd.WaitDelay = time.Second
+
+	log.Debugf("Running terraform console in %v: %v", s.dir, line)
+	err := cmd.Run()
+	if ctx.Err() != nil {
+		return "", errors.Wrapf(ctx.Err(), "terraform console timed out after %v", s.timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error running terraform console: %w\nstderr: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// parseConsoleTuple returns the elements of a tuple printed by terraform console, one per line
+func parseConsoleTuple(output string, length int) ([]string, error) {
+	lines := strings.Split(output, "\n")
+	if len(lines) < 2 || strings.TrimSpace(lines[0]) != "[" || strings.TrimSpace(lines[len(lines)-1]) != "]" {
+		return nil, errors.Errorf("Unexpected terraform console output: %v", output)
+	}
+	elements := []string{}
+	for _, line := range lines[1 : len(lines)-1] {
+		elements = append(elements, strings.TrimSuffix(strings.TrimSpace(line), ","))
+	}
+	if len(elements) != length {
+		return nil, errors.Errorf("Expected %v values from terraform console, got %v", length, len(elements))
+	}
+	return elements, nil
+}
+
+// parseConsoleValue returns the value of a jsonencode result printed by terraform console
+func parseConsoleValue(element string) (string, error) {
+	encoded, err := strconv.Unquote(element)
+	if err != nil {
+		// (known after apply), (sensitive value)...
+		return "", errors.Errorf("value is %v", element)
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(encoded), &value); err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case nil:
+		return "", errors.New("value is null")
+	case string:
+		return v, nil
+	default:
+		return encoded, nil
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeConsole is a terraform exec printing "<expression>" for each expression of the tuple it reads,
+// failing on undeclared references and sleeping on slow ones
+const fakeConsole = `#!/bin/sh
+echo run >> "$(dirname "$0")/runs"
+read line
+case "$line" in *slow*) sleep 5;; esac
+case "$line" in *undeclared*) echo "Error: Reference to undeclared resource" >&2; exit 1;; esac
+echo "["
+echo "$line" | sed -e 's/^\[//' -e 's/\]$//' -e 's/, jsonencode/\njsonencode/g' |
+  sed -e 's/^jsonencode(try(\(.*unknown.*\), null))$/  (known after apply),/' \
+      -e 's/^jsonencode(try(\(.*\), null))$/  "\\"\1\\"",/'
+echo "]"
+`
+
+func newFakeConsoleSession(t *testing.T, timeout time.Duration) (*ConsoleSession, func() int) {
+	dir := t.TempDir()
+	execPath := filepath.Join(dir, "terraform")
+	err := os.WriteFile(execPath, []byte(fakeConsole), 0755)
+	assert.NoError(t, err)
+	session := &ConsoleSession{
+		dir:      dir,
+		execPath: execPath,
+		timeout:  timeout,
+		results:  map[string]consoleResult{},
+	}
+	runs := func() int {
+		content, _ := os.ReadFile(filepath.Join(dir, "runs"))
+		return strings.Count(string(content), "run")
+	}
+	return session, runs
+}
+
+func TestConsoleSession_EvaluateAll(t *testing.T) {
+	session, runs := newFakeConsoleSession(t, time.Minute)
+
+	values := session.EvaluateAll("var.a", "local.b", "aws_instance.unknown.id", "var.a")
+	assert.Equal(t, map[string]string{"var.a": "var.a", "local.b": "local.b"}, values)
+	assert.Equal(t, 1, runs())
+
+	// Cached
+	value, err := session.Evaluate("local.b")
+	assert.NoError(t, err)
+	assert.Equal(t, "local.b", *value)
+	_, err = session.Evaluate("aws_instance.unknown.id")
+	assert.Error(t, err)
+	assert.Equal(t, 1, runs())
+}
+
+func TestConsoleSession_InvalidExpression(t *testing.T) {
+	session, runs := newFakeConsoleSession(t, time.Minute)
+
+	values := session.EvaluateAll("var.a", "var.b", "var.c", "google_compute_instance.undeclared.id")
+	assert.Equal(t, map[string]string{"var.a": "var.a", "var.b": "var.b", "var.c": "var.c"}, values)
+	// whole batch, then [a, b] and [c, undeclared], then [c] and [undeclared]
+	assert.Equal(t, 5, runs())
+	_, err := session.Evaluate("google_compute_instance.undeclared.id")
+	assert.ErrorContains(t, err, "undeclared")
+}
+
+func TestConsoleSession_Timeout(t *testing.T) {
+	session, runs := newFakeConsoleSession(t, 100*time.Millisecond)
+
+	values := session.EvaluateAll("var.a", "var.slow")
+	assert.Empty(t, values)
+	// A timed out batch is not split
+	assert.Equal(t, 1, runs())
+
+	// Timed out expressions are evaluated again
+	value, err := session.Evaluate("var.a")
+	assert.NoError(t, err)
+	assert.Equal(t, "var.a", *value)
+	assert.Equal(t, 2, runs())
+	_, err = session.Evaluate("var.slow")
+	assert.ErrorContains(t, err, "timed out")
+	assert.Equal(t, 3, runs())
+
+	// until they timed out too many times
+	_, err = session.Evaluate("var.slow")
+	assert.ErrorContains(t, err, "timed out")
+	assert.Equal(t, 3, runs())
+}
+
+func TestParseConsoleValue(t *testing.T) {
+	value, err := parseConsoleValue(`"\"n2-standard-2\""`)
+	assert.NoError(t, err)
+	assert.Equal(t, "n2-standard-2", value)
+	value, err = parseConsoleValue(`"[\"a\",2]"`)
+	assert.NoError(t, err)
+	assert.Equal(t, `["a",2]`, value)
+	_, err = parseConsoleValue(`"null"`)
+	assert.Error(t, err)
+	_, err = parseConsoleValue("(sensitive value)")
+	assert.Error(t, err)
+}
@@ -11,3 +11,19 @@ type ProviderAuthError struct {
 func (e *ProviderAuthError) Error() string {
 	return fmt.Sprintf("Missing/Invalid provider credentials, please check or set your credentials : %v", e.ParentError)
 }
+
+// TerraformError is the error of a terraform command (install, init, validate, plan, show...)
+type TerraformError struct {
+	Command     string
+	ParentError error
+}
+
+// Error returns the error of a terraform command
+func (e *TerraformError) Error() string {
+	return fmt.Sprintf("terraform %v failed: %v", e.Command, e.ParentError)
+}
+
+// Unwrap returns the error of the terraform command
+func (e *TerraformError) Unwrap() error {
+	return e.ParentError
+}
@@ -0,0 +1,1150 @@
+package terraform
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/tryfunc"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// UnresolvedAttribute is an attribute of the terraform files whose value cannot be known without running terraform
+type UnresolvedAttribute struct {
+	Address   string
+	Attribute string
+	Reason    string
+}
+
+func (u UnresolvedAttribute) String() string {
+	return fmt.Sprintf("%v.%v: %v", u.Address, u.Attribute, u.Reason)
+}
+
+// Meta arguments are not attributes of resources and modules
+var resourceMetaArguments = map[string]bool{"count": true, "for_each": true, "provider": true, "depends_on": true}
+var resourceMetaBlocks = map[string]bool{"lifecycle": true, "provisioner": true, "connection": true}
+var moduleMetaArguments = map[string]bool{"source": true, "version": true, "providers": true, "count": true, "for_each": true, "depends_on": true}
+
+type offlineParser struct {
+	parser            *hclparse.Parser
+	rootDir           string
+	workspace         string
+	requiredProviders map[string]string
+	providerConfig    map[string]interface{}
+	configuration     []interface{}
+	unresolved        []UnresolvedAttribute
+	providerDefaults  map[string]map[string]cty.Value
+	defaultedValues   []defaultedValues
+}
+
+// defaultedValues are values of a resource that can get defaults from its provider configuration
+type defaultedValues struct {
+	resourceType string
+	providerKey  string
+	values       map[string]interface{}
+}
+
+type offlineModule struct {
+	address             string
+	dir                 string
+	rootDir             string
+	workspace           string
+	variables           map[string]cty.Value
+	variableConfigs     map[string]interface{} // configuration of variables, as in the configuration of plans
+	locals              map[string]cty.Value
+	unknownReasons      map[string]string // why variables and locals are unknown, empty if known after apply
+	managedTypes        map[string]bool
+	modules             map[string]cty.Value
+	moduleOutputReasons map[string]string
+	resources           []interface{}
+	dataSources         []interface{}
+	children            []*offlineModule
+	outputs             map[string]cty.Value
+	outputReasons       map[string]string
+}
+
+type offlineInstance struct {
+	key  interface{}
+	vars map[string]cty.Value
+}
+
+// OfflinePlan builds a plan JSON by parsing the terraform files of a directory, without terraform, providers or credentials.
+// Variables, locals, count and for_each are evaluated when they are static, attributes known only after apply
+// (ids of other resources...) are set as references in the configuration, like in a plan.
+// Var files, variables and workspace of the settings are used, targets are not supported.
+// It returns the attributes that cannot be resolved offline.
+func OfflinePlan(dir string, settings PlanSettings) (*map[string]interface{}, []UnresolvedAttribute, error) {
+	workspace := settings.Workspace
+	if workspace == "" {
+		workspace = "default"
+	}
+	if len(settings.Targets) > 0 {
+		log.Warnf("Targets are ignored offline, all resources of %v are estimated", dir)
+	}
+	p := &offlineParser{
+		parser:            hclparse.NewParser(),
+		rootDir:           dir,
+		workspace:         workspace,
+		requiredProviders: map[string]string{},
+		providerConfig:    map[string]interface{}{},
+		configuration:     []interface{}{},
+		unresolved:        []UnresolvedAttribute{},
+		providerDefaults:  map[string]map[string]cty.Value{},
+	}
+	rootVariables, err := p.readRootVariables(dir, settings)
+	if err != nil {
+		return nil, nil, err
+	}
+	root, err := p.loadModule("", dir, rootVariables, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	p.setProviderDefaults()
+
+	variables := map[string]interface{}{}
+	for name, value := range root.variables {
+		if value.IsWhollyKnown() {
+			variables[name] = map[string]interface{}{"value": ctyToJSON(value)}
+		}
+	}
+	tfPlan := map[string]interface{}{
+		"format_version": "1.2",
+		"variables":      variables,
+		"planned_values": map[string]interface{}{
+			"root_module": root.toJSON(false),
+		},
+		"prior_state": map[string]interface{}{
+			"values": map[string]interface{}{
+				"root_module": root.toJSON(true),
+			},
+		},
+		"configuration": map[string]interface{}{
+			"provider_config": p.providerConfig,
+			// All resources, with their full address, are in the root module
+			"root_module": map[string]interface{}{
+				"resources": p.configuration,
+				"variables": root.variableConfigs,
+			},
+		},
+	}
+	return &tfPlan, p.unresolved, nil
+}
+
+// readRootVariables reads the values of root variables from TF_VAR_ environment variables,
+// terraform.tfvars, *.auto.tfvars files, var files and variables of settings, in the precedence order of terraform
+func (p *offlineParser) readRootVariables(dir string, settings PlanSettings) (map[string]cty.Value, error) {
+	variables := map[string]cty.Value{}
+	for _, env := range os.Environ() {
+		if strings.HasPrefix(env, "TF_VAR_") {
+			name, value, _ := strings.Cut(strings.TrimPrefix(env, "TF_VAR_"), "=")
+			variables[name] = cty.StringVal(value)
+		}
+	}
+
+	files := []string{}
+	for _, name := range []string{"terraform.tfvars", "terraform.tfvars.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			files = append(files, filepath.Join(dir, name))
+		}
+	}
+	autoFiles, err := filepath.Glob(filepath.Join(dir, "*.auto.tfvars*"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(autoFiles)
+	files = append(files, autoFiles...)
+	files = append(files, settings.VarFiles...)
+
+	for _, file := range files {
+		fileVariables, err := p.readVariablesFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range fileVariables {
+			variables[name] = value
+		}
+	}
+
+	settingsVariables, err := settings.Variables()
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range settingsVariables {
+		variables[name] = parseVariableValue(value)
+	}
+	return variables, nil
+}
+
+// parseVariableValue reads lists and maps given on the command line as HCL, like terraform, other values are strings
+func parseVariableValue(value string) cty.Value {
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "[") || strings.HasPrefix(trimmed, "{") {
+		expr, diags := hclsyntax.ParseExpression([]byte(trimmed), "<value>", hcl.InitialPos)
+		if !diags.HasErrors() {
+			if parsed, diags := expr.Value(nil); !diags.HasErrors() {
+				return parsed
+			}
+		}
+	}
+	return cty.StringVal(value)
+}
+
+func (p *offlineParser) readVariablesFile(file string) (map[string]cty.Value, error) {
+	var hclFile *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasSuffix(file, ".json") {
+		hclFile, diags = p.parser.ParseJSONFile(file)
+	} else {
+		hclFile, diags = p.parser.ParseHCLFile(file)
+	}
+	if diags.HasErrors() {
+		return nil, errors.Wrapf(diags, "Cannot parse variables file %v", file)
+	}
+	attributes, diags := hclFile.Body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, errors.Wrapf(diags, "Cannot read variables file %v", file)
+	}
+	variables := map[string]cty.Value{}
+	for name, attribute := range attributes {
+		value, diags := attribute.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, errors.Wrapf(diags, "Cannot read variable %v of %v", name, file)
+		}
+		variables[name] = value
+	}
+	return variables, nil
+}
+
+// loadModule parses the terraform files of a module and evaluates its resources and child modules
+func (p *offlineParser) loadModule(address string, dir string, inputs map[string]cty.Value, inputReasons map[string]string) (*offlineModule, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.Errorf("No terraform files found in %v", dir)
+	}
+	sort.Strings(files)
+	blocks := hclsyntax.Blocks{}
+	for _, file := range files {
+		hclFile, diags := p.parser.ParseHCLFile(file)
+		if diags.HasErrors() {
+			return nil, errors.Wrapf(diags, "Cannot parse terraform file %v", file)
+		}
+		blocks = append(blocks, hclFile.Body.(*hclsyntax.Body).Blocks...)
+	}
+	log.Debugf("Parsed %v terraform files of %v", len(files), dir)
+
+	m := &offlineModule{
+		address:             address,
+		dir:                 dir,
+		rootDir:             p.rootDir,
+		workspace:           p.workspace,
+		variables:           map[string]cty.Value{},
+		variableConfigs:     map[string]interface{}{},
+		locals:              map[string]cty.Value{},
+		unknownReasons:      map[string]string{},
+		managedTypes:        map[string]bool{},
+		modules:             map[string]cty.Value{},
+		moduleOutputReasons: map[string]string{},
+		outputs:             map[string]cty.Value{},
+		outputReasons:       map[string]string{},
+	}
+	for _, block := range blocks {
+		if block.Type == "resource" && len(block.Labels) == 2 {
+			m.managedTypes[block.Labels[0]] = true
+		}
+	}
+
+	for _, block := range blocks {
+		if block.Type == "terraform" {
+			p.readRequiredProviders(block)
+		}
+	}
+	for name, reason := range inputReasons {
+		m.unknownReasons["var."+name] = reason
+	}
+	for _, block := range blocks {
+		if block.Type == "variable" && len(block.Labels) == 1 {
+			p.readVariable(m, block, inputs)
+		}
+	}
+	if err := p.readLocalsAndModules(m, blocks); err != nil {
+		return nil, err
+	}
+	if address == "" {
+		for _, block := range blocks {
+			if block.Type == "provider" && len(block.Labels) == 1 {
+				p.readProvider(m, block)
+			}
+		}
+	}
+	for _, block := range blocks {
+		switch {
+		case block.Type == "resource" && len(block.Labels) == 2:
+			p.readResource(m, block, "managed")
+		case block.Type == "data" && len(block.Labels) == 2:
+			p.readResource(m, block, "data")
+		}
+	}
+	for _, block := range blocks {
+		if block.Type == "output" && len(block.Labels) == 1 {
+			p.readOutput(m, block)
+		}
+	}
+	return m, nil
+}
+
+func (p *offlineParser) readRequiredProviders(block *hclsyntax.Block) {
+	for _, terraformBlock := range block.Body.Blocks {
+		if terraformBlock.Type != "required_providers" {
+			continue
+		}
+		for name, attribute := range terraformBlock.Body.Attributes {
+			value, diags := attribute.Expr.Value(nil)
+			if diags.HasErrors() || !value.Type().IsObjectType() || !value.Type().HasAttribute("source") {
+				continue
+			}
+			source := value.GetAttr("source")
+			if source.Type() == cty.String && source.IsKnown() && !source.IsNull() {
+				p.requiredProviders[name] = source.AsString()
+			}
+		}
+	}
+}
+
+// providerFullName returns the full name of a provider, as in plans (registry.terraform.io/hashicorp/google)
+func (p *offlineParser) providerFullName(name string) string {
+	source, ok := p.requiredProviders[name]
+	if !ok {
+		source = "hashicorp/" + name
+	}
+	if strings.Count(source, "/") == 1 {
+		source = "registry.terraform.io/" + source
+	}
+	return source
+}
+
+func (p *offlineParser) readVariable(m *offlineModule, block *hclsyntax.Block, inputs map[string]cty.Value) {
+	name := block.Labels[0]
+	address := m.prefix() + "var." + name
+	variableConfig := map[string]interface{}{}
+	if sensitiveAttribute, ok := block.Body.Attributes["sensitive"]; ok {
+		sensitive, diags := sensitiveAttribute.Expr.Value(nil)
+		if !diags.HasErrors() && sensitive.Type() == cty.Bool && sensitive.IsKnown() && !sensitive.IsNull() && sensitive.True() {
+			variableConfig["sensitive"] = true
+		}
+	}
+	m.variableConfigs[name] = variableConfig
+	value, ok := inputs[name]
+	if !ok {
+		defaultAttribute, hasDefault := block.Body.Attributes["default"]
+		if !hasDefault {
+			p.report(address, "value", "variable has no value nor default")
+			m.variables[name] = cty.DynamicVal
+			return
+		}
+		var diags hcl.Diagnostics
+		value, diags = defaultAttribute.Expr.Value(nil)
+		if diags.HasErrors() {
+			p.report(address, "default", diagnosticsReason(diags))
+			m.variables[name] = cty.DynamicVal
+			return
+		}
+	}
+	if typeAttribute, ok := block.Body.Attributes["type"]; ok && !value.IsNull() {
+		if varType, diags := typeexpr.TypeConstraint(typeAttribute.Expr); !diags.HasErrors() {
+			converted, err := convert.Convert(value, varType)
+			if err != nil {
+				p.report(address, "value", fmt.Sprintf("invalid value for type %v: %v", typeexpr.TypeString(varType), err))
+				m.variables[name] = cty.DynamicVal
+				return
+			}
+			value = converted
+		}
+	}
+	m.variables[name] = value
+}
+
+// readLocalsAndModules evaluates locals and module calls, in the order of their dependencies
+func (p *offlineParser) readLocalsAndModules(m *offlineModule, blocks hclsyntax.Blocks) error {
+	pendingLocals := map[string]*hclsyntax.Attribute{}
+	pendingModules := map[string]*hclsyntax.Block{}
+	for _, block := range blocks {
+		switch {
+		case block.Type == "locals":
+			for name, attribute := range block.Body.Attributes {
+				pendingLocals[name] = attribute
+			}
+		case block.Type == "module" && len(block.Labels) == 1:
+			pendingModules[block.Labels[0]] = block
+		}
+	}
+	for len(pendingLocals)+len(pendingModules) > 0 {
+		progress := false
+		for _, name := range sortedKeys(pendingLocals) {
+			if !isReady(pendingLocals[name].Expr.Variables(), pendingLocals, pendingModules) {
+				continue
+			}
+			m.locals[name] = p.evaluateLocal(m, name, pendingLocals[name])
+			delete(pendingLocals, name)
+			progress = true
+		}
+		for _, name := range sortedKeys(pendingModules) {
+			block := pendingModules[name]
+			variables := []hcl.Traversal{}
+			for _, attribute := range block.Body.Attributes {
+				variables = append(variables, attribute.Expr.Variables()...)
+			}
+			if !isReady(variables, pendingLocals, pendingModules) {
+				continue
+			}
+			if err := p.readModuleCall(m, block); err != nil {
+				return err
+			}
+			delete(pendingModules, name)
+			progress = true
+		}
+		if !progress {
+			// Cycle between locals and modules
+			for _, name := range sortedKeys(pendingLocals) {
+				p.report(m.prefix()+"local."+name, "value", "cyclic dependency")
+				m.locals[name] = cty.DynamicVal
+			}
+			for _, name := range sortedKeys(pendingModules) {
+				p.report(m.prefix()+"module."+name, "source", "cyclic dependency, resources of the module are not estimated")
+				m.modules[name] = cty.DynamicVal
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+// isReady returns true if the variables of an expression do not refer to pending locals or modules
+func isReady(variables []hcl.Traversal, pendingLocals map[string]*hclsyntax.Attribute, pendingModules map[string]*hclsyntax.Block) bool {
+	for _, traversal := range variables {
+		if len(traversal) < 2 {
+			continue
+		}
+		attr, ok := traversal[1].(hcl.TraverseAttr)
+		if !ok {
+			continue
+		}
+		if _, isPending := pendingLocals[attr.Name]; isPending && traversal.RootName() == "local" {
+			return false
+		}
+		if _, isPending := pendingModules[attr.Name]; isPending && traversal.RootName() == "module" {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *offlineParser) evaluateLocal(m *offlineModule, name string, attribute *hclsyntax.Attribute) cty.Value {
+	value, diags := attribute.Expr.Value(m.evalContext(nil))
+	if diags.HasErrors() {
+		p.report(m.prefix()+"local."+name, "value", diagnosticsReason(diags))
+		return cty.DynamicVal
+	}
+	if !value.IsWhollyKnown() {
+		m.unknownReasons["local."+name] = p.unknownReason(m, attribute.Expr, diags)
+	}
+	return value
+}
+
+func (p *offlineParser) readProvider(m *offlineModule, block *hclsyntax.Block) {
+	name := block.Labels[0]
+	key := name
+	if alias, ok := block.Body.Attributes["alias"]; ok {
+		if aliasValue, diags := alias.Expr.Value(nil); !diags.HasErrors() && aliasValue.Type() == cty.String {
+			key = name + "." + aliasValue.AsString()
+		}
+	}
+	_, expressions := p.readBody(m, "provider."+key, block.Body, m.evalContext(nil), "", nil)
+	p.providerConfig[key] = map[string]interface{}{
+		"name":        name,
+		"full_name":   p.providerFullName(name),
+		"expressions": expressions,
+	}
+	if key != name {
+		return
+	}
+	defaults := map[string]cty.Value{}
+	for _, attributeName := range []string{"region", "zone"} {
+		if attribute, ok := block.Body.Attributes[attributeName]; ok {
+			if value, diags := attribute.Expr.Value(m.evalContext(nil)); !diags.HasErrors() && value.IsWhollyKnown() {
+				defaults[attributeName] = value
+			}
+		}
+	}
+	p.providerDefaults[name] = defaults
+}
+
+func (p *offlineParser) readResource(m *offlineModule, block *hclsyntax.Block, mode string) {
+	resourceType, name := block.Labels[0], block.Labels[1]
+	address := m.prefix() + resourceType + "." + name
+	if mode == "data" {
+		address = m.prefix() + "data." + resourceType + "." + name
+	}
+
+	providerKey := strings.Split(resourceType, "_")[0]
+	if providerAttribute, ok := block.Body.Attributes["provider"]; ok {
+		if traversal, diags := hcl.AbsTraversalForExpr(providerAttribute.Expr); !diags.HasErrors() {
+			providerKey = traversal.RootName()
+		}
+	}
+	providerName := p.providerFullName(providerKey)
+	// Modules are not in the configuration, the location of the block is kept for the sources of reports (SARIF)
+	sourceLocation := map[string]interface{}{"file": block.DefRange().Filename, "line": float64(block.DefRange().Start.Line)}
+	if file, err := filepath.Abs(block.DefRange().Filename); err == nil {
+		sourceLocation["file"] = file
+	}
+
+	for _, instance := range p.expandInstances(m, address, block.Body) {
+		instanceAddress := address + indexSuffix(instance.key)
+		values, expressions := p.readBody(m, instanceAddress, block.Body, m.evalContext(instance.vars), "", resourceMetaArguments)
+		if mode == "managed" {
+			p.defaultedValues = append(p.defaultedValues, defaultedValues{resourceType: resourceType, providerKey: providerKey, values: values})
+		}
+		resource := map[string]interface{}{
+			"address":          instanceAddress,
+			"mode":             mode,
+			"type":             resourceType,
+			"name":             name,
+			"provider_name":    providerName,
+			"schema_version":   0,
+			"values":           values,
+			"sensitive_values": map[string]interface{}{},
+		}
+		if index, ok := instance.key.(int64); ok {
+			resource["index"] = float64(index)
+		} else if instance.key != nil {
+			resource["index"] = instance.key
+		}
+		if mode == "data" {
+			m.dataSources = append(m.dataSources, resource)
+		} else {
+			m.resources = append(m.resources, resource)
+		}
+		p.configuration = append(p.configuration, map[string]interface{}{
+			"address":             instanceAddress,
+			"mode":                mode,
+			"type":                resourceType,
+			"name":                name,
+			"provider_config_key": providerKey,
+			"expressions":         expressions,
+			"schema_version":      0,
+			"source_location":     sourceLocation,
+		})
+	}
+}
+
+// setProviderDefaults sets the zone and region of the provider on google resources that do not set them,
+// as the google provider does when planning
+func (p *offlineParser) setProviderDefaults() {
+	for _, resource := range p.defaultedValues {
+		if !strings.HasPrefix(resource.providerKey, "google") {
+			continue
+		}
+		defaults := p.providerDefaults[resource.providerKey]
+		_, hasZone := resource.values["zone"]
+		_, hasRegion := resource.values["region"]
+		_, hasLocation := resource.values["location"]
+		if hasZone || hasRegion || hasLocation {
+			continue
+		}
+		if zone, ok := defaults["zone"]; ok && !strings.Contains(resource.resourceType, "_region_") {
+			resource.values["zone"] = ctyToJSON(zone)
+		}
+		if region, ok := defaults["region"]; ok {
+			resource.values["region"] = ctyToJSON(region)
+		}
+	}
+}
+
+// readModuleCall loads the instances of a local module, its outputs can then be used by the calling module
+func (p *offlineParser) readModuleCall(m *offlineModule, block *hclsyntax.Block) error {
+	name := block.Labels[0]
+	address := m.prefix() + "module." + name
+	m.modules[name] = cty.DynamicVal
+	sourceAttribute, ok := block.Body.Attributes["source"]
+	if !ok {
+		p.report(address, "source", "module has no source")
+		return nil
+	}
+	source, diags := sourceAttribute.Expr.Value(nil)
+	if diags.HasErrors() || source.Type() != cty.String {
+		p.report(address, "source", "module source is not a static string")
+		return nil
+	}
+	if !strings.HasPrefix(source.AsString(), "./") && !strings.HasPrefix(source.AsString(), "../") {
+		p.report(address, "source", fmt.Sprintf("only local modules can be read offline, resources of %v are not estimated", source.AsString()))
+		return nil
+	}
+	dir := filepath.Join(m.dir, source.AsString())
+
+	_, hasCount := block.Body.Attributes["count"]
+	instancesOutputs := []cty.Value{}
+	outputsByKey := map[string]cty.Value{}
+	for _, instance := range p.expandInstances(m, address, block.Body) {
+		instanceAddress := address + indexSuffix(instance.key)
+		ctx := m.evalContext(instance.vars)
+		inputs := map[string]cty.Value{}
+		inputReasons := map[string]string{}
+		for _, inputName := range sortedKeys(block.Body.Attributes) {
+			if moduleMetaArguments[inputName] {
+				continue
+			}
+			expr := block.Body.Attributes[inputName].Expr
+			value, diags := expr.Value(ctx)
+			if diags.HasErrors() {
+				p.report(instanceAddress, inputName, diagnosticsReason(diags))
+				value = cty.DynamicVal
+			}
+			if !value.IsWhollyKnown() {
+				inputReasons[inputName] = p.unknownReason(m, expr, diags)
+			}
+			inputs[inputName] = value
+		}
+		child, err := p.loadModule(instanceAddress, dir, inputs, inputReasons)
+		if err != nil {
+			return errors.Wrapf(err, "Cannot read module %v", instanceAddress)
+		}
+		m.children = append(m.children, child)
+
+		outputs := objectOrEmpty(child.outputs)
+		instancesOutputs = append(instancesOutputs, outputs)
+		if key, ok := instance.key.(string); ok {
+			outputsByKey[key] = outputs
+		}
+		for output, reason := range child.outputReasons {
+			m.moduleOutputReasons[name+"."+output] = reason
+		}
+	}
+
+	switch {
+	case hasCount:
+		if len(instancesOutputs) == 0 {
+			m.modules[name] = cty.EmptyTupleVal
+		} else {
+			m.modules[name] = cty.TupleVal(instancesOutputs)
+		}
+	case len(outputsByKey) > 0:
+		m.modules[name] = cty.ObjectVal(outputsByKey)
+	case len(instancesOutputs) == 1:
+		m.modules[name] = instancesOutputs[0]
+	}
+	return nil
+}
+
+// readOutput evaluates an output of a module, keeping why it is unknown if it cannot be evaluated offline
+func (p *offlineParser) readOutput(m *offlineModule, block *hclsyntax.Block) {
+	name := block.Labels[0]
+	valueAttribute, ok := block.Body.Attributes["value"]
+	if !ok {
+		return
+	}
+	value, diags := valueAttribute.Expr.Value(m.evalContext(nil))
+	if diags.HasErrors() {
+		value = cty.DynamicVal
+	}
+	if !value.IsWhollyKnown() {
+		m.outputReasons[name] = p.unknownReason(m, valueAttribute.Expr, diags)
+	}
+	m.outputs[name] = value
+}
+
+// expandInstances returns the instances of a resource or a module, from its count or for_each.
+// If they cannot be evaluated, a single instance is assumed.
+func (p *offlineParser) expandInstances(m *offlineModule, address string, body *hclsyntax.Body) []offlineInstance {
+	if countAttribute, ok := body.Attributes["count"]; ok {
+		count, diags := countAttribute.Expr.Value(m.evalContext(nil))
+		if !diags.HasErrors() && count.IsWhollyKnown() && !count.IsNull() {
+			count, err := convert.Convert(count, cty.Number)
+			if err == nil {
+				n, _ := count.AsBigFloat().Int64()
+				instances := []offlineInstance{}
+				for i := int64(0); i < n; i++ {
+					instances = append(instances, offlineInstance{
+						key:  i,
+						vars: map[string]cty.Value{"count": cty.ObjectVal(map[string]cty.Value{"index": cty.NumberIntVal(i)})},
+					})
+				}
+				return instances
+			}
+		}
+		p.report(address, "count", p.unknownReason(m, countAttribute.Expr, diags)+", assuming a single instance")
+		return []offlineInstance{{
+			vars: map[string]cty.Value{"count": cty.ObjectVal(map[string]cty.Value{"index": cty.NumberIntVal(0)})},
+		}}
+	}
+
+	if forEachAttribute, ok := body.Attributes["for_each"]; ok {
+		forEach, diags := forEachAttribute.Expr.Value(m.evalContext(nil))
+		if !diags.HasErrors() && forEach.IsWhollyKnown() && !forEach.IsNull() {
+			forEachType := forEach.Type()
+			if forEachType.IsMapType() || forEachType.IsObjectType() || (forEachType.IsSetType() && forEachType.ElementType() == cty.String) {
+				instances := []offlineInstance{}
+				for it := forEach.ElementIterator(); it.Next(); {
+					key, value := it.Element()
+					if forEachType.IsSetType() {
+						key = value
+					}
+					instances = append(instances, offlineInstance{
+						key:  key.AsString(),
+						vars: map[string]cty.Value{"each": cty.ObjectVal(map[string]cty.Value{"key": key, "value": value})},
+					})
+				}
+				return instances
+			}
+			p.report(address, "for_each", "for_each must be a map or a set of strings, assuming a single instance")
+		} else {
+			p.report(address, "for_each", p.unknownReason(m, forEachAttribute.Expr, diags)+", assuming a single instance")
+		}
+		return []offlineInstance{{
+			vars: map[string]cty.Value{"each": cty.ObjectVal(map[string]cty.Value{"key": cty.DynamicVal, "value": cty.DynamicVal})},
+		}}
+	}
+
+	return []offlineInstance{{}}
+}
+
+// readBody evaluates the attributes and nested blocks of a body, returning its values and its configuration expressions
+func (p *offlineParser) readBody(m *offlineModule, address string, body *hclsyntax.Body, ctx *hcl.EvalContext, prefix string, metaArguments map[string]bool) (map[string]interface{}, map[string]interface{}) {
+	values := map[string]interface{}{}
+	expressions := map[string]interface{}{}
+	for _, name := range sortedKeys(body.Attributes) {
+		if metaArguments[name] {
+			continue
+		}
+		expr := body.Attributes[name].Expr
+		expression := map[string]interface{}{}
+		if references := m.references(expr); len(references) > 0 {
+			expression["references"] = references
+		}
+		value, diags := expr.Value(ctx)
+		if !diags.HasErrors() && value.IsWhollyKnown() {
+			values[name] = ctyToJSON(value)
+			expression["constant_value"] = values[name]
+		} else if reason := p.unknownReason(m, expr, diags); reason != "" {
+			p.report(address, prefix+name, reason)
+		} else {
+			log.Debugf("%v.%v%v is known after apply", address, prefix, name)
+		}
+		expressions[name] = expression
+	}
+
+	for _, block := range body.Blocks {
+		if metaArguments != nil && resourceMetaBlocks[block.Type] {
+			continue
+		}
+		if block.Type == "dynamic" && len(block.Labels) == 1 {
+			p.readDynamicBlock(m, address, block, ctx, prefix, values, expressions)
+			continue
+		}
+		blockValues, blockExpressions := p.readBody(m, address, block.Body, ctx, prefix+block.Type+".", nil)
+		appendBlock(values, expressions, block.Type, blockValues, blockExpressions)
+	}
+	return values, expressions
+}
+
+// readDynamicBlock generates the blocks of a dynamic block, if its for_each is known
+func (p *offlineParser) readDynamicBlock(m *offlineModule, address string, block *hclsyntax.Block, ctx *hcl.EvalContext, prefix string, values map[string]interface{}, expressions map[string]interface{}) {
+	blockType := block.Labels[0]
+	forEachAttribute, ok := block.Body.Attributes["for_each"]
+	if !ok {
+		p.report(address, prefix+blockType, "dynamic block has no for_each")
+		return
+	}
+	forEach, diags := forEachAttribute.Expr.Value(ctx)
+	if diags.HasErrors() || !forEach.IsWhollyKnown() || forEach.IsNull() || !forEach.CanIterateElements() {
+		reason := p.unknownReason(m, forEachAttribute.Expr, diags)
+		if reason == "" {
+			reason = "for_each of dynamic block is known after apply"
+		}
+		p.report(address, prefix+blockType, reason)
+		return
+	}
+	iterator := blockType
+	if iteratorAttribute, ok := block.Body.Attributes["iterator"]; ok {
+		if traversal, diags := hcl.AbsTraversalForExpr(iteratorAttribute.Expr); !diags.HasErrors() {
+			iterator = traversal.RootName()
+		}
+	}
+	for _, contentBlock := range block.Body.Blocks {
+		if contentBlock.Type != "content" {
+			continue
+		}
+		for it := forEach.ElementIterator(); it.Next(); {
+			key, value := it.Element()
+			childCtx := ctx.NewChild()
+			childCtx.Variables = map[string]cty.Value{
+				iterator: cty.ObjectVal(map[string]cty.Value{"key": key, "value": value}),
+			}
+			blockValues, blockExpressions := p.readBody(m, address, contentBlock.Body, childCtx, prefix+blockType+".", nil)
+			appendBlock(values, expressions, blockType, blockValues, blockExpressions)
+		}
+	}
+}
+
+// appendBlock adds a nested block to values and expressions, blocks are lists as in plans
+func appendBlock(values map[string]interface{}, expressions map[string]interface{}, blockType string, blockValues map[string]interface{}, blockExpressions map[string]interface{}) {
+	blockValuesList, _ := values[blockType].([]interface{})
+	values[blockType] = append(blockValuesList, blockValues)
+	blockExpressionsList, _ := expressions[blockType].([]interface{})
+	expressions[blockType] = append(blockExpressionsList, blockExpressions)
+}
+
+// unknownReason explains why an expression cannot be evaluated offline.
+// It returns an empty string if it only depends on resources of the module, known after apply like in a plan.
+func (p *offlineParser) unknownReason(m *offlineModule, expr hclsyntax.Expression, diags hcl.Diagnostics) string {
+	if diags.HasErrors() {
+		return diagnosticsReason(diags)
+	}
+	dependencies := []string{}
+	for _, traversal := range expr.Variables() {
+		root := traversal.RootName()
+		if m.managedTypes[root] || root == "count" || root == "each" || root == "self" {
+			continue
+		}
+		length := 2
+		switch root {
+		case "var", "local":
+			if reason, ok := m.unknownReasons[traversalString(traversal, 2)]; ok && reason == "" {
+				continue
+			}
+		case "data":
+			length = 3
+		case "module":
+			// Outputs of local modules are unknown only if they depend on something else than resources
+			if moduleOutput := moduleOutputName(traversal); moduleOutput != "" {
+				if reason, ok := m.moduleOutputReasons[moduleOutput]; ok && reason == "" {
+					continue
+				}
+				if _, isOutput := m.moduleOutputReasons[moduleOutput]; !isOutput && m.modules[strings.Split(moduleOutput, ".")[0]].IsWhollyKnown() {
+					continue
+				}
+			}
+			length = 3
+		}
+		dependencies = appendUnique(dependencies, traversalString(traversal, length))
+	}
+	if len(dependencies) == 0 {
+		return ""
+	}
+	return "depends on " + strings.Join(dependencies, ", ") + ", not known offline"
+}
+
+// references returns the references of an expression, as in the configuration of plans
+// (ex: google_compute_instance_template.tpl.id, google_compute_instance_template.tpl)
+func (m *offlineModule) references(expr hclsyntax.Expression) []interface{} {
+	references := []string{}
+	for _, traversal := range expr.Variables() {
+		root := traversal.RootName()
+		resourceLength := 0
+		switch {
+		case root == "data":
+			resourceLength = 3
+		case root == "module" || m.managedTypes[root]:
+			resourceLength = 2
+		}
+		if resourceLength == 0 {
+			references = appendUnique(references, traversalString(traversal, len(traversal)))
+			continue
+		}
+		// Resources are referenced with their full address, as all resources are in the root module configuration
+		references = appendUnique(references, m.prefix()+traversalString(traversal, len(traversal)))
+		if len(traversal) > resourceLength {
+			if _, isIndex := traversal[resourceLength].(hcl.TraverseIndex); isIndex {
+				references = appendUnique(references, m.prefix()+traversalString(traversal, resourceLength+1))
+			}
+		}
+		references = appendUnique(references, m.prefix()+traversalString(traversal, resourceLength))
+	}
+	result := make([]interface{}, len(references))
+	for i, reference := range references {
+		result[i] = reference
+	}
+	return result
+}
+
+func (m *offlineModule) prefix() string {
+	if m.address == "" {
+		return ""
+	}
+	return m.address + "."
+}
+
+func (m *offlineModule) evalContext(vars map[string]cty.Value) *hcl.EvalContext {
+	variables := map[string]cty.Value{
+		"var":   objectOrEmpty(m.variables),
+		"local": objectOrEmpty(m.locals),
+		"path": cty.ObjectVal(map[string]cty.Value{
+			"module": cty.StringVal(m.dir),
+			"root":   cty.StringVal(m.rootDir),
+			"cwd":    cty.StringVal(m.rootDir),
+		}),
+		"terraform": cty.ObjectVal(map[string]cty.Value{"workspace": cty.StringVal(m.workspace)}),
+		// Known after apply
+		"data":   cty.DynamicVal,
+		"module": objectOrEmpty(m.modules),
+		"self":   cty.DynamicVal,
+	}
+	for resourceType := range m.managedTypes {
+		variables[resourceType] = cty.DynamicVal
+	}
+	for name, value := range vars {
+		variables[name] = value
+	}
+	return &hcl.EvalContext{
+		Variables: variables,
+		Functions: offlineFunctions(m.dir),
+	}
+}
+
+func (m *offlineModule) toJSON(dataSources bool) map[string]interface{} {
+	resources := m.resources
+	if dataSources {
+		resources = m.dataSources
+	}
+	if resources == nil {
+		resources = []interface{}{}
+	}
+	module := map[string]interface{}{
+		"resources": resources,
+	}
+	if m.address != "" {
+		module["address"] = m.address
+	}
+	if len(m.children) > 0 {
+		children := []interface{}{}
+		for _, child := range m.children {
+			children = append(children, child.toJSON(dataSources))
+		}
+		module["child_modules"] = children
+	}
+	return module
+}
+
+func (p *offlineParser) report(address string, attribute string, reason string) {
+	p.unresolved = append(p.unresolved, UnresolvedAttribute{Address: address, Attribute: attribute, Reason: reason})
+}
+
+// offlineFunctions are the terraform functions available offline
+func offlineFunctions(dir string) map[string]function.Function {
+	return map[string]function.Function{
+		"abs":             stdlib.AbsoluteFunc,
+		"can":             tryfunc.CanFunc,
+		"ceil":            stdlib.CeilFunc,
+		"chomp":           stdlib.ChompFunc,
+		"chunklist":       stdlib.ChunklistFunc,
+		"coalesce":        stdlib.CoalesceFunc,
+		"coalescelist":    stdlib.CoalesceListFunc,
+		"compact":         stdlib.CompactFunc,
+		"concat":          stdlib.ConcatFunc,
+		"contains":        stdlib.ContainsFunc,
+		"csvdecode":       stdlib.CSVDecodeFunc,
+		"distinct":        stdlib.DistinctFunc,
+		"element":         stdlib.ElementFunc,
+		"file":            fileFunc(dir),
+		"flatten":         stdlib.FlattenFunc,
+		"floor":           stdlib.FloorFunc,
+		"format":          stdlib.FormatFunc,
+		"formatdate":      stdlib.FormatDateFunc,
+		"formatlist":      stdlib.FormatListFunc,
+		"indent":          stdlib.IndentFunc,
+		"index":           stdlib.IndexFunc,
+		"join":            stdlib.JoinFunc,
+		"jsondecode":      stdlib.JSONDecodeFunc,
+		"jsonencode":      stdlib.JSONEncodeFunc,
+		"keys":            stdlib.KeysFunc,
+		"length":          stdlib.LengthFunc,
+		"log":             stdlib.LogFunc,
+		"lookup":          stdlib.LookupFunc,
+		"lower":           stdlib.LowerFunc,
+		"max":             stdlib.MaxFunc,
+		"merge":           stdlib.MergeFunc,
+		"min":             stdlib.MinFunc,
+		"parseint":        stdlib.ParseIntFunc,
+		"pow":             stdlib.PowFunc,
+		"range":           stdlib.RangeFunc,
+		"regex":           stdlib.RegexFunc,
+		"regexall":        stdlib.RegexAllFunc,
+		"replace":         stdlib.ReplaceFunc,
+		"reverse":         stdlib.ReverseListFunc,
+		"setintersection": stdlib.SetIntersectionFunc,
+		"setproduct":      stdlib.SetProductFunc,
+		"setsubtract":     stdlib.SetSubtractFunc,
+		"setunion":        stdlib.SetUnionFunc,
+		"signum":          stdlib.SignumFunc,
+		"slice":           stdlib.SliceFunc,
+		"sort":            stdlib.SortFunc,
+		"split":           stdlib.SplitFunc,
+		"strrev":          stdlib.ReverseFunc,
+		"substr":          stdlib.SubstrFunc,
+		"timeadd":         stdlib.TimeAddFunc,
+		"title":           stdlib.TitleFunc,
+		"tobool":          stdlib.MakeToFunc(cty.Bool),
+		"tolist":          stdlib.MakeToFunc(cty.List(cty.DynamicPseudoType)),
+		"tomap":           stdlib.MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+		"tonumber":        stdlib.MakeToFunc(cty.Number),
+		"toset":           stdlib.MakeToFunc(cty.Set(cty.DynamicPseudoType)),
+		"tostring":        stdlib.MakeToFunc(cty.String),
+		"trim":            stdlib.TrimFunc,
+		"trimprefix":      stdlib.TrimPrefixFunc,
+		"trimspace":       stdlib.TrimSpaceFunc,
+		"trimsuffix":      stdlib.TrimSuffixFunc,
+		"try":             tryfunc.TryFunc,
+		"upper":           stdlib.UpperFunc,
+		"values":          stdlib.ValuesFunc,
+		"zipmap":          stdlib.ZipmapFunc,
+	}
+}
+
+// fileFunc reads a file relative to the module directory
+func fileFunc(dir string) function.Function {
+	return function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			return cty.StringVal(string(content)), nil
+		},
+	})
+}
+
+// ctyToJSON converts a known value to its JSON representation, as unmarshalled from a plan JSON
+func ctyToJSON(value cty.Value) interface{} {
+	if value.IsNull() {
+		return nil
+	}
+	valueType := value.Type()
+	switch {
+	case valueType == cty.String:
+		return value.AsString()
+	case valueType == cty.Number:
+		number, _ := value.AsBigFloat().Float64()
+		return number
+	case valueType == cty.Bool:
+		return value.True()
+	case valueType.IsListType() || valueType.IsSetType() || valueType.IsTupleType():
+		list := []interface{}{}
+		for it := value.ElementIterator(); it.Next(); {
+			_, element := it.Element()
+			list = append(list, ctyToJSON(element))
+		}
+		return list
+	case valueType.IsMapType() || valueType.IsObjectType():
+		object := map[string]interface{}{}
+		for it := value.ElementIterator(); it.Next(); {
+			key, element := it.Element()
+			object[key.AsString()] = ctyToJSON(element)
+		}
+		return object
+	}
+	return nil
+}
+
+// traversalString formats the first parts of a traversal (ex: google_compute_disk.disk[0].id)
+func traversalString(traversal hcl.Traversal, length int) string {
+	builder := strings.Builder{}
+	for i, step := range traversal {
+		if i >= length {
+			break
+		}
+		switch typedStep := step.(type) {
+		case hcl.TraverseRoot:
+			builder.WriteString(typedStep.Name)
+		case hcl.TraverseAttr:
+			builder.WriteString("." + typedStep.Name)
+		case hcl.TraverseIndex:
+			key := typedStep.Key
+			if key.Type() == cty.String {
+				builder.WriteString(fmt.Sprintf("[%q]", key.AsString()))
+			} else if key.Type() == cty.Number {
+				builder.WriteString(fmt.Sprintf("[%v]", key.AsBigFloat().String()))
+			}
+		}
+	}
+	return builder.String()
+}
+
+// moduleOutputName returns the module and output referenced by a traversal (ex: network.vpc_id for module.network[0].vpc_id)
+func moduleOutputName(traversal hcl.Traversal) string {
+	names := []string{}
+	for _, step := range traversal[1:] {
+		if attr, ok := step.(hcl.TraverseAttr); ok {
+			names = append(names, attr.Name)
+		}
+		if len(names) == 2 {
+			return strings.Join(names, ".")
+		}
+	}
+	return ""
+}
+
+func indexSuffix(key interface{}) string {
+	switch typedKey := key.(type) {
+	case string:
+		return fmt.Sprintf("[%q]", typedKey)
+	case int64:
+		return fmt.Sprintf("[%v]", typedKey)
+	}
+	return ""
+}
+
+func diagnosticsReason(diags hcl.Diagnostics) string {
+	for _, diag := range diags {
+		if diag.Severity == hcl.DiagError {
+			if diag.Detail != "" {
+				return diag.Summary + ": " + diag.Detail
+			}
+			return diag.Summary
+		}
+	}
+	return diags.Error()
+}
+
+func objectOrEmpty(values map[string]cty.Value) cty.Value {
+	if len(values) == 0 {
+		return cty.EmptyObjectVal
+	}
+	return cty.ObjectVal(values)
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
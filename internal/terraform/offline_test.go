@@ -0,0 +1,175 @@
+package terraform
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func offlineTestDir() string {
+	_, filename, _, _ := runtime.Caller(0)
+	return path.Join(path.Dir(filename), "../../test/terraform/offline")
+}
+
+func TestOfflinePlan(t *testing.T) {
+	tfPlan, unresolved, err := OfflinePlan(offlineTestDir(), PlanSettings{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []UnresolvedAttribute{
+		{Address: "module.remote", Attribute: "source", Reason: "only local modules can be read offline, resources of terraform-google-modules/vm/google are not estimated"},
+		{Address: "google_compute_instance.web[0]", Attribute: "boot_disk.initialize_params.image", Reason: "depends on data.google_compute_image.debian, not known offline"},
+		{Address: "google_compute_instance.web[1]", Attribute: "boot_disk.initialize_params.image", Reason: "depends on data.google_compute_image.debian, not known offline"},
+	}, unresolved)
+
+	variables := (*tfPlan)["variables"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"value": float64(2)}, variables["instance_count"])
+
+	plannedRoot := (*tfPlan)["planned_values"].(map[string]interface{})["root_module"].(map[string]interface{})
+	assert.Len(t, plannedRoot["resources"], 5)
+	storageModule := plannedRoot["child_modules"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "module.storage", storageModule["address"])
+	disk := storageModule["resources"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "module.storage.google_compute_disk.data", disk["address"])
+	assert.Equal(t, "registry.terraform.io/hashicorp/google", disk["provider_name"])
+	assert.Equal(t, map[string]interface{}{"name": "cbf-default-data", "type": "pd-ssd", "size": float64(100), "zone": "us-central1-a", "region": "us-central1"}, disk["values"])
+
+	priorRoot := (*tfPlan)["prior_state"].(map[string]interface{})["values"].(map[string]interface{})["root_module"].(map[string]interface{})
+	image := priorRoot["resources"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "data.google_compute_image.debian", image["address"])
+
+	configuration := (*tfPlan)["configuration"].(map[string]interface{})
+	providerConfig := configuration["provider_config"].(map[string]interface{})["google"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"references": []interface{}{"var.region"}, "constant_value": "us-central1"}, providerConfig["expressions"].(map[string]interface{})["region"])
+	for _, resourceI := range configuration["root_module"].(map[string]interface{})["resources"].([]interface{}) {
+		resource := resourceI.(map[string]interface{})
+		if resource["address"] != "google_compute_instance.web[0]" {
+			continue
+		}
+		attachedDisk := resource["expressions"].(map[string]interface{})["attached_disk"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, map[string]interface{}{"references": []interface{}{"module.storage.id", "module.storage"}}, attachedDisk["source"])
+	}
+}
+
+func TestOfflinePlan_UnknownCount(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+data "aws_availability_zones" "available" {}
+
+variable "size" {}
+
+resource "aws_instance" "web" {
+  count         = length(data.aws_availability_zones.available.names)
+  instance_type = "t2.micro"
+  ami           = lookup({}, "ami", "unknown")
+}
+
+resource "aws_ebs_volume" "volume" {
+  size = var.size
+}
+`), 0644)
+	assert.NoError(t, err)
+
+	tfPlan, unresolved, err := OfflinePlan(dir, PlanSettings{})
+	assert.NoError(t, err)
+	assert.Equal(t, []UnresolvedAttribute{
+		{Address: "var.size", Attribute: "value", Reason: "variable has no value nor default"},
+		{Address: "aws_instance.web", Attribute: "count", Reason: "depends on data.aws_availability_zones.available, not known offline, assuming a single instance"},
+		{Address: "aws_ebs_volume.volume", Attribute: "size", Reason: "depends on var.size, not known offline"},
+	}, unresolved)
+
+	resources := (*tfPlan)["planned_values"].(map[string]interface{})["root_module"].(map[string]interface{})["resources"].([]interface{})
+	assert.Len(t, resources, 2)
+	web := resources[0].(map[string]interface{})
+	assert.Equal(t, "aws_instance.web", web["address"])
+	assert.Equal(t, map[string]interface{}{"instance_type": "t2.micro", "ami": "unknown"}, web["values"])
+}
+
+func TestOfflinePlan_NoTerraformFiles(t *testing.T) {
+	_, _, err := OfflinePlan(t.TempDir(), PlanSettings{})
+	assert.Error(t, err)
+}
+
+func TestOfflinePlan_Settings(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+variable "machine_type" {
+  default = "e2-standard-2"
+}
+
+variable "zones" {
+  type = list(string)
+}
+
+resource "google_compute_instance" "web" {
+  count        = length(var.zones)
+  name         = "web-${terraform.workspace}"
+  machine_type = var.machine_type
+  zone         = var.zones[count.index]
+}
+`), 0644)
+	assert.NoError(t, err)
+	varFile := filepath.Join(t.TempDir(), "prod.tfvars")
+	err = os.WriteFile(varFile, []byte(`machine_type = "c2-standard-4"`), 0644)
+	assert.NoError(t, err)
+
+	tfPlan, unresolved, err := OfflinePlan(dir, PlanSettings{
+		VarFiles:  []string{varFile},
+		Vars:      []string{`zones=["europe-west9-a", "europe-west9-b"]`},
+		Workspace: "prod",
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, unresolved)
+
+	resources := (*tfPlan)["planned_values"].(map[string]interface{})["root_module"].(map[string]interface{})["resources"].([]interface{})
+	assert.Len(t, resources, 2)
+	web := resources[1].(map[string]interface{})
+	assert.Equal(t, "google_compute_instance.web[1]", web["address"])
+	assert.Equal(t, map[string]interface{}{"name": "web-prod", "machine_type": "c2-standard-4", "zone": "europe-west9-b"}, web["values"])
+}
+
+func TestOfflinePlan_SensitiveVariables(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+variable "db_password" {
+  sensitive = true
+}
+
+variable "region" {
+  default = "europe-west9"
+}
+`), 0644)
+	assert.NoError(t, err)
+
+	tfPlan, _, err := OfflinePlan(dir, PlanSettings{Vars: []string{"db_password=hunter2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"db_password": "(sensitive)", "region": "europe-west9"}, GetPlanVariables(tfPlan))
+}
+
+func TestCarboniferPlan_OfflineUnresolved(t *testing.T) {
+	offline, workdir := viper.Get("offline"), viper.Get("workdir")
+	defer viper.Set("offline", offline)
+	defer viper.Set("workdir", workdir)
+	viper.Set("offline", true)
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+variable "size" {}
+
+resource "aws_ebs_volume" "volume" {
+  size = var.size
+}
+`), 0644)
+	assert.NoError(t, err)
+
+	_, err = CarboniferPlan(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []UnresolvedAttribute{
+		{Address: "var.size", Attribute: "value", Reason: "variable has no value nor default"},
+		{Address: "aws_ebs_volume.volume", Attribute: "size", Reason: "depends on var.size, not known offline"},
+	}, GetUsedPlanUnresolved())
+}
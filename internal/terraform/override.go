@@ -0,0 +1,134 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// overrideFileName is the terraform override file written to plan without credentials
+const overrideFileName = "carbonifer_override.tf"
+
+// fakeGoogleCredentials makes the google provider use a static token instead of looking for credentials
+var fakeGoogleCredentials = map[string]cty.Value{
+	"credentials":  cty.NullVal(cty.String),
+	"access_token": cty.StringVal("carbonifer"),
+}
+
+// credentialsOverrides are the provider arguments skipping credentials validation, metadata API checks and remote lookups
+var credentialsOverrides = map[string]map[string]cty.Value{
+	"aws": {
+		"profile":                     cty.NullVal(cty.String),
+		"access_key":                  cty.StringVal("carbonifer"),
+		"secret_key":                  cty.StringVal("carbonifer"),
+		"skip_credentials_validation": cty.True,
+		"skip_metadata_api_check":     cty.True,
+		"skip_region_validation":      cty.True,
+		"skip_requesting_account_id":  cty.True,
+	},
+	"google":      fakeGoogleCredentials,
+	"google-beta": fakeGoogleCredentials,
+}
+
+// writeCredentialsOverride writes an override file in dir, so that providers don't need credentials
+// and the state is local. It returns a function removing the file.
+func writeCredentialsOverride(dir string) (func(), error) {
+	overridePath := filepath.Join(dir, overrideFileName)
+	if _, err := os.Stat(overridePath); err == nil {
+		return nil, errors.Errorf("Cannot write %v, the file already exists", overridePath)
+	}
+	content, err := credentialsOverride(dir)
+	if err != nil {
+		return nil, err
+	}
+	log.Debugf("Writing terraform override file %v", overridePath)
+	err = os.WriteFile(overridePath, content, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		log.Debugf("Removing terraform override file %v", overridePath)
+		if err := os.Remove(overridePath); err != nil {
+			log.Errorf("Cannot remove terraform override file %v: %v", overridePath, err)
+		}
+	}, nil
+}
+
+// credentialsOverride returns the content of the override file of the terraform files of dir
+func credentialsOverride(dir string) ([]byte, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	parser := hclparse.NewParser()
+	file := hclwrite.NewEmptyFile()
+	body := file.Body()
+	overridden := map[string]bool{}
+	hasBackend := false
+	for _, fileName := range files {
+		// Override files can only override blocks of the primary files
+		if strings.HasSuffix(fileName, "_override.tf") || filepath.Base(fileName) == "override.tf" {
+			continue
+		}
+		hclFile, diags := parser.ParseHCLFile(fileName)
+		if diags.HasErrors() {
+			return nil, errors.Wrapf(diags, "Cannot parse terraform file %v", fileName)
+		}
+		for _, block := range hclFile.Body.(*hclsyntax.Body).Blocks {
+			switch block.Type {
+			case "terraform":
+				for _, child := range block.Body.Blocks {
+					hasBackend = hasBackend || child.Type == "backend" || child.Type == "cloud"
+				}
+			case "provider":
+				name := block.Labels[0]
+				arguments, ok := credentialsOverrides[name]
+				if !ok {
+					log.Debugf("Provider %v is not known to need credentials, it is not overridden", name)
+					continue
+				}
+				alias := providerAlias(block)
+				key := name + "." + alias
+				if overridden[key] {
+					continue
+				}
+				overridden[key] = true
+				providerBody := body.AppendNewBlock("provider", []string{name}).Body()
+				if alias != "" {
+					providerBody.SetAttributeValue("alias", cty.StringVal(alias))
+				}
+				for _, argument := range sortedKeys(arguments) {
+					providerBody.SetAttributeValue(argument, arguments[argument])
+				}
+			}
+		}
+	}
+	if hasBackend {
+		terraformBody := body.AppendNewBlock("terraform", nil).Body()
+		terraformBody.AppendNewBlock("backend", []string{"local"})
+	}
+	return file.Bytes(), nil
+}
+
+func providerAlias(block *hclsyntax.Block) string {
+	attribute, ok := block.Body.Attributes["alias"]
+	if !ok {
+		return ""
+	}
+	value, diags := attribute.Expr.Value(&hcl.EvalContext{})
+	if diags.HasErrors() || value.Type() != cty.String || value.IsNull() {
+		return ""
+	}
+	return value.AsString()
+}
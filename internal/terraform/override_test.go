@@ -0,0 +1,90 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteCredentialsOverride(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`
+terraform {
+  backend "s3" {
+    bucket = "state"
+  }
+}
+
+provider "aws" {
+  region  = "eu-west-3"
+  profile = "prod"
+}
+
+provider "aws" {
+  alias  = "us"
+  region = "us-east-1"
+}
+
+provider "random" {}
+`), 0644)
+	assert.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "google.tf"), []byte(`
+provider "google" {
+  credentials = file("key.json")
+}
+`), 0644)
+	assert.NoError(t, err)
+
+	removeOverride, err := writeCredentialsOverride(dir)
+	assert.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(dir, overrideFileName))
+	assert.NoError(t, err)
+	assert.Equal(t, `provider "google" {
+  access_token = "carbonifer"
+  credentials  = null
+}
+provider "aws" {
+  access_key                  = "carbonifer"
+  profile                     = null
+  secret_key                  = "carbonifer"
+  skip_credentials_validation = true
+  skip_metadata_api_check     = true
+  skip_region_validation      = true
+  skip_requesting_account_id  = true
+}
+provider "aws" {
+  alias                       = "us"
+  access_key                  = "carbonifer"
+  profile                     = null
+  secret_key                  = "carbonifer"
+  skip_credentials_validation = true
+  skip_metadata_api_check     = true
+  skip_region_validation      = true
+  skip_requesting_account_id  = true
+}
+terraform {
+  backend "local" {
+  }
+}
+`, string(content))
+
+	// Only one override at a time
+	_, err = writeCredentialsOverride(dir)
+	assert.Error(t, err)
+
+	removeOverride()
+	_, err = os.Stat(filepath.Join(dir, overrideFileName))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestWriteCredentialsOverride_NoBackend(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "main.tf"), []byte(`resource "google_compute_instance" "vm" {}`), 0644)
+	assert.NoError(t, err)
+
+	content, err := credentialsOverride(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, string(content))
+}
@@ -0,0 +1,157 @@
+package terraform
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+)
+
+// PlanSettings are the var files, variables, targets and workspace passed to terraform plan
+type PlanSettings struct {
+	VarFiles  []string
+	Vars      []string // "name=value"
+	Targets   []string
+	Workspace string
+	Refresh   bool
+	Lock      bool
+	// SkipCredentials plans with a temporary override file configuring providers without credentials and a local backend
+	SkipCredentials bool
+}
+
+// usedPlanSettings are the settings of the last plan generated from a terraform directory, with the workspace used,
+// nil if the plan was read from a file
+var usedPlanSettings *PlanSettings
+
+// usedPlanUnresolved are the unresolved attributes of the last plan, if generated offline
+var usedPlanUnresolved []UnresolvedAttribute
+
+// GetPlanSettings reads the terraform plan settings from config
+func GetPlanSettings() PlanSettings {
+	varFiles := []string{}
+	for _, varFile := range viper.GetStringSlice("terraform.var_files") {
+		// terraform runs in the workdir, var files are relative to where carbonifer is run
+		if absVarFile, err := filepath.Abs(varFile); err == nil {
+			varFile = absVarFile
+		}
+		varFiles = append(varFiles, varFile)
+	}
+	return PlanSettings{
+		VarFiles:  varFiles,
+		Vars:      viper.GetStringSlice("terraform.vars"),
+		Targets:   viper.GetStringSlice("terraform.targets"),
+		Workspace: viper.GetString("terraform.workspace"),
+		Refresh:   viper.GetBool("terraform.refresh"),
+		Lock:      viper.GetBool("terraform.lock"),
+
+		SkipCredentials: viper.GetBool("terraform.skip_credentials"),
+	}
+}
+
+// Variables returns the variables given with --var as a map
+func (s PlanSettings) Variables() (map[string]string, error) {
+	variables := map[string]string{}
+	for _, variable := range s.Vars {
+		name, value, found := strings.Cut(variable, "=")
+		if !found || name == "" {
+			return nil, errors.Errorf("Invalid variable %q, expected name=value", variable)
+		}
+		variables[name] = value
+	}
+	return variables, nil
+}
+
+func (s PlanSettings) initOptions() []tfexec.InitOption {
+	// The backend is replaced by a local one when skipping credentials
+	return []tfexec.InitOption{tfexec.Lock(s.Lock), tfexec.Reconfigure(s.SkipCredentials)}
+}
+
+func (s PlanSettings) planOptions(out string) []tfexec.PlanOption {
+	options := []tfexec.PlanOption{
+		tfexec.Out(out),
+		tfexec.Lock(s.Lock),
+		tfexec.Refresh(s.Refresh),
+	}
+	for _, varFile := range s.VarFiles {
+		options = append(options, tfexec.VarFile(varFile))
+	}
+	for _, variable := range s.Vars {
+		options = append(options, tfexec.Var(variable))
+	}
+	for _, target := range s.Targets {
+		options = append(options, tfexec.Target(target))
+	}
+	return options
+}
+
+// selectWorkspace switches to the configured workspace, it must already exist.
+// It returns the workspace used by terraform.
+func (s PlanSettings) selectWorkspace(ctx context.Context, tf *tfexec.Terraform) (string, error) {
+	workspaces, current, err := tf.WorkspaceList(ctx)
+	if err != nil {
+		return "", &TerraformError{Command: "workspace list", ParentError: err}
+	}
+	if s.Workspace == "" || current == s.Workspace {
+		return current, nil
+	}
+	found := false
+	for _, workspace := range workspaces {
+		found = found || workspace == s.Workspace
+	}
+	if !found {
+		return "", errors.Errorf("Terraform workspace %q does not exist, available workspaces: %v", s.Workspace, strings.Join(workspaces, ", "))
+	}
+	log.Debugf("Selecting terraform workspace %v", s.Workspace)
+	if err := tf.WorkspaceSelect(ctx, s.Workspace); err != nil {
+		return "", &TerraformError{Command: "workspace select", ParentError: err}
+	}
+	return s.Workspace, nil
+}
+
+// GetPlanVariables returns the values of the root variables of a plan, sensitive ones are masked
+func GetPlanVariables(tfPlan *map[string]interface{}) map[string]interface{} {
+	planVariables, ok := (*tfPlan)["variables"].(map[string]interface{})
+	if !ok || len(planVariables) == 0 {
+		return nil
+	}
+	sensitive := map[string]bool{}
+	if configuration, ok := (*tfPlan)["configuration"].(map[string]interface{}); ok {
+		if rootModule, ok := configuration["root_module"].(map[string]interface{}); ok {
+			if configVariables, ok := rootModule["variables"].(map[string]interface{}); ok {
+				for name, variable := range configVariables {
+					if variableMap, ok := variable.(map[string]interface{}); ok {
+						sensitive[name], _ = variableMap["sensitive"].(bool)
+					}
+				}
+			}
+		}
+	}
+
+	variables := map[string]interface{}{}
+	for name, variable := range planVariables {
+		variableMap, ok := variable.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if sensitive[name] {
+			variables[name] = "(sensitive)"
+		} else {
+			variables[name] = variableMap["value"]
+		}
+	}
+	return variables
+}
+
+// GetUsedPlanSettings returns the settings used to generate the last plan, nil if the plan was read from a file
+func GetUsedPlanSettings() *PlanSettings {
+	return usedPlanSettings
+}
+
+// GetUsedPlanUnresolved returns the attributes whose value is unknown in the last plan, empty if not generated offline
+func GetUsedPlanUnresolved() []UnresolvedAttribute {
+	return usedPlanUnresolved
+}
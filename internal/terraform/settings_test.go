@@ -0,0 +1,68 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestGetPlanSettings(t *testing.T) {
+	viper.Set("terraform.var_files", []string{"prod.tfvars"})
+	viper.Set("terraform.vars", []string{"size=2"})
+	viper.Set("terraform.workspace", "prod")
+	viper.Set("terraform.refresh", false)
+	viper.Set("terraform.lock", true)
+	defer func() {
+		for _, key := range []string{"terraform.var_files", "terraform.vars", "terraform.workspace", "terraform.refresh", "terraform.lock"} {
+			viper.Set(key, nil)
+		}
+	}()
+
+	workdir, _ := os.Getwd()
+	settings := GetPlanSettings()
+	assert.Equal(t, []string{filepath.Join(workdir, "prod.tfvars")}, settings.VarFiles)
+	assert.Equal(t, []string{"size=2"}, settings.Vars)
+	assert.Empty(t, settings.Targets)
+	assert.Equal(t, "prod", settings.Workspace)
+	assert.False(t, settings.Refresh)
+	assert.True(t, settings.Lock)
+	assert.Len(t, settings.planOptions("plan.tfplan"), 5)
+}
+
+func TestPlanSettings_Variables(t *testing.T) {
+	variables, err := PlanSettings{Vars: []string{"size=2", "tags=a=b", "empty="}}.Variables()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"size": "2", "tags": "a=b", "empty": ""}, variables)
+
+	_, err = PlanSettings{Vars: []string{"size"}}.Variables()
+	assert.Error(t, err)
+}
+
+func TestParseVariableValue(t *testing.T) {
+	assert.Equal(t, cty.StringVal("e2-standard-2"), parseVariableValue("e2-standard-2"))
+	assert.Equal(t, cty.TupleVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}), parseVariableValue(`["a", "b"]`))
+	assert.Equal(t, cty.StringVal("[not hcl"), parseVariableValue("[not hcl"))
+}
+
+func TestGetPlanVariables(t *testing.T) {
+	tfPlan := map[string]interface{}{
+		"variables": map[string]interface{}{
+			"region":   map[string]interface{}{"value": "europe-west9"},
+			"password": map[string]interface{}{"value": "secret"},
+		},
+		"configuration": map[string]interface{}{
+			"root_module": map[string]interface{}{
+				"variables": map[string]interface{}{
+					"region":   map[string]interface{}{"default": "us-central1"},
+					"password": map[string]interface{}{"sensitive": true},
+				},
+			},
+		},
+	}
+	assert.Equal(t, map[string]interface{}{"region": "europe-west9", "password": "(sensitive)"}, GetPlanVariables(&tfPlan))
+	assert.Nil(t, GetPlanVariables(&map[string]interface{}{}))
+}
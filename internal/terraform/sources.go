@@ -0,0 +1,229 @@
+package terraform
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// SourceLocation is the location of a resource block in the terraform files
+type SourceLocation struct {
+	File string
+	Line int
+}
+
+// SourceLocations are the locations of resource blocks, by address without instance keys (see ConfigAddress)
+type SourceLocations map[string]SourceLocation
+
+// Get returns the location of the block of a resource instance
+func (l SourceLocations) Get(address string) (SourceLocation, bool) {
+	location, ok := l[ConfigAddress(address)]
+	return location, ok
+}
+
+// modulesManifest is the manifest of the modules installed by terraform init (.terraform/modules/modules.json)
+type modulesManifest struct {
+	Modules []struct {
+		Key string `json:"Key"`
+		Dir string `json:"Dir"`
+	} `json:"Modules"`
+}
+
+// GetSourceLocations returns the locations of the resource blocks of a plan, read from the terraform files of dir.
+// The directory of each module is found from the module calls of the plan configuration: installed modules are
+// read from the manifest of terraform init, local modules from their source. Resources of offline plans have the
+// location of their block. Resources whose block is not found have no location.
+func GetSourceLocations(tfPlan *map[string]interface{}, dir string) (SourceLocations, error) {
+	locations := SourceLocations{}
+	configuration, ok := (*tfPlan)["configuration"].(map[string]interface{})
+	if !ok {
+		return locations, nil
+	}
+	rootModule, ok := configuration["root_module"].(map[string]interface{})
+	if !ok {
+		return locations, nil
+	}
+	manifestDirs, err := readModulesManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	reader := &sourcesReader{
+		rootDir:      dir,
+		manifestDirs: manifestDirs,
+		blocks:       map[string]SourceLocations{},
+		locations:    locations,
+	}
+	reader.readModule(rootModule, "", "", dir)
+	return locations, nil
+}
+
+// sourcesReader finds the blocks of the resources of the modules of a plan configuration
+type sourcesReader struct {
+	rootDir      string
+	manifestDirs map[string]string
+	blocks       map[string]SourceLocations // blocks of each directory, by type.name or data.type.name
+	locations    SourceLocations
+}
+
+// readModule adds the locations of the resources of a module configuration and of its module calls
+func (r *sourcesReader) readModule(module map[string]interface{}, key string, prefix string, dir string) {
+	resources, _ := module["resources"].([]interface{})
+	for _, resourceI := range resources {
+		resource, ok := resourceI.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		address, _ := resource["address"].(string)
+		resourceType, _ := resource["type"].(string)
+		name, _ := resource["name"].(string)
+		blockKey := resourceType + "." + name
+		if resource["mode"] == "data" {
+			blockKey = "data." + blockKey
+		}
+		// Offline plans have resources of all modules in the root module configuration, with the location of
+		// their block
+		if location, ok := offlineSourceLocation(resource); ok {
+			r.locations[prefix+ConfigAddress(address)] = location
+			continue
+		}
+		if ConfigAddress(address) != blockKey {
+			continue
+		}
+		if location, ok := r.dirBlocks(dir)[blockKey]; ok {
+			r.locations[prefix+blockKey] = location
+		}
+	}
+
+	moduleCalls, _ := module["module_calls"].(map[string]interface{})
+	for _, name := range sortedKeys(moduleCalls) {
+		moduleCall, ok := moduleCalls[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		childModule, ok := moduleCall["module"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		childKey := name
+		if key != "" {
+			childKey = key + "." + name
+		}
+		childDir, ok := r.manifestDirs[childKey]
+		if !ok {
+			source, _ := moduleCall["source"].(string)
+			if !strings.HasPrefix(source, "./") && !strings.HasPrefix(source, "../") {
+				log.Debugf("Module %v is not installed, its resources have no source location", childKey)
+				continue
+			}
+			childDir = filepath.Join(dir, source)
+		}
+		r.readModule(childModule, childKey, prefix+"module."+name+".", childDir)
+	}
+}
+
+// offlineSourceLocation returns the location of the block of a resource of an offline plan (see OfflinePlan)
+func offlineSourceLocation(resource map[string]interface{}) (SourceLocation, bool) {
+	sourceLocation, ok := resource["source_location"].(map[string]interface{})
+	if !ok {
+		return SourceLocation{}, false
+	}
+	file, _ := sourceLocation["file"].(string)
+	line, _ := sourceLocation["line"].(float64)
+	return SourceLocation{File: file, Line: int(line)}, file != ""
+}
+
+// dirBlocks returns the resource and data blocks of the terraform files of a directory
+func (r *sourcesReader) dirBlocks(dir string) SourceLocations {
+	if blocks, ok := r.blocks[dir]; ok {
+		return blocks
+	}
+	blocks := SourceLocations{}
+	r.blocks[dir] = blocks
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		log.Warnf("Cannot list terraform files of %v: %v", dir, err)
+		return blocks
+	}
+	sort.Strings(files)
+	parser := hclparse.NewParser()
+	for _, fileName := range files {
+		hclFile, diags := parser.ParseHCLFile(fileName)
+		if diags.HasErrors() {
+			log.Warnf("Cannot parse terraform file %v: %v", fileName, diags)
+			continue
+		}
+		for _, block := range hclFile.Body.(*hclsyntax.Body).Blocks {
+			if (block.Type != "resource" && block.Type != "data") || len(block.Labels) != 2 {
+				continue
+			}
+			blockKey := block.Labels[0] + "." + block.Labels[1]
+			if block.Type == "data" {
+				blockKey = "data." + blockKey
+			}
+			if _, ok := blocks[blockKey]; !ok {
+				blocks[blockKey] = SourceLocation{File: fileName, Line: block.DefRange().Start.Line}
+			}
+		}
+	}
+	return blocks
+}
+
+// readModulesManifest returns the directories of the modules installed by terraform init, by module key
+func readModulesManifest(dir string) (map[string]string, error) {
+	dirs := map[string]string{}
+	content, err := os.ReadFile(filepath.Join(dir, ".terraform", "modules", "modules.json"))
+	if os.IsNotExist(err) {
+		return dirs, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read terraform modules manifest")
+	}
+	var manifest modulesManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, errors.Wrap(err, "Cannot parse terraform modules manifest")
+	}
+	for _, module := range manifest.Modules {
+		if module.Key == "" {
+			continue
+		}
+		dirs[module.Key] = filepath.Join(dir, module.Dir)
+	}
+	return dirs, nil
+}
+
+// ConfigAddress returns the address of the block of a resource instance, without the instance keys of the resource
+// and of its modules. Ex: module.a["x"].google_compute_instance.b[0] -> module.a.google_compute_instance.b
+func ConfigAddress(address string) string {
+	builder := &strings.Builder{}
+	depth := 0
+	inString := false
+	escaped := false
+	for _, char := range address {
+		switch {
+		case inString:
+			if escaped {
+				escaped = false
+			} else if char == '\\' {
+				escaped = true
+			} else if char == '"' {
+				inString = false
+			}
+		case char == '"' && depth > 0:
+			inString = true
+		case char == '[':
+			depth++
+		case char == ']':
+			depth--
+		case depth == 0:
+			builder.WriteRune(char)
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,117 @@
+package terraform
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSourceLocations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.tf": `
+resource "google_compute_instance" "web" {
+  name = "web"
+}
+
+data "google_compute_image" "debian" {
+  family = "debian-11"
+}
+`,
+		"modules/db/main.tf": `
+variable "name" {}
+
+resource "google_sql_database_instance" "db" {
+  name = var.name
+}
+`,
+		".terraform/modules/gke/cluster.tf": `
+resource "google_container_cluster" "main" {
+  name = "gke"
+}
+`,
+		".terraform/modules/modules.json": `{"Modules":[{"Key":"","Source":"","Dir":"."},{"Key":"gke","Source":"terraform-google-modules/kubernetes-engine/google","Dir":".terraform/modules/gke"}]}`,
+	}
+	for name, content := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	tfPlan := map[string]interface{}{
+		"configuration": map[string]interface{}{
+			"root_module": map[string]interface{}{
+				"resources": []interface{}{
+					map[string]interface{}{"address": "google_compute_instance.web", "mode": "managed", "type": "google_compute_instance", "name": "web"},
+					map[string]interface{}{"address": "data.google_compute_image.debian", "mode": "data", "type": "google_compute_image", "name": "debian"},
+					map[string]interface{}{"address": "google_compute_instance.missing", "mode": "managed", "type": "google_compute_instance", "name": "missing"},
+				},
+				"module_calls": map[string]interface{}{
+					"db": map[string]interface{}{
+						"source": "./modules/db",
+						"module": map[string]interface{}{
+							"resources": []interface{}{
+								map[string]interface{}{"address": "google_sql_database_instance.db", "mode": "managed", "type": "google_sql_database_instance", "name": "db"},
+							},
+						},
+					},
+					"gke": map[string]interface{}{
+						"source": "terraform-google-modules/kubernetes-engine/google",
+						"module": map[string]interface{}{
+							"resources": []interface{}{
+								map[string]interface{}{"address": "google_container_cluster.main", "mode": "managed", "type": "google_container_cluster", "name": "main"},
+							},
+						},
+					},
+					"remote": map[string]interface{}{
+						"source": "git::https://example.com/modules.git",
+						"module": map[string]interface{}{
+							"resources": []interface{}{
+								map[string]interface{}{"address": "google_compute_instance.web", "mode": "managed", "type": "google_compute_instance", "name": "web"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	locations, err := GetSourceLocations(&tfPlan, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, SourceLocations{
+		"google_compute_instance.web":               {File: filepath.Join(dir, "main.tf"), Line: 2},
+		"data.google_compute_image.debian":          {File: filepath.Join(dir, "main.tf"), Line: 6},
+		"module.db.google_sql_database_instance.db": {File: filepath.Join(dir, "modules/db/main.tf"), Line: 4},
+		"module.gke.google_container_cluster.main":  {File: filepath.Join(dir, ".terraform/modules/gke/cluster.tf"), Line: 2},
+	}, locations)
+
+	location, ok := locations.Get(`module.db["eu"].google_sql_database_instance.db[0]`)
+	assert.True(t, ok)
+	assert.Equal(t, 4, location.Line)
+	_, ok = locations.Get("module.remote.google_compute_instance.web")
+	assert.False(t, ok)
+}
+
+func TestConfigAddress(t *testing.T) {
+	assert.Equal(t, "google_compute_instance.web", ConfigAddress("google_compute_instance.web"))
+	assert.Equal(t, "google_compute_instance.web", ConfigAddress("google_compute_instance.web[2]"))
+	assert.Equal(t, "module.a.module.b.google_compute_instance.web", ConfigAddress(`module.a["x.y[0]"].module.b[1].google_compute_instance.web["k\"]"]`))
+}
+
+func TestGetSourceLocations_Offline(t *testing.T) {
+	dir, err := filepath.Abs(offlineTestDir())
+	assert.NoError(t, err)
+	tfPlan, _, err := OfflinePlan(dir, PlanSettings{})
+	assert.NoError(t, err)
+
+	// Resources of local modules are in the root module configuration of offline plans
+	locations, err := GetSourceLocations(tfPlan, dir)
+	assert.NoError(t, err)
+	location, ok := locations.Get("module.storage.google_compute_disk.data")
+	assert.True(t, ok)
+	assert.Equal(t, SourceLocation{File: filepath.Join(dir, "storage", "main.tf"), Line: 10}, location)
+	location, ok = locations.Get("google_compute_instance.web[1]")
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "main.tf"), location.File)
+}
@@ -0,0 +1,292 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// isStateJSON returns true if the JSON is a terraform state: a state file (terraform.tfstate)
+// or the output of 'terraform show -json' on a state
+func isStateJSON(content map[string]interface{}) bool {
+	if _, isPlan := content["planned_values"]; isPlan {
+		return false
+	}
+	_, hasValues := content["values"]
+	// Only state files have a version (plans have a format_version)
+	_, hasVersion := content["version"]
+	return hasValues || hasVersion
+}
+
+// stateToPlan converts a terraform state into the shape of a terraform plan JSON, so mappings work on live state:
+//   - planned_values and prior_state contain the resources of the state (data sources only in prior_state)
+//   - configuration expressions are rebuilt from attributes referencing other resources (id, self_link or name)
+func stateToPlan(state map[string]interface{}) (*map[string]interface{}, error) {
+	values, ok := state["values"].(map[string]interface{})
+	if !ok {
+		// State file, convert it to the output of 'terraform show -json'
+		var err error
+		values, err = stateFileToValues(state)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rootModule, ok := values["root_module"].(map[string]interface{})
+	if !ok {
+		rootModule = map[string]interface{}{}
+		values["root_module"] = rootModule
+	}
+
+	allResources := moduleResources(rootModule)
+	log.Debugf("Read %v resources from terraform state", len(allResources))
+
+	plan := map[string]interface{}{
+		"format_version":    state["format_version"],
+		"terraform_version": state["terraform_version"],
+		"planned_values": map[string]interface{}{
+			"root_module": managedModule(rootModule),
+		},
+		"prior_state": map[string]interface{}{
+			"values": values,
+		},
+		"configuration": map[string]interface{}{
+			// All resources, with their full address, are in the root module
+			"root_module": map[string]interface{}{
+				"resources": configurationResources(allResources),
+			},
+		},
+	}
+	return &plan, nil
+}
+
+// stateFileToValues converts the resources of a state file to the values of 'terraform show -json'
+func stateFileToValues(state map[string]interface{}) (map[string]interface{}, error) {
+	if version, ok := state["version"].(float64); ok && version < 4 {
+		return nil, errors.Errorf("Unsupported terraform state version %v, only version 4 and above are supported", version)
+	}
+	stateResources, _ := state["resources"].([]interface{})
+
+	modules := map[string]map[string]interface{}{
+		"": {"resources": []interface{}{}},
+	}
+	for _, stateResourceI := range stateResources {
+		stateResource, ok := stateResourceI.(map[string]interface{})
+		if !ok {
+			return nil, errors.Errorf("Cannot parse state resource %v", stateResourceI)
+		}
+		modulePath, _ := stateResource["module"].(string)
+		module := getOrCreateModule(modules, modulePath)
+
+		mode, _ := stateResource["mode"].(string)
+		resourceType, _ := stateResource["type"].(string)
+		name, _ := stateResource["name"].(string)
+		address := resourceType + "." + name
+		if mode == "data" {
+			address = "data." + address
+		}
+		if modulePath != "" {
+			address = modulePath + "." + address
+		}
+
+		instances, _ := stateResource["instances"].([]interface{})
+		for _, instanceI := range instances {
+			instance, ok := instanceI.(map[string]interface{})
+			if !ok {
+				return nil, errors.Errorf("Cannot parse instance of state resource %v", address)
+			}
+			resource := map[string]interface{}{
+				"address":        address,
+				"mode":           mode,
+				"type":           resourceType,
+				"name":           name,
+				"provider_name":  providerName(stateResource["provider"]),
+				"schema_version": instance["schema_version"],
+				"values":         instance["attributes"],
+			}
+			if indexKey, ok := instance["index_key"]; ok {
+				resource["index"] = indexKey
+				switch key := indexKey.(type) {
+				case string:
+					resource["address"] = fmt.Sprintf("%v[%q]", address, key)
+				default:
+					resource["address"] = fmt.Sprintf("%v[%v]", address, key)
+				}
+			}
+			module["resources"] = append(module["resources"].([]interface{}), resource)
+		}
+	}
+	return map[string]interface{}{
+		"outputs":     state["outputs"],
+		"root_module": modules[""],
+	}, nil
+}
+
+// getOrCreateModule returns the module of a module path (ex: module.app.module.db), creating its parents if needed
+func getOrCreateModule(modules map[string]map[string]interface{}, modulePath string) map[string]interface{} {
+	if module, ok := modules[modulePath]; ok {
+		return module
+	}
+	parentPath := ""
+	if i := strings.LastIndex(modulePath, ".module."); i >= 0 {
+		parentPath = modulePath[:i]
+	}
+	parent := getOrCreateModule(modules, parentPath)
+	module := map[string]interface{}{
+		"address":   modulePath,
+		"resources": []interface{}{},
+	}
+	childModules, _ := parent["child_modules"].([]interface{})
+	parent["child_modules"] = append(childModules, module)
+	modules[modulePath] = module
+	return module
+}
+
+// providerName converts the provider of a state file (provider["registry.terraform.io/hashicorp/google"])
+// to the provider name of a plan (registry.terraform.io/hashicorp/google)
+func providerName(providerI interface{}) string {
+	provider, _ := providerI.(string)
+	if start := strings.Index(provider, "[\""); start >= 0 {
+		if end := strings.Index(provider[start+2:], "\"]"); end >= 0 {
+			return provider[start+2 : start+2+end]
+		}
+	}
+	return provider
+}
+
+// moduleResources returns the resources of a module and its child modules
+func moduleResources(module map[string]interface{}) []map[string]interface{} {
+	result := []map[string]interface{}{}
+	resources, _ := module["resources"].([]interface{})
+	for _, resourceI := range resources {
+		if resource, ok := resourceI.(map[string]interface{}); ok {
+			result = append(result, resource)
+		}
+	}
+	childModules, _ := module["child_modules"].([]interface{})
+	for _, childModuleI := range childModules {
+		if childModule, ok := childModuleI.(map[string]interface{}); ok {
+			result = append(result, moduleResources(childModule)...)
+		}
+	}
+	return result
+}
+
+// managedModule returns a copy of a module without its data sources, as planned values of a plan
+func managedModule(module map[string]interface{}) map[string]interface{} {
+	result := map[string]interface{}{}
+	for key, value := range module {
+		result[key] = value
+	}
+	managedResources := []interface{}{}
+	resources, _ := module["resources"].([]interface{})
+	for _, resourceI := range resources {
+		resource, ok := resourceI.(map[string]interface{})
+		if ok && resource["mode"] != "data" {
+			managedResources = append(managedResources, resource)
+		}
+	}
+	result["resources"] = managedResources
+	childModules, _ := module["child_modules"].([]interface{})
+	if childModules != nil {
+		managedChildModules := []interface{}{}
+		for _, childModuleI := range childModules {
+			if childModule, ok := childModuleI.(map[string]interface{}); ok {
+				managedChildModules = append(managedChildModules, managedModule(childModule))
+			}
+		}
+		result["child_modules"] = managedChildModules
+	}
+	return result
+}
+
+// configurationResources rebuilds the configuration of resources: an attribute whose value is the id, self_link
+// or (unique) name of another resource becomes an expression referencing it
+func configurationResources(resources []map[string]interface{}) []interface{} {
+	referencedAttributes := map[string][]interface{}{}
+	names := map[string][]string{}
+	for _, resource := range resources {
+		address, _ := resource["address"].(string)
+		values, _ := resource["values"].(map[string]interface{})
+		// Index is not part of references
+		referenceAddress := address
+		if i := strings.LastIndex(address, "["); i > strings.LastIndex(address, ".") {
+			referenceAddress = address[:i]
+		}
+		for _, attribute := range []string{"id", "self_link"} {
+			if value, ok := values[attribute].(string); ok && value != "" {
+				referencedAttributes[value] = []interface{}{referenceAddress + "." + attribute, referenceAddress}
+			}
+		}
+		if value, ok := values["name"].(string); ok && value != "" {
+			names[value] = append(names[value], referenceAddress)
+		}
+	}
+	for name, addresses := range names {
+		if _, ok := referencedAttributes[name]; !ok && len(addresses) == 1 {
+			referencedAttributes[name] = []interface{}{addresses[0] + ".name", addresses[0]}
+		}
+	}
+
+	configuration := []interface{}{}
+	for _, resource := range resources {
+		expressions, _ := toExpressions(resource["values"], referencedAttributes).(map[string]interface{})
+		if expressions == nil {
+			expressions = map[string]interface{}{}
+		}
+		configuration = append(configuration, map[string]interface{}{
+			"address":             resource["address"],
+			"mode":                resource["mode"],
+			"type":                resource["type"],
+			"name":                resource["name"],
+			"provider_config_key": resource["provider_name"],
+			"expressions":         expressions,
+		})
+	}
+	return configuration
+}
+
+// toExpressions converts attribute values to configuration expressions, as in the configuration of a plan
+func toExpressions(value interface{}, referencedAttributes map[string][]interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		expressions := map[string]interface{}{}
+		keys := make([]string, 0, len(typedValue))
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if expression := toExpressions(typedValue[key], referencedAttributes); expression != nil {
+				expressions[key] = expression
+			}
+		}
+		return expressions
+	case []interface{}:
+		// Blocks are lists of expressions, other lists are constant values
+		blocks := []interface{}{}
+		for _, item := range typedValue {
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				return constantExpression(value, referencedAttributes)
+			}
+			blocks = append(blocks, toExpressions(itemMap, referencedAttributes))
+		}
+		return blocks
+	case nil:
+		return nil
+	default:
+		return constantExpression(value, referencedAttributes)
+	}
+}
+
+func constantExpression(value interface{}, referencedAttributes map[string][]interface{}) map[string]interface{} {
+	if valueString, ok := value.(string); ok {
+		if references, ok := referencedAttributes[valueString]; ok {
+			return map[string]interface{}{"references": references}
+		}
+	}
+	return map[string]interface{}{"constant_value": value}
+}
@@ -0,0 +1,77 @@
+package terraform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStateToPlan(t *testing.T) {
+	state := map[string]interface{}{
+		"version":           float64(4),
+		"terraform_version": "1.5.0",
+		"resources": []interface{}{
+			map[string]interface{}{
+				"mode":     "data",
+				"type":     "google_compute_image",
+				"name":     "debian",
+				"provider": `provider["registry.terraform.io/hashicorp/google"]`,
+				"instances": []interface{}{
+					map[string]interface{}{"attributes": map[string]interface{}{"self_link": "https://debian"}},
+				},
+			},
+			map[string]interface{}{
+				"module":   "module.app.module.vm",
+				"mode":     "managed",
+				"type":     "google_compute_instance",
+				"name":     "vm",
+				"provider": `module.app.module.vm.provider["registry.terraform.io/hashicorp/google"]`,
+				"instances": []interface{}{
+					map[string]interface{}{
+						"index_key": "a",
+						"attributes": map[string]interface{}{
+							"name":      "vm",
+							"boot_disk": []interface{}{map[string]interface{}{"image": "https://debian"}},
+							"tags":      []interface{}{"web"},
+						},
+					},
+				},
+			},
+		},
+	}
+	tfPlan, err := stateToPlan(state)
+	assert.NoError(t, err)
+
+	plannedRoot := (*tfPlan)["planned_values"].(map[string]interface{})["root_module"].(map[string]interface{})
+	assert.Empty(t, plannedRoot["resources"])
+	appModule := plannedRoot["child_modules"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "module.app", appModule["address"])
+	vmModule := appModule["child_modules"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "module.app.module.vm", vmModule["address"])
+	vm := vmModule["resources"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, `module.app.module.vm.google_compute_instance.vm["a"]`, vm["address"])
+	assert.Equal(t, "registry.terraform.io/hashicorp/google", vm["provider_name"])
+
+	priorRoot := (*tfPlan)["prior_state"].(map[string]interface{})["values"].(map[string]interface{})["root_module"].(map[string]interface{})
+	image := priorRoot["resources"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "data.google_compute_image.debian", image["address"])
+
+	configuration := (*tfPlan)["configuration"].(map[string]interface{})["root_module"].(map[string]interface{})["resources"].([]interface{})
+	assert.Len(t, configuration, 2)
+	expressions := configuration[1].(map[string]interface{})["expressions"].(map[string]interface{})
+	bootDisk := expressions["boot_disk"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, []interface{}{"data.google_compute_image.debian.self_link", "data.google_compute_image.debian"}, bootDisk["image"].(map[string]interface{})["references"])
+	assert.Equal(t, []interface{}{"web"}, expressions["tags"].(map[string]interface{})["constant_value"])
+}
+
+func TestStateToPlan_OldVersion(t *testing.T) {
+	_, err := stateToPlan(map[string]interface{}{"version": float64(3), "modules": []interface{}{}})
+	assert.Error(t, err)
+}
+
+func TestIsStateJSON(t *testing.T) {
+	assert.True(t, isStateJSON(map[string]interface{}{"values": map[string]interface{}{}}))
+	assert.True(t, isStateJSON(map[string]interface{}{"version": float64(4), "resources": []interface{}{}}))
+	assert.True(t, isStateJSON(map[string]interface{}{"version": float64(3), "modules": []interface{}{}}))
+	assert.False(t, isStateJSON(map[string]interface{}{"planned_values": map[string]interface{}{}, "prior_state": map[string]interface{}{}}))
+}
@@ -1,60 +1,67 @@
 package terraform
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
-	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/hashicorp/go-version"
 	"github.com/hashicorp/hc-install/product"
 	"github.com/hashicorp/hc-install/releases"
 	"github.com/hashicorp/terraform-exec/tfexec"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
 var terraformExec *tfexec.Terraform
+var terraformExecMutex sync.Mutex
 
 func GetTerraformExec() (*tfexec.Terraform, error) {
+	terraformExecMutex.Lock()
+	defer terraformExecMutex.Unlock()
 	if terraformExec == nil {
 		log.Debugf("Finding or installing terraform exec")
 		// Check if terraform is already installed
 		execPath, err := exec.LookPath("terraform")
 		if err != nil {
 			log.Info("Terraform exec not found. Installing...")
-			execPath = installTerraform()
+			execPath, err = installTerraform()
+			if err != nil {
+				return nil, &TerraformError{Command: "install", ParentError: err}
+			}
 		} else {
 			log.Info("Using Terraform exec from ", execPath)
 		}
 
-		terraformExec, err = tfexec.NewTerraform(viper.GetString("workdir"), execPath)
+		tf, err := tfexec.NewTerraform(viper.GetString("workdir"), execPath)
 		if err != nil {
 			return nil, err
 		}
-		version, _, err := terraformExec.Version(context.Background(), true)
+		version, _, err := tf.Version(context.Background(), true)
 		if err != nil {
-			log.Fatal(err)
+			return nil, &TerraformError{Command: "version", ParentError: err}
 		}
 
 		log.Infof("Using terraform %v", version)
-		if err != nil {
-			log.Fatalf("error running NewTerraform: %v", err)
-		}
+		terraformExec = tf
 	}
 
 	return terraformExec, nil
 }
 
 func ResetTerraformExec() {
+	terraformExecMutex.Lock()
 	terraformExec = nil
+	terraformExecMutex.Unlock()
+	resetConsoleSessions()
 }
 
-func installTerraform() string {
+func installTerraform() (string, error) {
 	var execPath string
 	terraformInstallDir := viper.GetString("terraform.path")
 	if terraformInstallDir != "" {
@@ -72,7 +79,7 @@ func installTerraform() string {
 		var err error
 		execPath, err = installer.Install(ctx)
 		if err != nil {
-			log.Fatalf("error installing Terraform: %v", err)
+			return "", err
 		}
 	} else {
 		log.Debugf("Terraform version not configured, picking latest")
@@ -83,15 +90,15 @@ func installTerraform() string {
 		var err error
 		execPath, err = installer.Install(ctx)
 		if err != nil {
-			log.Fatalf("error installing Terraform: %v", err)
+			return "", err
 		}
 	}
 
 	log.Infof("Terraform is installed in %v", execPath)
-	return execPath
+	return execPath, nil
 }
 
-func terraformInit() (*tfexec.Terraform, *context.Context, error) {
+func terraformInit(options ...tfexec.InitOption) (*tfexec.Terraform, *context.Context, error) {
 	tf, err := GetTerraformExec()
 	if err != nil {
 		return nil, nil, err
@@ -102,15 +109,15 @@ func terraformInit() (*tfexec.Terraform, *context.Context, error) {
 	ctx := context.Background()
 
 	// Terraform init
-	err = tf.Init(ctx)
+	err = tf.Init(ctx, options...)
 	if err != nil {
-		return nil, &ctx, err
+		return nil, &ctx, &TerraformError{Command: "init", ParentError: err}
 	}
 
 	return tf, &ctx, err
 }
 
-// CarboniferPlan generates a Terraform plan from a tfplan file or a Terraform directory
+// CarboniferPlan generates a Terraform plan from a tfplan file, a plan or state JSON, a tfstate file or a Terraform directory
 func CarboniferPlan(input string) (*map[string]interface{}, error) {
 	fileInfo, err := os.Stat(input)
 	if err != nil {
@@ -124,6 +131,8 @@ func CarboniferPlan(input string) (*map[string]interface{}, error) {
 		viper.AddConfigPath(filepath.Join(parentDir, ".carbonifer"))
 		fileName := filepath.Base(input)
 		viper.Set("workdir", parentDir)
+		usedPlanSettings = nil
+		usedPlanUnresolved = nil
 		tfPlan, err := terraformShow(fileName)
 		return tfPlan, err
 	} else {
@@ -133,16 +142,36 @@ func CarboniferPlan(input string) (*map[string]interface{}, error) {
 	// Refresh viper config
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			log.Panic(err)
+			return nil, err
 		}
 	}
 
 	// If the path points to a directory, run plan
 	viper.Set("workdir", input)
+	usedPlanSettings = nil
+	usedPlanUnresolved = nil
+	if viper.GetBool("offline") {
+		log.Debugf("Reading terraform files of %v offline", input)
+		settings := GetPlanSettings()
+		tfPlan, unresolved, err := OfflinePlan(input, settings)
+		if err != nil {
+			return nil, err
+		}
+		for _, attribute := range unresolved {
+			log.Warnf("Cannot resolve %v", attribute)
+		}
+		if settings.Workspace == "" {
+			settings.Workspace = "default"
+		}
+		settings.Targets = nil // ignored offline
+		usedPlanSettings = &settings
+		usedPlanUnresolved = unresolved
+		return tfPlan, nil
+	}
 	tfPlan, err := TerraformPlan()
 	if err != nil {
 		if e, ok := err.(*ProviderAuthError); ok {
-			log.Warnf("Skipping Authentication error: %v", e)
+			log.Warnf("Skipping Authentication error: %v (use --skip-credentials to plan without credentials)", e)
 		} else {
 			return nil, err
 		}
@@ -151,8 +180,27 @@ func CarboniferPlan(input string) (*map[string]interface{}, error) {
 
 }
 
+// TerraformPlan runs terraform plan in the workdir with the configured var files, variables, targets and workspace
 func TerraformPlan() (*map[string]interface{}, error) {
-	tf, ctx, err := terraformInit()
+	settings := GetPlanSettings()
+	if settings.SkipCredentials {
+		tf, err := GetTerraformExec()
+		if err != nil {
+			return nil, err
+		}
+		removeOverride, err := writeCredentialsOverride(tf.WorkingDir())
+		if err != nil {
+			return nil, err
+		}
+		defer removeOverride()
+	}
+	tf, ctx, err := terraformInit(settings.initOptions()...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Terraform workspace
+	settings.Workspace, err = settings.selectWorkspace(*ctx, tf)
 	if err != nil {
 		return nil, err
 	}
@@ -160,25 +208,25 @@ func TerraformPlan() (*map[string]interface{}, error) {
 	// Terraform Validate
 	_, err = tf.Validate(*ctx)
 	if err != nil {
-		return nil, err
+		return nil, &TerraformError{Command: "validate", ParentError: err}
 	}
 
 	// Create Temp out plan file
 	cfDir, err := os.MkdirTemp(tf.WorkingDir(), ".carbonifer")
 	if err != nil {
-		log.Panic(err)
+		return nil, err
 	}
 	log.Debugf("Created temporary terraform plan directory %v", cfDir)
 
 	defer func() {
 		if err := os.RemoveAll(cfDir); err != nil {
-			log.Fatal(err)
+			log.Errorf("Cannot remove temporary terraform plan directory %v: %v", cfDir, err)
 		}
 	}()
 
 	tfPlanFile, err := os.CreateTemp(cfDir, "plan-*.tfplan")
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
 	// Log useful info
@@ -187,7 +235,7 @@ func TerraformPlan() (*map[string]interface{}, error) {
 	log.Debugf("Running terraform exec %v", tf.ExecPath())
 
 	// Run Terraform Plan with an output file
-	err = terraformPlanExec(*ctx, tf, tfPlanFile)
+	err = terraformPlanExec(*ctx, tf, tfPlanFile, settings)
 	if err != nil {
 		return nil, err
 	}
@@ -195,8 +243,7 @@ func TerraformPlan() (*map[string]interface{}, error) {
 	// Run Terraform Show reading file outputed in step above
 	tfplan, err := tf.ShowPlanFile(*ctx, tfPlanFile.Name())
 	if err != nil {
-		log.Infof("error running  Terraform Show: %s", err)
-		return nil, err
+		return nil, &TerraformError{Command: "show", ParentError: err}
 	}
 	var bytes []byte
 	bytes, err = json.MarshalIndent(tfplan, "", "  ")
@@ -209,12 +256,12 @@ func TerraformPlan() (*map[string]interface{}, error) {
 	if err != nil {
 		return nil, err
 	}
+	usedPlanSettings = &settings
 	return &tfplanJSON, nil
 }
 
-func terraformPlanExec(ctx context.Context, tf *tfexec.Terraform, tfPlanFile *os.File) error {
-	out := tfexec.Out(tfPlanFile.Name())
-	_, err := tf.Plan(ctx, out)
+func terraformPlanExec(ctx context.Context, tf *tfexec.Terraform, tfPlanFile *os.File, settings PlanSettings) error {
+	_, err := tf.Plan(ctx, settings.planOptions(tfPlanFile.Name())...)
 	var authError ProviderAuthError
 	if err != nil {
 		uwErr := err.Error()
@@ -224,15 +271,14 @@ func terraformPlanExec(ctx context.Context, tf *tfexec.Terraform, tfPlanFile *os
 			authError = ProviderAuthError{ParentError: err}
 			return &authError
 		}
-		log.Errorf("error running  Terraform Plan: %s", err)
-		return err
+		return &TerraformError{Command: "plan", ParentError: err}
 
 	}
 	return nil
 }
 
 func terraformShow(fileName string) (*map[string]interface{}, error) {
-	if strings.HasSuffix(fileName, ".json") {
+	if strings.HasSuffix(fileName, ".json") || strings.HasSuffix(fileName, ".tfstate") {
 		planFilePath := filepath.Join(viper.GetString("workdir"), fileName)
 		log.Debugf("Reading Terraform plan from %v", planFilePath)
 		jsonFile, err := os.Open(planFilePath)
@@ -247,6 +293,10 @@ func terraformShow(fileName string) (*map[string]interface{}, error) {
 		if err != nil {
 			return nil, err
 		}
+		if isStateJSON(tfplan) {
+			log.Debugf("Reading %v as a Terraform state", planFilePath)
+			return stateToPlan(tfplan)
+		}
 		return &tfplan, nil
 	}
 
@@ -258,11 +308,11 @@ func terraformShow(fileName string) (*map[string]interface{}, error) {
 	// Run Terraform Show
 	tfPlan, err := tf.ShowPlanFile(*ctx, fileName)
 	if err != nil {
-		return nil, err
+		return nil, &TerraformError{Command: "show", ParentError: err}
 	}
 	tfPlanJSONBytes, err := json.MarshalIndent(tfPlan, "", "  ")
 	if err != nil {
-		log.Fatalf("Failed to marshal plan: %v", err)
+		return nil, errors.Wrap(err, "Failed to marshal plan")
 	}
 
 	var tfPlanJSON map[string]interface{}
@@ -273,42 +323,3 @@ func terraformShow(fileName string) (*map[string]interface{}, error) {
 
 	return &tfPlanJSON, nil
 }
-
-func RunTerraformConsole(command string) (*string, error) {
-	tfExec, err := GetTerraformExec()
-	if err != nil {
-		return nil, err
-	}
-	cmd := exec.Command(tfExec.ExecPath(), "console")
-
-	cmd.Dir = terraformExec.WorkingDir() // set the working directory
-
-	var stdin bytes.Buffer
-	stdin.Write([]byte(command + "\n"))
-	cmd.Stdin = &stdin
-
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-
-	err = cmd.Run()
-	if err != nil {
-		return nil, fmt.Errorf("error running terraform console: %w\nstderr: %s", err, stderr.String())
-	}
-
-	output := strings.TrimSpace(stdout.String())
-
-	// Parse the output as JSON
-	var result map[string]string
-	if err := json.Unmarshal([]byte(output), &result); err == nil {
-		// If the output is valid JSON, extract the value of the command key
-		var ok bool
-		output, ok = result[command]
-		if !ok {
-			return nil, fmt.Errorf("output does not contain key %q", command)
-		}
-	}
-	// remove quotes surrounding the value
-	output = strings.Trim(output, "\"")
-	return &output, nil
-}
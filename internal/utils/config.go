@@ -8,6 +8,8 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/heirko/go-contrib/logrusHelper"
 	log "github.com/sirupsen/logrus"
@@ -17,6 +19,49 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// configInitialized is true once the configuration has been initialized
+var configInitialized bool
+var configInitMutex sync.Mutex
+
+// EnsureConfig initializes the configuration with the default config file, unless it is already initialized
+// (carbonifer used as a library)
+func EnsureConfig() {
+	configInitMutex.Lock()
+	defer configInitMutex.Unlock()
+	if !configInitialized {
+		InitWithDefaultConfig()
+	}
+}
+
+// DefaultValue returns the default value of a config key (ex: "unit.time"), nil if it has none
+func DefaultValue(key string) interface{} {
+	var defaults interface{}
+	if err := yaml.Unmarshal(defaultConfigFile, &defaults); err != nil {
+		log.Fatal(err)
+	}
+	for _, part := range strings.Split(key, ".") {
+		defaultsMap, ok := defaults.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		defaults = defaultsMap[part]
+	}
+	return defaults
+}
+
+// NewConfig returns a copy of the global config with settings overridden (ex: {"unit.time": "d"}). Changing it does
+// not change the global config, so that estimations run with different configs at the same time.
+func NewConfig(settings map[string]interface{}) *viper.Viper {
+	config := viper.New()
+	for _, key := range viper.AllKeys() {
+		config.Set(key, viper.Get(key))
+	}
+	for key, value := range settings {
+		config.Set(key, value)
+	}
+	return config
+}
+
 // InitWithDefaultConfig initializes the configuration with the default config file
 func InitWithDefaultConfig() {
 	initViper("")
@@ -59,6 +104,7 @@ func basePath() string {
 var WorkDir string
 
 func initViper(configFilePath string) {
+	configInitialized = true
 	loadViperDefaults()
 
 	if configFilePath != "" {
@@ -2,10 +2,11 @@ package utils
 
 import (
 	"fmt"
-	"log"
 	"strings"
+	"sync"
 
 	"github.com/itchyny/gojq"
+	"github.com/pkg/errors"
 )
 
 type moduleLoader struct{}
@@ -32,20 +33,12 @@ func (*moduleLoader) LoadModule(name string) (*gojq.Query, error) {
 	return nil, fmt.Errorf("module not found: %q", name)
 }
 
-// GetJSON returns the result of a jq query on a json object
+// GetJSON returns the result of a jq query on a json object. gojq normalizes the numbers of the json object in place,
+// so it must not be queried by several goroutines at once (cf CopyJSON).
 func GetJSON(query string, json interface{}) ([]interface{}, error) {
-	queryImport := fmt.Sprintf(`import "carbonifer" as cbf; %s`, query)
-	queryParsed, err := gojq.Parse(queryImport)
+	code, err := compileJSONQuery(query)
 	if err != nil {
-		log.Fatal(err)
-		return nil, err
-	}
-
-	code, err := gojq.Compile(
-		queryParsed, *getGoJQWithModules(),
-	)
-	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "Cannot compile jq query %q", query)
 	}
 
 	iter := code.Run(json)
@@ -71,12 +64,72 @@ func GetJSON(query string, json interface{}) ([]interface{}, error) {
 	return results, nil
 }
 
+// CopyJSON returns a deep copy of the maps and arrays of a json object
+func CopyJSON(json interface{}) interface{} {
+	switch value := json.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			copied[k] = CopyJSON(v)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, v := range value {
+			copied[i] = CopyJSON(v)
+		}
+		return copied
+	default:
+		return value
+	}
+}
+
+// CompileJSONQuery checks that a jq query, using the carbonifer module, parses and compiles
+func CompileJSONQuery(query string) error {
+	_, err := compileJSONQuery(query)
+	return err
+}
+
+// compiledQueries caches compiled jq queries by query text, as mappings run the same queries on every resource
+var compiledQueries = map[string]*gojq.Code{}
+var compiledQueriesMutex sync.RWMutex
+
+// compileJSONQuery parses and compiles a jq query using the carbonifer module, or returns it from cache
+func compileJSONQuery(query string) (*gojq.Code, error) {
+	compiledQueriesMutex.RLock()
+	code, ok := compiledQueries[query]
+	compiledQueriesMutex.RUnlock()
+	if ok {
+		return code, nil
+	}
+
+	queryParsed, err := gojq.Parse(fmt.Sprintf(`import "carbonifer" as cbf; %s`, query))
+	if err != nil {
+		return nil, &queryParseError{err}
+	}
+	code, err = gojq.Compile(queryParsed, *getGoJQWithModules())
+	if err != nil {
+		return nil, err
+	}
+
+	compiledQueriesMutex.Lock()
+	compiledQueries[query] = code
+	compiledQueriesMutex.Unlock()
+	return code, nil
+}
+
+// queryParseError is the error of a jq query that cannot be parsed
+type queryParseError struct {
+	error
+}
+
 var goJqWithModules *gojq.CompilerOption
+var goJqWithModulesOnce sync.Once
 
 func getGoJQWithModules() *gojq.CompilerOption {
-	if goJqWithModules == nil {
+	goJqWithModulesOnce.Do(func() {
 		goJqWithModulesObj := gojq.WithModuleLoader(&moduleLoader{})
 		goJqWithModules = &goJqWithModulesObj
-	}
+	})
 	return goJqWithModules
 }
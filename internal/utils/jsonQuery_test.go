@@ -98,3 +98,15 @@ func jsonParse(jsonString string) map[string]interface{} {
 	}
 	return result
 }
+
+func TestCompileJSONQuery_Cache(t *testing.T) {
+	code, err := compileJSONQuery(`cbf::all_select("type"; "google_compute_disk") | .values.size`)
+	assert.NoError(t, err)
+	cached, err := compileJSONQuery(`cbf::all_select("type"; "google_compute_disk") | .values.size`)
+	assert.NoError(t, err)
+	assert.Same(t, code, cached)
+
+	_, err = compileJSONQuery(`.foo |`)
+	assert.IsType(t, &queryParseError{}, err)
+	assert.Error(t, CompileJSONQuery(`cbf::unknown_function`))
+}
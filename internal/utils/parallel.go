@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Parallelism returns the maximum number of resources processed at once, set by the 'parallelism' config key.
+// It defaults to the number of CPUs.
+func Parallelism() int {
+	parallelism := viper.GetInt("parallelism")
+	if parallelism <= 0 {
+		return runtime.NumCPU()
+	}
+	return parallelism
+}
+
+// ParallelMap calls fn on each item, with at most Parallelism() calls at once, and returns the results in the
+// order of the items. A panic in fn is raised again in the caller goroutine once all calls are done.
+func ParallelMap[T, R any](items []T, fn func(T) R) []R {
+	return ParallelMapWorkers(items, func() struct{} { return struct{}{} }, func(_ struct{}, item T) R {
+		return fn(item)
+	})
+}
+
+// ParallelMapWorkers is ParallelMap with a state per worker, created by newWorker before the workers start. A state
+// is only used by one goroutine.
+func ParallelMapWorkers[W, T, R any](items []T, newWorker func() W, fn func(W, T) R) []R {
+	results := make([]R, len(items))
+	workers := Parallelism()
+	if workers > len(items) {
+		workers = len(items)
+	}
+	if workers <= 1 {
+		worker := newWorker()
+		for i, item := range items {
+			results[i] = fn(worker, item)
+		}
+		return results
+	}
+
+	states := make([]W, workers)
+	for w := range states {
+		states[w] = newWorker()
+	}
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicked interface{}
+	for _, state := range states {
+		wg.Add(1)
+		go func(state W) {
+			defer wg.Done()
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					panicOnce.Do(func() { panicked = recovered })
+					// Drain the remaining items so that the other workers and the producer don't block
+					for range indexes {
+					}
+				}
+			}()
+			for i := range indexes {
+				results[i] = fn(state, items[i])
+			}
+		}(state)
+	}
+	for i := range items {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+	return results
+}
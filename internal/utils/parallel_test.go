@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParallelMap(t *testing.T) {
+	parallelism := viper.Get("parallelism")
+	defer viper.Set("parallelism", parallelism)
+	viper.Set("parallelism", 3)
+
+	items := []int{}
+	for i := 0; i < 50; i++ {
+		items = append(items, i)
+	}
+	var running, maxRunning int32
+	results := ParallelMap(items, func(item int) int {
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return item * 2
+	})
+
+	// Results in the order of items, with at most 3 calls at once
+	for i, result := range results {
+		assert.Equal(t, i*2, result)
+	}
+	assert.LessOrEqual(t, maxRunning, int32(3))
+	assert.Greater(t, maxRunning, int32(1))
+}
+
+func TestParallelMap_Panic(t *testing.T) {
+	parallelism := viper.Get("parallelism")
+	defer viper.Set("parallelism", parallelism)
+	viper.Set("parallelism", 4)
+
+	assert.PanicsWithValue(t, "item 7", func() {
+		ParallelMap([]int{1, 2, 3, 4, 5, 6, 7, 8, 9}, func(item int) int {
+			if item == 7 {
+				panic("item 7")
+			}
+			return item
+		})
+	})
+}
+
+func TestParallelism(t *testing.T) {
+	parallelism := viper.Get("parallelism")
+	defer viper.Set("parallelism", parallelism)
+
+	viper.Set("parallelism", 0)
+	assert.Greater(t, Parallelism(), 0)
+	viper.Set("parallelism", 5)
+	assert.Equal(t, 5, Parallelism())
+}
@@ -0,0 +1,29 @@
+package estimate
+
+import (
+	"github.com/carboniferio/carbonifer/internal/estimate/coefficients"
+	"github.com/carboniferio/carbonifer/internal/plan"
+	internalProviders "github.com/carboniferio/carbonifer/internal/providers"
+	internalResources "github.com/carboniferio/carbonifer/internal/resources"
+	"github.com/carboniferio/carbonifer/internal/terraform"
+)
+
+// Errors returned by the estimations, to be checked with errors.As
+
+// ResourceError is the error of a single resource of a plan, listed in PlanReport.Errors
+type ResourceError = internalResources.ResourceError
+
+// UnknownRegionError is returned when the region of a resource has no carbon intensity data
+type UnknownRegionError = coefficients.UnknownRegionError
+
+// UnknownMachineTypeError is returned when a machine or instance type is not in the data files
+type UnknownMachineTypeError = internalProviders.UnknownMachineTypeError
+
+// UnsupportedProviderError is returned when a resource is from a provider not supported by carbonifer
+type UnsupportedProviderError = internalProviders.UnsupportedProviderError
+
+// MappingError is returned when the mapping of a resource type cannot be applied
+type MappingError = plan.MappingError
+
+// TerraformError is returned when a terraform command fails
+type TerraformError = terraform.TerraformError
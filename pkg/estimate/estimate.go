@@ -2,11 +2,11 @@ package estimate
 
 import (
 	"github.com/carboniferio/carbonifer/internal/estimate"
-	internalResources "github.com/carboniferio/carbonifer/internal/resources"
 	"github.com/carboniferio/carbonifer/internal/utils"
 	"github.com/carboniferio/carbonifer/pkg/providers"
 	"github.com/carboniferio/carbonifer/pkg/resources"
 	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
 )
 
 // EstimationReport is the struct that contains the estimation of a resource
@@ -18,9 +18,14 @@ type EstimationReport struct {
 	Count           decimal.Decimal
 }
 
-// GetEstimation returns the estimation of a resource
+// GetEstimation returns the estimation of a resource with the global config
 func GetEstimation(resource resources.GenericResource) (EstimationReport, error) {
-	estimation, err := estimate.EstimateResource(toInternalComputeResource(resource))
+	utils.EnsureConfig()
+	return getEstimation(viper.GetViper(), resource)
+}
+
+func getEstimation(config *viper.Viper, resource resources.GenericResource) (EstimationReport, error) {
+	estimation, err := estimate.EstimateResource(config, resource.ToComputeResource(), nil, "")
 	if err != nil {
 		return EstimationReport{}, err
 	}
@@ -39,32 +44,16 @@ func GetEstimation(resource resources.GenericResource) (EstimationReport, error)
 
 // GetEstimationFromInstanceType returns the estimation of a resource from its instance type
 func GetEstimationFromInstanceType(instanceType string, zone string, provider providers.Provider) (EstimationReport, error) {
-	resource, err := resources.GetResource(instanceType, zone, provider)
-	if err != nil {
-		return EstimationReport{}, err
-	}
-	estimation, err := GetEstimation(resource)
-	if err != nil {
-		return EstimationReport{}, err
-	}
-	return estimation, nil
+	utils.EnsureConfig()
+	return GetEstimationFromInstanceTypeWithConfig(viper.GetViper(), instanceType, zone, provider)
 }
 
-func toInternalComputeResource(resource resources.GenericResource) internalResources.ComputeResource {
-	// TODO: Support multiple CPU types
-	// Check this PR for more info: https://github.com/carboniferio/carbonifer/pull/41
-	return internalResources.ComputeResource{
-		Identification: resource.GetIdentification(),
-		Specs: &internalResources.ComputeResourceSpecs{
-			GpuTypes:   resource.GPUTypes,
-			HddStorage: resource.Storage.HddStorage,
-			SsdStorage: resource.Storage.SsdStorage,
-			MemoryMb:   resource.MemoryMb,
-			VCPUs:      resource.VCPUs,
-		},
+// GetEstimationFromInstanceTypeWithConfig returns the estimation of a resource from its instance type, with config
+// instead of the global config (see utils.NewConfig)
+func GetEstimationFromInstanceTypeWithConfig(config *viper.Viper, instanceType string, zone string, provider providers.Provider) (EstimationReport, error) {
+	resource, err := resources.GetResourceFromData(config.GetString("data.path"), instanceType, zone, provider)
+	if err != nil {
+		return EstimationReport{}, err
 	}
-}
-
-func init() {
-	utils.InitWithDefaultConfig()
+	return getEstimation(config, resource)
 }
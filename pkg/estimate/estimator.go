@@ -0,0 +1,105 @@
+package estimate
+
+import (
+	"os"
+	"strings"
+
+	"github.com/carboniferio/carbonifer/internal/estimate"
+	"github.com/carboniferio/carbonifer/internal/estimate/estimation"
+	"github.com/carboniferio/carbonifer/internal/plan"
+	"github.com/carboniferio/carbonifer/internal/utils"
+	"github.com/carboniferio/carbonifer/pkg/providers"
+	"github.com/carboniferio/carbonifer/pkg/resources"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+)
+
+// PlanReport is the estimation of all resources of a terraform plan
+type PlanReport = estimation.EstimationReport
+
+// Config is the configuration of an Estimator. Zero values use the defaults of carbonifer.
+type Config struct {
+	UnitTime   string // h, d, m or y
+	UnitPower  string // W or kW
+	UnitCarbon string // g or kg
+	Providers  map[providers.Provider]ProviderConfig
+	DataPath   string   // directory of data files overriding the embedded ones
+	Mappings   []string // mapping files added to the embedded mappings
+}
+
+// ProviderConfig is the planned usage of the resources of a provider
+type ProviderConfig struct {
+	AverageCPUUse                float64
+	AverageGPUUse                float64
+	AverageAutoscalerSizePercent float64
+}
+
+// Estimator estimates plans, resources and instance types with its own config and mappings instead of the global
+// config of carbonifer, which it never changes. Estimators can be used concurrently, and each one by several goroutines.
+type Estimator struct {
+	config   *viper.Viper
+	mappings *plan.Mappings
+}
+
+// NewEstimator creates an estimator with its config
+func NewEstimator(config Config) (*Estimator, error) {
+	utils.EnsureConfig()
+	settings := map[string]interface{}{
+		"unit.time":   defaultString(config.UnitTime, "unit.time"),
+		"unit.power":  defaultString(config.UnitPower, "unit.power"),
+		"unit.carbon": defaultString(config.UnitCarbon, "unit.carbon"),
+		"data.path":   config.DataPath,
+		// Plans are read as is, terraform is never run
+		"offline":               true,
+		"carbon_intensity_file": "",
+	}
+	for _, provider := range []providers.Provider{providers.GCP, providers.AWS} {
+		providerConfig := config.Providers[provider]
+		prefix := "provider." + strings.ToLower(provider.String()) + "."
+		settings[prefix+"avg_cpu_use"] = defaultFloat(providerConfig.AverageCPUUse, prefix+"avg_cpu_use")
+		settings[prefix+"avg_gpu_use"] = defaultFloat(providerConfig.AverageGPUUse, prefix+"avg_gpu_use")
+		settings[prefix+"avg_autoscaler_size_percent"] = defaultFloat(providerConfig.AverageAutoscalerSizePercent, prefix+"avg_autoscaler_size_percent")
+	}
+
+	if config.DataPath != "" {
+		if info, err := os.Stat(config.DataPath); err != nil || !info.IsDir() {
+			return nil, errors.Errorf("Cannot read data directory %v", config.DataPath)
+		}
+	}
+	mappings, err := plan.LoadMappings(config.Mappings...)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load mappings")
+	}
+	return &Estimator{config: utils.NewConfig(settings), mappings: mappings}, nil
+}
+
+// EstimatePlan estimates the resources of a terraform plan (JSON)
+func (e *Estimator) EstimatePlan(tfPlan map[string]interface{}) (PlanReport, error) {
+	return estimate.EstimatePlan(e.config, e.mappings, &tfPlan, nil, "")
+}
+
+// EstimateResource estimates a resource
+func (e *Estimator) EstimateResource(resource resources.GenericResource) (EstimationReport, error) {
+	return getEstimation(e.config, resource)
+}
+
+// EstimateInstanceType estimates an instance type of a provider, in a zone
+func (e *Estimator) EstimateInstanceType(instanceType string, zone string, provider providers.Provider) (EstimationReport, error) {
+	return GetEstimationFromInstanceTypeWithConfig(e.config, instanceType, zone, provider)
+}
+
+func defaultString(value string, key string) string {
+	if value != "" {
+		return value
+	}
+	defaultValue, _ := utils.DefaultValue(key).(string)
+	return defaultValue
+}
+
+func defaultFloat(value float64, key string) float64 {
+	if value != 0 {
+		return value
+	}
+	defaultValue, _ := utils.DefaultValue(key).(float64)
+	return defaultValue
+}
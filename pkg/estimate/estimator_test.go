@@ -0,0 +1,123 @@
+package estimate
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/carboniferio/carbonifer/pkg/providers"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimator_EstimateInstanceType(t *testing.T) {
+	estimator, err := NewEstimator(Config{})
+	assert.NoError(t, err)
+
+	report, err := estimator.EstimateInstanceType("e2-standard-2", "europe-west4", providers.GCP)
+	assert.NoError(t, err)
+	assert.Equal(t, "e2-standard-2", report.Resource.Name)
+	assert.Equal(t, decimal.NewFromFloatWithExponent(8.9166, -10).String(), report.Power.String())
+	assert.Equal(t, decimal.NewFromFloatWithExponent(2.5233978, -10).String(), report.CarbonEmissions.String())
+
+	_, err = estimator.EstimateInstanceType("unknown", "europe-west4", providers.GCP)
+	var machineTypeErr *UnknownMachineTypeError
+	assert.True(t, errors.As(err, &machineTypeErr))
+	assert.Equal(t, "unknown", machineTypeErr.MachineType)
+}
+
+func TestEstimator_Concurrent(t *testing.T) {
+	hourly, err := NewEstimator(Config{})
+	assert.NoError(t, err)
+	daily, err := NewEstimator(Config{UnitTime: "d"})
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	reports := make([]EstimationReport, 20)
+	for i := range reports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			estimator := hourly
+			if i%2 == 1 {
+				estimator = daily
+			}
+			report, err := estimator.EstimateInstanceType("e2-standard-2", "europe-west4", providers.GCP)
+			assert.NoError(t, err)
+			reports[i] = report
+		}(i)
+	}
+	wg.Wait()
+
+	for i, report := range reports {
+		expected := reports[i%2]
+		assert.True(t, expected.CarbonEmissions.Equal(report.CarbonEmissions))
+	}
+	assert.True(t, reports[0].CarbonEmissions.Mul(decimal.NewFromInt(24)).Round(6).Equal(reports[1].CarbonEmissions.Round(6)))
+}
+
+func TestEstimator_EstimatePlan(t *testing.T) {
+	content, err := os.ReadFile("../../test/terraform/planJson/plan.json")
+	assert.NoError(t, err)
+	var tfPlan map[string]interface{}
+	assert.NoError(t, json.Unmarshal(content, &tfPlan))
+
+	estimator, err := NewEstimator(Config{UnitCarbon: "kg"})
+	assert.NoError(t, err)
+	report, err := estimator.EstimatePlan(tfPlan)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, report.Resources)
+	assert.Equal(t, "kg", report.Info.UnitCarbonEmissionsTime[:2])
+}
+
+// TestEstimator_ConcurrentUnits runs estimators with different units at the same time, run it with -race
+func TestEstimator_ConcurrentUnits(t *testing.T) {
+	content, err := os.ReadFile("../../test/terraform/planJson/plan.json")
+	assert.NoError(t, err)
+	grams, err := NewEstimator(Config{UnitTime: "h", UnitCarbon: "g"})
+	assert.NoError(t, err)
+	kilograms, err := NewEstimator(Config{UnitTime: "d", UnitCarbon: "kg"})
+	assert.NoError(t, err)
+	unitTime, unitCarbon := viper.GetString("unit.time"), viper.GetString("unit.carbon")
+
+	var wg sync.WaitGroup
+	reports := make([]PlanReport, 8)
+	for i := range reports {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			estimator := grams
+			if i%2 == 1 {
+				estimator = kilograms
+			}
+			var tfPlan map[string]interface{}
+			assert.NoError(t, json.Unmarshal(content, &tfPlan))
+			report, err := estimator.EstimatePlan(tfPlan)
+			assert.NoError(t, err)
+			reports[i] = report
+		}(i)
+	}
+	wg.Wait()
+
+	for i, report := range reports {
+		if i%2 == 0 {
+			assert.Equal(t, "gCO2eq/h", report.Info.UnitCarbonEmissionsTime)
+		} else {
+			assert.Equal(t, "kgCO2eq/d", report.Info.UnitCarbonEmissionsTime)
+		}
+		assert.True(t, reports[i%2].Total.CarbonEmissions.Equal(report.Total.CarbonEmissions))
+	}
+	assert.True(t, reports[0].Total.CarbonEmissions.Mul(decimal.NewFromFloat(0.024)).Round(6).Equal(reports[1].Total.CarbonEmissions.Round(6)))
+	// The global config is left as is
+	assert.Equal(t, unitTime, viper.GetString("unit.time"))
+	assert.Equal(t, unitCarbon, viper.GetString("unit.carbon"))
+	assert.False(t, viper.GetBool("offline"))
+}
+
+func TestNewEstimator_BadDataPath(t *testing.T) {
+	_, err := NewEstimator(Config{DataPath: "/does/not/exist"})
+	assert.Error(t, err)
+}
@@ -1,14 +1,14 @@
 package resources
 
 import (
-	"fmt"
-
 	internalProvider "github.com/carboniferio/carbonifer/internal/providers"
+	"github.com/carboniferio/carbonifer/internal/providers/aws"
 	"github.com/carboniferio/carbonifer/internal/providers/gcp"
 	"github.com/carboniferio/carbonifer/internal/resources"
 	"github.com/carboniferio/carbonifer/internal/utils"
 	"github.com/carboniferio/carbonifer/pkg/providers"
 	"github.com/shopspring/decimal"
+	"github.com/spf13/viper"
 )
 
 // GenericResource is a struct that contains the information of a generic resource
@@ -23,13 +23,14 @@ type GenericResource struct {
 	MemoryMb          int32
 	Storage           Storage
 	ReplicationFactor int32
+	Count             int64 // number of instances, 1 if not set
 }
 
-// IsSupported returns true if the resource is supported by carbonifer. At the moment, only GCP is supported.
+// IsSupported returns true if the resource is supported by carbonifer. At the moment, GCP and AWS are supported.
 func (g GenericResource) IsSupported() bool {
 	// Use a switch to make it easier to add new providers
 	switch g.Provider {
-	case providers.GCP:
+	case providers.GCP, providers.AWS:
 		return true
 	default:
 		return false
@@ -38,12 +39,16 @@ func (g GenericResource) IsSupported() bool {
 
 // GetIdentification returns the identification of the resource
 func (g GenericResource) GetIdentification() *resources.ResourceIdentification {
+	count := g.Count
+	if count == 0 {
+		count = 1
+	}
 	return &resources.ResourceIdentification{
 		Name:              g.Name,
 		ResourceType:      "compute",
 		Provider:          internalProvider.Provider(g.Provider),
 		Region:            g.Region,
-		Count:             1,
+		Count:             count,
 		ReplicationFactor: 1,
 		Address:           g.Address,
 	}
@@ -54,19 +59,53 @@ func (g GenericResource) GetAddress() string {
 	return g.Address
 }
 
+// ToComputeResource converts the resource to the compute resource estimated by carbonifer
+func (g GenericResource) ToComputeResource() resources.ComputeResource {
+	// TODO: Support multiple CPU types
+	// Check this PR for more info: https://github.com/carboniferio/carbonifer/pull/41
+	return resources.ComputeResource{
+		Identification: g.GetIdentification(),
+		Specs: &resources.ComputeResourceSpecs{
+			GpuTypes:    g.GPUTypes,
+			HddStorage:  g.Storage.HddStorage,
+			SsdStorage:  g.Storage.SsdStorage,
+			MemoryMb:    g.MemoryMb,
+			VCPUs:       g.VCPUs,
+			MachineType: g.Name,
+		},
+	}
+}
+
 // Storage is the struct that contains the storage of a resource
 type Storage struct {
 	HddStorage decimal.Decimal
 	SsdStorage decimal.Decimal
 }
 
-// GetResource returns a GenericResource from an instance type
+// GetResource returns a GenericResource from an instance type, read from the data of the global config
 func GetResource(instanceType string, zone string, provider providers.Provider) (GenericResource, error) {
+	utils.EnsureConfig()
+	return GetResourceFromData(viper.GetString("data.path"), instanceType, zone, provider)
+}
+
+// GetResourceFromData returns a GenericResource from an instance type, read from the data files of dataPath (embedded
+// data if empty)
+func GetResourceFromData(dataPath string, instanceType string, zone string, provider providers.Provider) (GenericResource, error) {
 	switch provider {
 	case providers.GCP:
-		return fromGCPMachineTypeToResource(zone, gcp.GetGCPMachineType(instanceType, zone)), nil
+		machineType, err := gcp.GetGCPMachineType(dataPath, instanceType, zone)
+		if err != nil {
+			return GenericResource{}, err
+		}
+		return fromGCPMachineTypeToResource(zone, machineType), nil
+	case providers.AWS:
+		awsInstanceType, err := aws.GetAWSInstanceType(dataPath, instanceType)
+		if err != nil {
+			return GenericResource{}, err
+		}
+		return fromAWSInstanceTypeToResource(zone, awsInstanceType), nil
 	default:
-		return GenericResource{}, fmt.Errorf("provider %s not supported", provider.String())
+		return GenericResource{}, &internalProvider.UnsupportedProviderError{Provider: provider.String()}
 	}
 }
 
@@ -84,6 +123,16 @@ func fromGCPMachineTypeToResource(region string, machineType gcp.MachineType) Ge
 	}
 }
 
-func init() {
-	utils.InitWithDefaultConfig()
+// fromAWSInstanceTypeToResource converts an AWS instance type. Like plan, GPUs and local storage
+// of the instance type are not counted (local storage is counted only if declared).
+func fromAWSInstanceTypeToResource(region string, instanceType aws.InstanceType) GenericResource {
+	return GenericResource{
+		Name:              instanceType.InstanceType,
+		Region:            region,
+		Provider:          providers.AWS,
+		MemoryMb:          instanceType.MemoryMb,
+		VCPUs:             instanceType.VCPU,
+		Storage:           Storage{},
+		ReplicationFactor: 0,
+	}
 }
@@ -35,6 +35,13 @@ func TestGenericResource_IsSupported(t *testing.T) {
 			fields: fields{
 				Provider: providers.AWS,
 			},
+			want: true,
+		},
+		{
+			name: "Azure",
+			fields: fields{
+				Provider: providers.AZURE,
+			},
 			want: false,
 		},
 	}
@@ -94,6 +101,44 @@ func TestGetResource(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "m5d.xlarge",
+			args: args{
+				instanceType: "m5d.xlarge",
+				zone:         "eu-west-3",
+				provider:     providers.AWS,
+			},
+			want: GenericResource{
+				Name:              "m5d.xlarge",
+				Region:            "eu-west-3",
+				Provider:          providers.AWS,
+				VCPUs:             4,
+				MemoryMb:          16384,
+				Storage:           Storage{},
+				ReplicationFactor: 0,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown",
+			args: args{
+				instanceType: "m5d.unknown",
+				zone:         "eu-west-3",
+				provider:     providers.AWS,
+			},
+			want:    GenericResource{},
+			wantErr: true,
+		},
+		{
+			name: "azure",
+			args: args{
+				instanceType: "Standard_D2s_v3",
+				zone:         "westeurope",
+				provider:     providers.AZURE,
+			},
+			want:    GenericResource{},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
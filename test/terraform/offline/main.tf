@@ -0,0 +1,83 @@
+terraform {
+  required_providers {
+    google = {
+      source = "hashicorp/google"
+    }
+  }
+}
+
+provider "google" {
+  region = var.region
+  zone   = "${var.region}-a"
+}
+
+locals {
+  prefix    = "cbf-${terraform.workspace}"
+  disk_size = var.instance_count * 50
+}
+
+data "google_compute_image" "debian" {
+  family  = "debian-11"
+  project = "debian-cloud"
+}
+
+resource "google_compute_instance" "web" {
+  count        = var.instance_count
+  name         = "${local.prefix}-web-${count.index}"
+  machine_type = "n1-standard-2"
+
+  boot_disk {
+    initialize_params {
+      image = data.google_compute_image.debian.self_link
+      size  = local.disk_size
+    }
+  }
+
+  attached_disk {
+    source = module.storage.id
+  }
+
+  network_interface {
+    network = "default"
+  }
+}
+
+resource "google_compute_instance" "app" {
+  for_each     = var.machine_types
+  name         = "${local.prefix}-${each.key}"
+  machine_type = each.value
+  zone         = "europe-west9-a"
+
+  boot_disk {
+    initialize_params {
+      image = "debian-cloud/debian-11"
+      size  = 20
+    }
+  }
+
+  dynamic "scratch_disk" {
+    for_each = range(2)
+    content {
+      interface = "NVME"
+    }
+  }
+
+  network_interface {
+    network = "default"
+  }
+}
+
+resource "google_compute_disk" "backup" {
+  name = "${local.prefix}-backup"
+  type = "pd-standard"
+  size = module.storage.size * 2
+}
+
+module "storage" {
+  source = "./storage"
+  name   = local.prefix
+}
+
+module "remote" {
+  source = "terraform-google-modules/vm/google"
+}
@@ -0,0 +1,22 @@
+variable "name" {
+  type = string
+}
+
+variable "size" {
+  type    = number
+  default = 100
+}
+
+resource "google_compute_disk" "data" {
+  name = "${var.name}-data"
+  type = "pd-ssd"
+  size = var.size
+}
+
+output "id" {
+  value = google_compute_disk.data.id
+}
+
+output "size" {
+  value = var.size
+}
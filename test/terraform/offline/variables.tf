@@ -0,0 +1,16 @@
+variable "region" {
+  type    = string
+  default = "us-central1"
+}
+
+variable "instance_count" {
+  type    = number
+  default = 1
+}
+
+variable "machine_types" {
+  type = map(string)
+  default = {
+    front = "e2-standard-2"
+  }
+}